@@ -13,6 +13,15 @@ func ConfigureServiceProxies(router *gin.RouterGroup) {
 		addPrefix   string
 	}{
 		{"/orders", "http://order-service:8001", "/api/orders", "/orders"},
+		{"/cleaners", "http://order-service:8001", "/api/cleaners", "/cleaners"},
+		{"/cleaner", "http://order-service:8001", "/api/cleaner", "/cleaner"},
+		{"/promo-codes", "http://order-service:8001", "/api/promo-codes", "/promo-codes"},
+		{"/addresses", "http://order-service:8001", "/api/addresses", "/addresses"},
+		{"/zones", "http://order-service:8001", "/api/zones", "/zones"},
+		{"/reviews", "http://order-service:8001", "/api/reviews", "/reviews"},
+		{"/analytics", "http://order-service:8001", "/api/analytics", "/analytics"},
+		{"/earnings", "http://order-service:8001", "/api/earnings", "/earnings"},
+		{"/tips", "http://order-service:8001", "/api/tips", "/tips"},
 		{"/notifications", "http://notification-service:8002", "/api/notifications", "/notifications"},
 		{"/support", "http://support-service:8008", "/api/support", "/support"},
 		{"/subscriptions", "http://subscription-service:8004", "/api/subscriptions", "/subscriptions"},
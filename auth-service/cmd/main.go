@@ -82,6 +82,14 @@ func main() {
 		auth.GET("/managers", authHandler.GetManagers)
 		auth.POST("/logout", authHandler.Logout)
 
+		// Межсервисные вызовы, подписанные общим секретом INTERNAL_API_SECRET.
+		internal := auth.Group("/internal")
+		internal.Use(utils.RequireInternalSignature(cfg.InternalAPISecret))
+		{
+			internal.GET("/cleaners", authHandler.GetCleaners)
+			internal.PUT("/cleaners/:id/rating", authHandler.SetCleanerRating)
+		}
+
 		protected := auth.Group("/")
 		protected.Use(utils.AuthMiddleware(jwtUtil, utils.WrapRedisClient(rdb)))
 		{
@@ -91,7 +99,6 @@ func main() {
 			protected.PUT("/set-initial-password", authHandler.SetInitialPassword)
 
 			protected.GET("/total-users", authHandler.GetTotalUsers)
-			protected.POST("/add-ratings", authHandler.AddBulkRatings)
 			protected.GET("/rating", authHandler.GetRating)
 		}
 	}
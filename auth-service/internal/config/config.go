@@ -15,6 +15,9 @@ type Config struct {
 	SMTPUser         string
 	SMTPPass         string
 	NotifiServiceURL string
+
+	// Общий с другими сервисами секрет подписи межсервисных вызовов.
+	InternalAPISecret string
 }
 
 
@@ -33,5 +36,7 @@ func LoadConfig() (*Config, error) {
 		SMTPUser:         os.Getenv("SMTP_USER"),
 		SMTPPass:         os.Getenv("SMTP_PASS"),
 		NotifiServiceURL: os.Getenv("NOTIFI_SERVICE_URL"),
+
+		InternalAPISecret: os.Getenv("INTERNAL_API_SECRET"),
 	}, nil
 }
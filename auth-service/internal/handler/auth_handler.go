@@ -29,7 +29,7 @@ type AuthService interface {
 	GetTotalUsers(ctx context.Context) (int64, error)
 	GetRating(userID primitive.ObjectID) (float64, error)
 	ResendTemporaryPassword(email string) error
-	AddBulkRatings(ctx context.Context, customerID string, cleanerIDs []string, rating int, comment string) error
+	SetCleanerRating(ctx context.Context, cleanerID primitive.ObjectID, average float64, count int, asOf time.Time) (bool, error)
 }
 
 func NewAuthHandler(authService AuthService) *AuthHandler {
@@ -259,6 +259,30 @@ func (h *AuthHandler) GetManagers(c *gin.Context) {
 	c.JSON(200, managers)
 }
 
+// GetCleaners отдаёт список клинеров с рейтингом — внутренний вызов order-service для автоназначения.
+// GET /auth/internal/cleaners
+func (h *AuthHandler) GetCleaners(c *gin.Context) {
+	cleaners, err := h.authService.GetByRole("cleaner")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cleaners"})
+		return
+	}
+	out := make([]gin.H, 0, len(cleaners))
+	for _, u := range cleaners {
+		if u.Banned {
+			continue
+		}
+		out = append(out, gin.H{
+			"id":             u.ID.Hex(),
+			"first_name":     u.FirstName,
+			"last_name":      u.LastName,
+			"average_rating": u.AverageRating,
+			"rating_count":   u.RatingCount,
+		})
+	}
+	c.JSON(http.StatusOK, out)
+}
+
 func (h *AuthHandler) GetTotalUsers(c *gin.Context) {
 	count, err := h.authService.GetTotalUsers(c.Request.Context())
 	if err != nil {
@@ -269,31 +293,30 @@ func (h *AuthHandler) GetTotalUsers(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"totalUsers": count})
 }
 
-func (h *AuthHandler) AddBulkRatings(c *gin.Context) {
+// SetCleanerRating — внутренний вызов order-service: рейтинг клинера, пересчитанный по отзывам.
+// PUT /auth/internal/cleaners/:id/rating — body: { "average_rating": 4.67, "rating_count": 12, "as_of": RFC3339 }
+func (h *AuthHandler) SetCleanerRating(c *gin.Context) {
+	cleanerID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cleaner ID"})
+		return
+	}
 	var req struct {
-		CleanerIDs []string `json:"cleaner_ids" binding:"required,min=1,dive,required"`
-		Rating     int      `json:"rating" binding:"required,min=1,max=5"`
-		Comment    string   `json:"comment"`
+		AverageRating float64   `json:"average_rating" binding:"min=0,max=5"`
+		RatingCount   int       `json:"rating_count" binding:"min=0"`
+		AsOf          time.Time `json:"as_of" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	userIDIface, ok := c.Get("user_id")
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user_id not found"})
-		return
-	}
-	clientID := userIDIface.(string)
-	ctx := c.Request.Context()
-
-	if err := h.authService.AddBulkRatings(ctx, clientID, req.CleanerIDs, req.Rating, req.Comment); err != nil {
+	applied, err := h.authService.SetCleanerRating(c.Request.Context(), cleanerID, req.AverageRating, req.RatingCount, req.AsOf)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Ratings added to all cleaners"})
+	c.JSON(http.StatusOK, gin.H{"applied": applied})
 }
 
 func (h *AuthHandler) GetRating(c *gin.Context) {
@@ -20,8 +20,8 @@ type User struct {
 	Gender        string             `bson:"gender" validate:"omitempty,oneof=male female other"`
 	ResetRequired bool               `bson:"reset_required"`
 	RatingCount   int                `bson:"rating_count,omitempty" json:"rating_count,omitempty"`
-	RatingSum     int                `bson:"rating_sum,omitempty" json:"rating_sum,omitempty"`
 	AverageRating float64            `bson:"average_rating,omitempty" json:"average_rating,omitempty"`
+	RatingAsOf    *time.Time         `bson:"rating_updated_at,omitempty" json:"rating_updated_at,omitempty"` // когда order-service пересчитал рейтинг по отзывам
 }
 
 func (u *User) HashPassword() error {
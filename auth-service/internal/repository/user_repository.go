@@ -144,44 +144,29 @@ func (r *UserRepository) GetRating(userID primitive.ObjectID) (float64, error) {
 	return user.AverageRating, err
 }
 
-func (r *UserRepository) AddRating(ctx context.Context, cleanerID string, rating int) error {
-	oid, err := primitive.ObjectIDFromHex(cleanerID)
-	if err != nil {
-		return fmt.Errorf("invalid cleanerID %q: %w", cleanerID, err)
+// SetRating записывает рейтинг клинера, пересчитанный order-service по отзывам.
+// Обновление со временем asOf не старше уже сохранённого игнорируется (возвращает false),
+// поэтому запоздавшая доставка не перетрёт более свежий рейтинг.
+func (r *UserRepository) SetRating(ctx context.Context, cleanerID primitive.ObjectID, average float64, count int, asOf time.Time) (bool, error) {
+	filter := bson.M{
+		"_id":  cleanerID,
+		"role": "cleaner",
+		"$or": []bson.M{
+			{"rating_updated_at": bson.M{"$exists": false}},
+			{"rating_updated_at": bson.M{"$lt": asOf}},
+		},
 	}
-
-	// 1) Инкрементируем count и sum
 	update := bson.M{
-		"$inc": bson.M{
-			"rating_count": 1,
-			"rating_sum":   rating,
+		"$set": bson.M{
+			"average_rating":    average,
+			"rating_count":      count,
+			"rating_updated_at": asOf,
 		},
+		"$unset": bson.M{"rating_sum": ""},
 	}
-	// Если вы хотите сразу пересчитать average в одном запросе и ваша MongoDB ≥4.2, можно использовать aggregation pipeline update:
-	// update = mongo.Pipeline{
-	//   {{ "$set": bson.M{
-	//       "rating_count": bson.M{"$add": []interface{}{"$rating_count", 1}},
-	//       "rating_sum":   bson.M{"$add": []interface{}{"$rating_sum", rating}},
-	//   }}},
-	//   {{ "$set": bson.M{"average_rating": bson.M{"$divide": []interface{}{"$rating_sum", "$rating_count"}}}}},
-	// }
-
-	_, err = r.collection.UpdateByID(ctx, oid, update)
+	res, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return fmt.Errorf("inc sum/count: %w", err)
+		return false, fmt.Errorf("set rating: %w", err)
 	}
-
-	// 2) Получаем свежие значения для подсчёта среднего
-	var u models.User
-	if err := r.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&u); err != nil {
-		return fmt.Errorf("fetch user for avg: %w", err)
-	}
-	avg := float64(u.RatingSum) / float64(u.RatingCount)
-
-	// 3) Обновляем поле average_rating
-	if _, err := r.collection.UpdateByID(ctx, oid, bson.M{"$set": bson.M{"average_rating": avg}}); err != nil {
-		return fmt.Errorf("set average_rating: %w", err)
-	}
-
-	return nil
+	return res.MatchedCount == 1, nil
 }
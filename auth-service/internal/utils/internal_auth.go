@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// InternalTimestampHeader — время подписи межсервисного вызова, unix-секунды.
+	InternalTimestampHeader = "X-Internal-Timestamp"
+	// InternalSignatureHeader — HMAC-SHA256 метода, пути, времени и тела вызова в hex.
+	InternalSignatureHeader = "X-Internal-Signature"
+
+	// internalSignatureMaxSkew — насколько подпись может разойтись с часами сервиса.
+	internalSignatureMaxSkew = 5 * time.Minute
+)
+
+// RequireInternalSignature пропускает только межсервисные вызовы, подписанные общим
+// секретом INTERNAL_API_SECRET. Без настроенного секрета вызовы не принимаются вовсе.
+func RequireInternalSignature(secret string) gin.HandlerFunc {
+	if secret == "" {
+		log.Printf("[INTERNAL] INTERNAL_API_SECRET is not set: internal calls will be rejected")
+	}
+	return func(c *gin.Context) {
+		if secret == "" {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "internal calls are not configured"})
+			return
+		}
+		ts := c.GetHeader(InternalTimestampHeader)
+		unix, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil || time.Since(time.Unix(unix, 0)).Abs() > internalSignatureMaxSkew {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+			return
+		}
+		got, err := hex.DecodeString(c.GetHeader(InternalSignatureHeader))
+		if err != nil || !hmac.Equal(got, signInternal(secret, c.Request.Method, c.Request.URL.Path, ts, body)) {
+			log.Printf("[INTERNAL] Rejected internal call with invalid signature from %s", c.ClientIP())
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+func signInternal(secret, method, path, ts string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + path + "\n" + ts + "\n"))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
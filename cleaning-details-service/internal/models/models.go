@@ -8,12 +8,22 @@ import (
 )
 
 type CleaningService struct {
-	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	Name      string             `json:"name" bson:"name" validate:"required"`
-	Price     float64            `json:"price" bson:"price" validate:"required,gt=0"`
-	IsActive  bool               `json:"isActive" bson:"isActive"`
-	CreatedAt primitive.DateTime `json:"createdAt,omitempty" bson:"createdAt,omitempty"`
-	UpdatedAt primitive.DateTime `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
+	ID               primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name             string             `json:"name" bson:"name" validate:"required"`
+	Price            float64            `json:"price" bson:"price" validate:"required,gt=0"`
+	PriceUnit        string             `json:"priceUnit,omitempty" bson:"priceUnit,omitempty" validate:"omitempty,oneof=fixed per_m2 per_room per_bathroom"` // за что цена ("" = fixed)
+	IsActive         bool               `json:"isActive" bson:"isActive"`
+	DurationMinutes  int                `json:"durationMinutes" bson:"durationMinutes" validate:"gte=0"`   // средняя длительность услуги
+	CleanersRequired int                `json:"cleanersRequired" bson:"cleanersRequired" validate:"gte=0"` // сколько клинеров нужно (0 = 1)
+	Checklist        []ChecklistTask    `json:"checklist,omitempty" bson:"checklist,omitempty" validate:"dive"`
+	CreatedAt        primitive.DateTime `json:"createdAt,omitempty" bson:"createdAt,omitempty"`
+	UpdatedAt        primitive.DateTime `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
+}
+
+// ChecklistTask — пункт чек-листа услуги, копируется в заказ при его создании.
+type ChecklistTask struct {
+	Title    string `json:"title" bson:"title" validate:"required"`
+	Required bool   `json:"required" bson:"required"`
 }
 
 func (cs CleaningService) Validate() error {
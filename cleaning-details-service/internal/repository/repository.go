@@ -113,10 +113,14 @@ func (r *CleaningServiceRepository) UpdateService(ctx context.Context, service *
 
 	filter := bson.M{"_id": service.ID}
 	update := bson.M{"$set": bson.M{
-		"name":      service.Name,
-		"price":     service.Price,
-		"isActive":  service.IsActive,
-		"updatedAt": service.UpdatedAt,
+		"name":             service.Name,
+		"price":            service.Price,
+		"priceUnit":        service.PriceUnit,
+		"isActive":         service.IsActive,
+		"durationMinutes":  service.DurationMinutes,
+		"cleanersRequired": service.CleanersRequired,
+		"checklist":        service.Checklist,
+		"updatedAt":        service.UpdatedAt,
 	}}
 
 	result, err := collection.UpdateOne(ctx, filter, update)
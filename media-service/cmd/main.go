@@ -59,6 +59,8 @@ func main() {
 	{
 		media.POST("/report/:orderId", handler.UploadReport)
 		media.GET("/reports/:orderId", handler.GetReports)
+		media.POST("/dispute/:orderId", handler.UploadDisputeEvidence)
+		media.GET("/disputes/:orderId", handler.GetDisputeEvidence)
 		media.POST("/avatar", handler.UploadAvatar)
 		media.GET("/avatars", handler.GetAvatars)
 		media.GET("/presigned/:id", handler.GetPresignedURLByID)
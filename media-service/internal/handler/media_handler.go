@@ -20,10 +20,13 @@ type MediaService interface {
 	GetAvatars(ctx context.Context, userID string) ([]models.Media, error)
 	GeneratePresignedURL(ctx context.Context, objectName string) (string, error)
 	GetMediaByID(ctx context.Context, id string) (*models.Media, error)
+	GetDisputeEvidence(ctx context.Context, orderID string) ([]models.Media, error)
 }
 
 type OrderServiceClient interface {
 	IsCleaner(ctx context.Context, orderID, authHeader string) (bool, error)
+	IsOrderClient(ctx context.Context, orderID, userID, authHeader string) (bool, error)
+	IsOrderParty(ctx context.Context, orderID, userID, authHeader string) (bool, error)
 }
 
 func NewMediaHandler(svc MediaService, orderClient OrderServiceClient) *MediaHandler {
@@ -93,6 +96,66 @@ func (h *MediaHandler) UploadReport(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"url": url})
 }
 
+// UploadDisputeEvidence — клиент прикладывает фото к спору по своему заказу.
+// Полученный url передаётся в POST /orders/:id/dispute.
+func (h *MediaHandler) UploadDisputeEvidence(c *gin.Context) {
+	orderID := c.Param("orderId")
+	userID := c.GetString("userId")
+
+	ok, err := h.orderClient.IsOrderClient(c.Request.Context(), orderID, userID, c.GetHeader("Authorization"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "validation error"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the order's client can upload dispute evidence"})
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	defer file.Close()
+
+	url, err := h.svc.Upload(
+		c.Request.Context(), file, header.Size,
+		header.Header.Get("Content-Type"),
+		header.Filename,
+		models.DisputeMedia,
+		orderID, userID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"url": url})
+}
+
+// GetDisputeEvidence — фото по спору видят участники заказа (клиент и клинеры) и менеджеры.
+func (h *MediaHandler) GetDisputeEvidence(c *gin.Context) {
+	orderID := c.Param("orderId")
+	if role := c.GetString("role"); role != "manager" && role != "admin" {
+		ok, err := h.orderClient.IsOrderParty(c.Request.Context(), orderID, c.GetString("userId"), c.GetHeader("Authorization"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "validation error"})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only the order's participants and managers can view dispute evidence"})
+			return
+		}
+	}
+
+	medias, err := h.svc.GetDisputeEvidence(c.Request.Context(), orderID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, medias)
+}
+
 func (h *MediaHandler) GetReports(c *gin.Context) {
 	orderID := c.Param("orderId")
 	medias, err := h.svc.GetReports(c.Request.Context(), orderID)
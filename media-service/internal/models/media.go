@@ -8,8 +8,9 @@ import (
 type MediaType string
 
 const (
-	ReportMedia MediaType = "report"
-	AvatarMedia MediaType = "avatar"
+	ReportMedia  MediaType = "report"
+	AvatarMedia  MediaType = "avatar"
+	DisputeMedia MediaType = "dispute" // фото-доказательства клиента по спору
 )
 
 type Media struct {
@@ -18,7 +19,7 @@ type Media struct {
 	ObjectKey string             `bson:"object_key"`
 	URL       string             `bson:"url"`
 	Type      MediaType          `bson:"type"`
-	OrderID   string             `bson:"order_id,omitempty"` // для фотоотчёта
+	OrderID   string             `bson:"order_id,omitempty"` // для фотоотчёта и спора
 	UserID    string             `bson:"user_id,omitempty"`  // для аватарки
 	CreatedAt time.Time          `bson:"created_at"`
 }
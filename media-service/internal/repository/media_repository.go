@@ -63,3 +63,15 @@ func (r *MediaRepository) FindByUserID(ctx context.Context, userID string) ([]mo
 	}
 	return res, nil
 }
+
+func (r *MediaRepository) FindDisputeEvidence(ctx context.Context, orderID string) ([]models.Media, error) {
+	cursor, err := r.col.Find(ctx, bson.M{"order_id": orderID, "type": models.DisputeMedia})
+	if err != nil {
+		return nil, err
+	}
+	res := make([]models.Media, 0)
+	if err := cursor.All(ctx, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
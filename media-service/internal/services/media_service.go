@@ -24,6 +24,7 @@ type MediaRepository interface {
 	FindByOrderID(ctx context.Context, orderID string) ([]models.Media, error)
 	FindByUserID(ctx context.Context, userID string) ([]models.Media, error)
 	FindByID(ctx context.Context, id string) (*models.Media, error)
+	FindDisputeEvidence(ctx context.Context, orderID string) ([]models.Media, error)
 }
 
 func NewMediaService(r MediaRepository, m *minio.Client, bucket string, publicURL string) *MediaService {
@@ -63,6 +64,10 @@ func (s *MediaService) GetReports(ctx context.Context, orderID string) ([]models
 	return s.repo.FindByOrderID(ctx, orderID)
 }
 
+func (s *MediaService) GetDisputeEvidence(ctx context.Context, orderID string) ([]models.Media, error) {
+	return s.repo.FindDisputeEvidence(ctx, orderID)
+}
+
 func (s *MediaService) GetAvatars(ctx context.Context, userID string) ([]models.Media, error) {
 	return s.repo.FindByUserID(ctx, userID)
 }
@@ -10,6 +10,7 @@ import (
 // OrderResponse содержит только то, что нам нужно из Order Service
 type OrderResponse struct {
 	ID         string   `json:"id"`
+	ClientID   string   `json:"client_id"`
 	CleanerIDs []string `json:"cleaner_id"` // именно так приходит массив
 }
 
@@ -49,6 +50,65 @@ func (oc *OrderServiceClient) IsCleaner(ctx context.Context, orderID, authHeader
 	// любая другая — отказ
 	return false, nil
 }
+
+// IsOrderClient проверяет, что userID — клиент, оформивший заказ.
+func (oc *OrderServiceClient) IsOrderClient(ctx context.Context, orderID, userID, authHeader string) (bool, error) {
+	url := fmt.Sprintf("%s/orders/%s", oc.BaseURL, orderID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Authorization", authHeader)
+
+	resp, err := oc.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("call order service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, nil
+	}
+	var order OrderResponse
+	if err := json.NewDecoder(resp.Body).Decode(&order); err != nil {
+		return false, fmt.Errorf("decode order: %w", err)
+	}
+	return order.ClientID == userID, nil
+}
+
+// IsOrderParty проверяет, что userID — клиент заказа или назначенный на него клинер.
+func (oc *OrderServiceClient) IsOrderParty(ctx context.Context, orderID, userID, authHeader string) (bool, error) {
+	url := fmt.Sprintf("%s/orders/%s", oc.BaseURL, orderID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Authorization", authHeader)
+
+	resp, err := oc.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("call order service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, nil
+	}
+	var order OrderResponse
+	if err := json.NewDecoder(resp.Body).Decode(&order); err != nil {
+		return false, fmt.Errorf("decode order: %w", err)
+	}
+	if order.ClientID == userID {
+		return true, nil
+	}
+	for _, id := range order.CleanerIDs {
+		if id == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (c *OrderServiceClient) HasReports(orderID, authHeader string) (bool, error) {
 	url := fmt.Sprintf("%s/media/reports/%s", c.BaseURL, orderID)
 	req, err := http.NewRequest("GET", url, nil)
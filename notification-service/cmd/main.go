@@ -67,11 +67,15 @@ func main() {
 
 	// 6. Инициализация слоев
 	repo := repository.NewNotificationRepository(db)
+	if err := repo.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("Failed to create notification indexes: %v", err)
+	}
 	notificationService := services.NewNotificationService(repo, rdb, fcm)
 	notificationHandler := handler.NewNotificationHandler(notificationService)
 
 	// 7. Запуск подписки на единый Redis-канал (новая версия)
 	go notificationService.StartRedisSubscriber(ctx)
+	go notificationService.StartStreamConsumer(ctx)
 
 	// 8. Инициализация маршрутов
 	router := gin.New()
@@ -53,6 +53,8 @@ type Notification struct {
 	IsRead       bool               `bson:"is_read" json:"is_read"`
 	Metadata     map[string]string  `bson:"metadata,omitempty" json:"metadata,omitempty"`
 	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	// EventID — ключ идемпотентности события, из которого создано уведомление
+	EventID string `bson:"event_id,omitempty" json:"-"`
 }
 
 // PushNotificationRequest используется для ручной отправки push-уведомления
@@ -21,12 +21,42 @@ func NewNotificationRepository(db *mongo.Database) *NotificationRepository {
 	}
 }
 
+// EnsureIndexes — не больше одного уведомления на событие (event_id).
+func (r *NotificationRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "event_id", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+			"event_id": bson.M{"$exists": true},
+		}),
+	})
+	return err
+}
+
 func (r *NotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
 	notification.CreatedAt = time.Now()
 	_, err := r.collection.InsertOne(ctx, notification)
 	return err
 }
 
+// CreateOnce сохраняет уведомление события notification.EventID, если его ещё нет.
+// false — уведомление по этому событию уже сохранено при прошлой доставке.
+func (r *NotificationRepository) CreateOnce(ctx context.Context, notification *models.Notification) (bool, error) {
+	notification.CreatedAt = time.Now()
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"event_id": notification.EventID},
+		bson.M{"$setOnInsert": notification},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		// Параллельная доставка того же события успела вставить его первой.
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return res.UpsertedCount == 1, nil
+}
+
 func (r *NotificationRepository) GetByUserID(ctx context.Context, userID string, limit, offset int64) ([]models.Notification, error) {
 	filter := bson.M{"user_id": userID}
 	opts := options.Find().SetLimit(limit).SetSkip(offset).SetSort(bson.D{{"created_at", -1}})
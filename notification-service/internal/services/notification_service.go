@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"log"
@@ -19,6 +20,9 @@ const (
 	NotificationEventsChannel = "notification_events"
 )
 
+// errMalformedEvent — payload события не разбирается; повторная обработка не поможет.
+var errMalformedEvent = errors.New("failed to unmarshal event")
+
 // NotificationEvent — структура события, публикуемого в Redis
 type NotificationEvent struct {
 	UserID      string            `json:"user_id"`
@@ -28,6 +32,8 @@ type NotificationEvent struct {
 	Message     string            `json:"message,omitempty"`      // можно переопределить дефолтный
 	ExtraData   map[string]string `json:"extra_data,omitempty"`   // любые дополнительные поля
 	DeviceToken string            `json:"device_token,omitempty"` // для push-уведомлений
+	// IdempotencyKey — ключ события у отправителя; при повторной доставке событие пропускается
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 }
 
 // Metadata о каждом типе события: дефолтный заголовок, текст, тип уведомления и DeliveryMethod
@@ -163,6 +169,69 @@ var eventMeta = map[string]struct {
 		NotifType:  models.TypeSystemMessage,
 		Delivery:   models.DeliveryPush,
 	},
+	// 19. Client asked to move an order
+	"reschedule_requested": {
+		Title:      "Reschedule requested",
+		DefaultMsg: "The client has proposed new time slots for an order. Please accept or decline.",
+		NotifType:  models.TypeOrderEvent,
+		Delivery:   models.DeliveryPush,
+	},
+	// 20. A cleaner answered the reschedule request
+	"reschedule_response": {
+		Title:      "Reschedule update",
+		DefaultMsg: "A cleaner has responded to your reschedule request.",
+		NotifType:  models.TypeOrderEvent,
+		Delivery:   models.DeliveryPush,
+	},
+	// 21. New time agreed
+	"reschedule_confirmed": {
+		Title:      "Order rescheduled",
+		DefaultMsg: "The order has been moved to a new time.",
+		NotifType:  models.TypeOrderEvent,
+		Delivery:   models.DeliveryPush,
+	},
+	// 22. Nobody could take the new time
+	"reschedule_failed": {
+		Title:      "Looking for a new cleaner",
+		DefaultMsg: "Your cleaners are not available at the new time. We are assigning a new cleaner.",
+		NotifType:  models.TypeOrderEvent,
+		Delivery:   models.DeliveryPush,
+	},
+	// 23. Cleaner removed from a rescheduled order
+	"reschedule_unassigned": {
+		Title:      "Order reassigned",
+		DefaultMsg: "An order was moved to a time you are not available and has been removed from your schedule.",
+		NotifType:  models.TypeOrderEvent,
+		Delivery:   models.DeliveryPush,
+	},
+	// 24. Client opened a dispute on a completed order
+	"dispute_opened": {
+		Title:      "Order disputed",
+		DefaultMsg: "The client has opened a dispute on your order. Payout is on hold until it is resolved.",
+		NotifType:  models.TypeOrderEvent,
+		Delivery:   models.DeliveryPush,
+	},
+	// 25. Manager resolved the dispute
+	"dispute_resolved": {
+		Title:      "Dispute resolved",
+		DefaultMsg: "The dispute on your order has been resolved.",
+		NotifType:  models.TypeOrderEvent,
+		Delivery:   models.DeliveryPush,
+	},
+	// 26. Client reviewed the cleaner
+	"review_received": {
+		Title:      "New review",
+		DefaultMsg: "A client has rated your work. You can reply to the review in the app.",
+		NotifType:  models.TypeOrderEvent,
+		Delivery:   models.DeliveryPush,
+	},
+	// 27. Client left a tip for the cleaner
+	"tip_received": {
+		Title:      "You received a tip",
+		DefaultMsg: "A client has left you a tip. It has been added to your earnings.",
+		NotifType:  models.TypeOrderEvent,
+		Delivery:   models.DeliveryPush,
+	},
 	// default на случай неизвестного типа
 	"default": {
 		Title:      "System notification",
@@ -181,6 +250,7 @@ type NotificationService struct {
 
 type NotificationRepository interface {
 	Create(ctx context.Context, notification *models.Notification) error
+	CreateOnce(ctx context.Context, notification *models.Notification) (bool, error)
 	GetByUserID(ctx context.Context, userID string, limit, offset int64) ([]models.Notification, error)
 	MarkAsRead(ctx context.Context, id primitive.ObjectID) error
 }
@@ -212,7 +282,7 @@ func (s *NotificationService) SendNotification(ctx context.Context, notification
 func (s *NotificationService) ProcessEvent(ctx context.Context, payload []byte) error {
 	var event NotificationEvent
 	if err := json.Unmarshal(payload, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal event: %w", err)
+		return fmt.Errorf("%w: %v", errMalformedEvent, err)
 	}
 
 	meta, ok := eventMeta[event.Type]
@@ -239,10 +309,24 @@ func (s *NotificationService) ProcessEvent(ctx context.Context, payload []byte)
 		IsRead:       false,
 		CreatedAt:    time.Now(),
 		Metadata:     event.ExtraData,
+		EventID:      event.IdempotencyKey,
 	}
 
-	// Сохраняем уведомление в БД
-	if err := s.SendNotification(ctx, notification); err != nil {
+	// Отправители доставляют события «хотя бы один раз»: уведомление с ключом сохраняется
+	// upsert'ом по event_id, поэтому повторная доставка ничего не дублирует, а событие,
+	// которое не удалось сохранить, при следующей доставке будет обработано заново.
+	if event.IdempotencyKey != "" {
+		created, err := s.repo.CreateOnce(ctx, notification)
+		if err != nil {
+			return fmt.Errorf("failed to save notification: %w", err)
+		}
+		if !created {
+			log.Printf("Skipping duplicate event %s", event.IdempotencyKey)
+			return nil
+		}
+		log.Printf("Notification saved - Type: %s, User: %s, Title: %s",
+			notification.Type, notification.UserID, notification.Title)
+	} else if err := s.SendNotification(ctx, notification); err != nil {
 		return err
 	}
 
@@ -256,7 +340,9 @@ func (s *NotificationService) ProcessEvent(ctx context.Context, payload []byte)
 	return nil
 }
 
-// StartRedisSubscriber подписывается на единый канал и обрабатывает входящие события
+// StartRedisSubscriber подписывается на единый канал и обрабатывает входящие события.
+// Канал остаётся для сервисов, публикующих через PUBLISH; order-service пишет в стрим
+// (см. StartStreamConsumer).
 func (s *NotificationService) StartRedisSubscriber(ctx context.Context) {
 	pubsub := s.redis.Subscribe(ctx, NotificationEventsChannel)
 	defer pubsub.Close()
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// NotificationEventsStream — стрим, в который order-service пишет события (XADD).
+	// В отличие от pub/sub событие остаётся в стриме, пока группа его не подтвердит.
+	NotificationEventsStream = "notification_events_stream"
+	notificationGroup        = "notification-service"
+
+	// streamClaimIdle — через сколько неподтверждённое событие забирается на повторную обработку
+	// (упавший экземпляр сервиса или ошибка сохранения).
+	streamClaimIdle = time.Minute
+	streamBatchSize = 50
+	streamBlock     = 5 * time.Second
+)
+
+// StartStreamConsumer читает notification_events_stream через группу потребителей.
+// Событие подтверждается (XACK) только после успешной обработки; иначе оно остаётся
+// в списке ожидающих и через streamClaimIdle обрабатывается повторно.
+func (s *NotificationService) StartStreamConsumer(ctx context.Context) {
+	err := s.redis.XGroupCreateMkStream(ctx, NotificationEventsStream, notificationGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		log.Printf("Failed to create consumer group for %s: %v", NotificationEventsStream, err)
+		return
+	}
+	consumer, _ := os.Hostname()
+	if consumer == "" {
+		consumer = "notification-service"
+	}
+	log.Printf("Consuming Redis stream %s as %s/%s", NotificationEventsStream, notificationGroup, consumer)
+
+	lastClaim := time.Time{}
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= streamClaimIdle {
+			s.claimStale(ctx, consumer)
+			lastClaim = time.Now()
+		}
+		streams, err := s.redis.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    notificationGroup,
+			Consumer: consumer,
+			Streams:  []string{NotificationEventsStream, ">"},
+			Count:    streamBatchSize,
+			Block:    streamBlock,
+		}).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+				continue
+			}
+			log.Printf("Failed to read %s: %v", NotificationEventsStream, err)
+			time.Sleep(time.Second)
+			continue
+		}
+		for _, stream := range streams {
+			s.handleStreamMessages(ctx, stream.Messages)
+		}
+	}
+	log.Println("Stopping Redis stream consumer...")
+}
+
+// claimStale забирает события, которые давно не подтверждены, и обрабатывает их заново.
+func (s *NotificationService) claimStale(ctx context.Context, consumer string) {
+	start := "0-0"
+	for {
+		messages, next, err := s.redis.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   NotificationEventsStream,
+			Group:    notificationGroup,
+			Consumer: consumer,
+			MinIdle:  streamClaimIdle,
+			Start:    start,
+			Count:    streamBatchSize,
+		}).Result()
+		if err != nil {
+			log.Printf("Failed to claim pending events from %s: %v", NotificationEventsStream, err)
+			return
+		}
+		s.handleStreamMessages(ctx, messages)
+		if next == "0-0" || len(messages) == 0 {
+			return
+		}
+		start = next
+	}
+}
+
+func (s *NotificationService) handleStreamMessages(ctx context.Context, messages []redis.XMessage) {
+	for _, msg := range messages {
+		payload, _ := msg.Values["payload"].(string)
+		if err := s.ProcessEvent(ctx, []byte(payload)); err != nil {
+			log.Printf("Error processing stream event %s: %v", msg.ID, err)
+			if !errors.Is(err, errMalformedEvent) {
+				continue // не подтверждаем: событие будет обработано повторно
+			}
+		}
+		if err := s.redis.XAck(ctx, NotificationEventsStream, notificationGroup, msg.ID).Err(); err != nil {
+			log.Printf("Failed to ack stream event %s: %v", msg.ID, err)
+		}
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
 	"net/http"
+	"time"
+	_ "time/tzdata" // SERVICE_TIMEZONE и tz в запросах работают и в образе без системной базы поясов
 )
 
 func main() {
@@ -55,36 +57,95 @@ func main() {
 	})
 
 	// 4. Инициализация сервисов
-	orderRepo := repository.NewOrderRepository(db)
-	authClient := utils.NewAuthClient(cfg.AuthServiceURL)
-	orderService := services.NewOrderService(orderRepo, rdb, cfg, authClient)
+	orderRepo := repository.NewOrderRepository(db, time.Duration(cfg.DefaultOrderDurationMinutes)*time.Minute)
+	if err := orderRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatal("Failed to create order indexes:", err)
+	}
+	historyRepo := repository.NewStatusHistoryRepository(db)
+	if err := historyRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatal("Failed to create status history indexes:", err)
+	}
+	scheduleRepo := repository.NewScheduleRepository(db)
+	rescheduleRepo := repository.NewRescheduleRepository(db)
+	promoRepo := repository.NewPromoRepository(db)
+	if err := promoRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatal("Failed to create promo code indexes:", err)
+	}
+	addressRepo := repository.NewAddressRepository(db)
+	zoneRepo := repository.NewZoneRepository(db)
+	if err := zoneRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatal("Failed to create zone indexes:", err)
+	}
+	outboxRepo := repository.NewOutboxRepository(db)
+	if err := outboxRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatal("Failed to create outbox indexes:", err)
+	}
+	reviewRepo := repository.NewReviewRepository(db)
+	if err := reviewRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatal("Failed to create review indexes:", err)
+	}
+	earningsRepo := repository.NewEarningsRepository(db)
+	if err := earningsRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatal("Failed to create earnings indexes:", err)
+	}
+	tipRepo := repository.NewTipRepository(db)
+	if err := tipRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatal("Failed to create tip indexes:", err)
+	}
+	txRunner := repository.NewTxRunner(db)
+	if err := txRunner.Detect(ctx); err != nil {
+		log.Fatal("Failed to detect MongoDB transaction support:", err)
+	}
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	if err := idempotencyRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatal("Failed to create idempotency indexes:", err)
+	}
+	authClient := utils.NewAuthClient(cfg.AuthServiceURL, cfg.InternalAPISecret)
+	orderService := services.NewOrderService(orderRepo, historyRepo, scheduleRepo, rescheduleRepo, promoRepo, addressRepo, zoneRepo, reviewRepo, earningsRepo, tipRepo, outboxRepo, txRunner, rdb, cfg, authClient)
 	orderHandler := handler.NewOrderHandler(orderService, rdb, cfg)
 
-	// 5. Старт фонового кэш-рефрешера
-	cacheRefresher := services.NewCacheRefresher(orderService, rdb)
-	cacheRefresher.Start(ctx)
+	// 5. Фоновые задачи
+	outboxRelay := services.NewOutboxRelay(outboxRepo, cfg)
+	outboxRelay.Start(ctx)
 
-	cron := services.NewCronJobService(orderRepo, cfg)
+	cron := services.NewCronJobService(orderRepo, outboxRepo, txRunner, rdb, cfg)
 	cron.Start(ctx)
 
+	autoAssign := services.NewAutoAssignJob(orderService, cfg)
+	autoAssign.Start(ctx)
+
 	// 6. Настройка роутера
 	router := gin.New()
 	router.Use(gin.Logger(), gin.Recovery())
 	router.RedirectTrailingSlash = false
 
 	authMW := utils.AuthMiddleware(cfg.AuthServiceURL)
+	// Idempotency-Key на изменяющих запросах; GET пропускаются.
+	idemMW := utils.IdempotencyMiddleware(idempotencyRepo, time.Duration(cfg.IdempotencyTTLHours)*time.Hour)
 
 	orders := router.Group("/orders")
-	orders.Use(authMW)
+	orders.Use(authMW, idemMW)
 	{
 		orders.POST("", orderHandler.CreateOrder)
 		orders.POST("/", orderHandler.CreateOrder)
 
 		orders.GET("/my", orderHandler.GetMyOrders)
+		orders.POST("/quote", orderHandler.QuoteOrder) // body: { "service_ids": [...], "property": {...}, "date": RFC3339, "promo_code": "..." }
 		orders.GET("/:id", orderHandler.GetOrderByIDHTTP)
 		orders.PUT("/:id", orderHandler.UpdateOrder)
-		orders.DELETE("/:id", orderHandler.DeleteOrder)
-		orders.POST("/:id/review", orderHandler.AddOrderReview)
+		orders.POST("/:id/cancel", orderHandler.CancelOrder) // body: { "reason": "..." }
+		orders.GET("/:id/cancel/quote", orderHandler.QuoteCancellation)
+		orders.POST("/:id/review", utils.RequireRoles("client"), orderHandler.AddOrderReview) // body: { "reviews": [{ "cleaner_id": "...", "ratings": {...}, "comment": "..." }] }
+		orders.GET("/:id/reviews", orderHandler.GetOrderReviews)
+		orders.POST("/:id/tip", utils.RequireRoles("client"), orderHandler.TipOrder) // body: { "amount": 500, "message": "..." }
+		orders.GET("/:id/tips", orderHandler.GetOrderTips)
+		orders.POST("/:id/requote", orderHandler.RequoteOrder) // body (опц.): { "service_ids": [...] }
+		orders.GET("/:id/history", orderHandler.GetStatusHistory)
+		orders.GET("/:id/checklist", orderHandler.GetChecklist)
+		orders.POST("/:id/dispute", utils.RequireRoles("client"), orderHandler.OpenDispute) // body: { "category": "...", "description": "...", "photo_urls": [...] }
+		orders.GET("/:id/reschedule", orderHandler.GetReschedule)
+		orders.POST("/:id/reschedule", utils.RequireRoles("client"), orderHandler.RequestReschedule)          // body: { "slots": [RFC3339...], "reason": "..." }
+		orders.POST("/:id/reschedule/respond", utils.RequireRoles("cleaner"), orderHandler.RespondReschedule) // body: { "accept": true, "slot": RFC3339, "reason": "..." }
 
 		protected := orders.Group("/")
 		protected.Use(utils.RequireRoles("manager", "admin"))
@@ -92,11 +153,18 @@ func main() {
 			protected.PUT("/:id/assign", orderHandler.AssignCleaner)           // body: { "cleaner_id": "..." }
 			protected.PUT("/:id/assign-multiple", orderHandler.AssignCleaners) // body: { "cleaner_ids": ["id1","id2"] }
 			protected.PUT("/:id/unassign", orderHandler.UnassignCleaner)       // body: { "cleaner_id": "..." }
+			protected.GET("/:id/auto-assign/preview", orderHandler.PreviewAutoAssign)
+			protected.POST("/:id/auto-assign", orderHandler.AutoAssign)         // body (опц.): { "cleaner_ids": [...] }
+			protected.POST("/:id/dispute/resolve", orderHandler.ResolveDispute) // body: { "outcome": "...", "refund_amount": .., "reclean_date": RFC3339, "note": "..." }
+
+			// Жёсткое удаление — только для персонала; клиенты отменяют через /cancel.
+			protected.DELETE("/:id", orderHandler.DeleteOrder)
 
 			protected.GET("/all", orderHandler.GetAllOrders)
 			protected.GET("/filter", orderHandler.FilterOrders)
 			protected.GET("/stats", orderHandler.GetActiveOrdersCount)
 			protected.GET("/total-revenue", orderHandler.GetTotalRevenue)
+			protected.GET("/cache/stats", orderHandler.GetCacheStats)
 		}
 
 		protectedCleaner := orders.Group("/")
@@ -110,7 +178,100 @@ func main() {
 		}
 
 	}
-	router.POST("/api/internal/payments/notify", orderHandler.HandlePaymentNotification)
+	cleaners := router.Group("/cleaners")
+	cleaners.Use(authMW, idemMW)
+	{
+		cleaners.GET("/me/schedule", utils.RequireRoles("cleaner"), orderHandler.GetMySchedule)
+		cleaners.PUT("/me/schedule", utils.RequireRoles("cleaner"), orderHandler.UpdateMySchedule)
+		cleaners.GET("/:id/reviews", orderHandler.GetCleanerReviews) // ?limit=&cursor=
+
+		cleanersMgr := cleaners.Group("/")
+		cleanersMgr.Use(utils.RequireRoles("manager", "admin"))
+		{
+			cleanersMgr.GET("/availability", orderHandler.GetCleanersAvailability) // ?from=&to=&duration=
+			cleanersMgr.GET("/routes", orderHandler.GetRoutes)                     // ?date=YYYY-MM-DD&tz=
+			cleanersMgr.GET("/:id/route", orderHandler.GetCleanerRoute)            // ?date=YYYY-MM-DD&tz=
+			cleanersMgr.GET("/:id/schedule", orderHandler.GetCleanerSchedule)
+			cleanersMgr.PUT("/:id/schedule", orderHandler.UpdateCleanerSchedule)
+		}
+	}
+
+	cleaner := router.Group("/cleaner")
+	cleaner.Use(authMW, utils.RequireRoles("cleaner"), idemMW)
+	{
+		cleaner.GET("/route", orderHandler.GetMyRoute)                          // ?date=YYYY-MM-DD&tz=
+		cleaner.POST("/:id/check-in", orderHandler.CheckIn)                     // :id — заказ; body: { "lat": .., "lng": .. }
+		cleaner.POST("/:id/check-out", orderHandler.CheckOut)                   // :id — заказ; body: { "lat": .., "lng": .. }
+		cleaner.PUT("/:id/checklist/:itemId", orderHandler.UpdateChecklistItem) // body: { "status": "done|skipped|pending", "reason": "..." }
+		cleaner.GET("/earnings", orderHandler.GetMyEarnings)
+		cleaner.GET("/earnings/statements/:id", orderHandler.GetStatement)
+	}
+
+	reviews := router.Group("/reviews")
+	reviews.Use(authMW, idemMW)
+	{
+		reviews.PUT("/:id/reply", utils.RequireRoles("cleaner"), orderHandler.ReplyToReview)                // body: { "text": "..." }
+		reviews.PUT("/:id/moderation", utils.RequireRoles("manager", "admin"), orderHandler.ModerateReview) // body: { "hidden": true, "flagged": false, "note": "..." }
+	}
+
+	addresses := router.Group("/addresses")
+	addresses.Use(authMW, idemMW)
+	{
+		addresses.GET("", utils.RequireRoles("client"), orderHandler.ListAddresses)
+		addresses.POST("", utils.RequireRoles("client"), orderHandler.CreateAddress)
+		addresses.GET("/:id", orderHandler.GetAddress)
+		addresses.PUT("/:id", utils.RequireRoles("client"), orderHandler.UpdateAddress)
+		addresses.DELETE("/:id", utils.RequireRoles("client"), orderHandler.DeleteAddress)
+	}
+
+	zones := router.Group("/zones")
+	zones.Use(authMW, idemMW)
+	{
+		zones.GET("/lookup", orderHandler.LookupZone) // ?lat=&lng=
+
+		adminOnly := utils.RequireRoles("admin")
+		zones.POST("", adminOnly, orderHandler.CreateZone) // body: { "name": "...", "area": GeoJSON Polygon, "active": true, ... }
+		zones.GET("", adminOnly, orderHandler.ListZones)
+		zones.GET("/:id", adminOnly, orderHandler.GetZone)
+		zones.PUT("/:id", adminOnly, orderHandler.UpdateZone)
+		zones.DELETE("/:id", adminOnly, orderHandler.DeleteZone)
+	}
+
+	tips := router.Group("/tips")
+	tips.Use(authMW)
+	{
+		tips.GET("/:id", orderHandler.GetTip)
+	}
+
+	earnings := router.Group("/earnings")
+	earnings.Use(authMW, utils.RequireRoles("admin"), idemMW)
+	{
+		earnings.POST("/statements", orderHandler.GenerateStatements) // body: { "period_end": RFC3339 }
+		earnings.GET("/statements", orderHandler.ListStatements)      // ?cleaner_id=&status=pending|paid
+		earnings.GET("/statements/:id", orderHandler.GetStatement)
+		earnings.PUT("/statements/:id/paid", orderHandler.MarkStatementPaid) // body: { "payment_ref": "..." }
+	}
+
+	analytics := router.Group("/analytics")
+	analytics.Use(authMW, utils.RequireRoles("manager", "admin"))
+	{
+		analytics.GET("/revenue", orderHandler.GetRevenueReport) // ?from=&to=&group_by=day|week|month|service|cleaner|zone&tz=&format=json|csv
+		analytics.GET("/kpi", orderHandler.GetKPIReport)         // ?from=&to=&format=json|csv
+	}
+
+	promos := router.Group("/promo-codes")
+	promos.Use(authMW, utils.RequireRoles("admin"), idemMW)
+	{
+		promos.POST("", orderHandler.CreatePromo)
+		promos.GET("", orderHandler.ListPromos)
+		promos.GET("/stats", orderHandler.GetPromoStats)
+		promos.GET("/:id", orderHandler.GetPromo)
+		promos.PUT("/:id", orderHandler.UpdatePromo)
+		promos.DELETE("/:id", orderHandler.DeletePromo)
+		promos.GET("/:id/stats", orderHandler.GetPromoCodeStats)
+	}
+
+	router.POST("/api/internal/payments/notify", utils.RequireWebhookSignature(cfg.PaymentWebhookSecret), orderHandler.HandlePaymentNotification)
 
 	// 7. Запуск сервера
 	server := &http.Server{
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"cleaning-app/order-service/internal/models"
+	"fmt"
 	"github.com/joho/godotenv"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -15,12 +19,92 @@ type Config struct {
 	NotifiServiceURL   string
 	CleaningDetailsURL string
 	UserManagementURL  string
+	PaymentServiceURL  string
+	SupportServiceURL  string
+	MediaServiceURL    string
+
+	// Общий с payment-service секрет подписи уведомлений о платежах.
+	PaymentWebhookSecret string
+	// Общий с auth-service и user-management секрет подписи межсервисных вызовов.
+	InternalAPISecret string
+
+	// Планирование: длительность заказа по умолчанию (если у услуг не указана)
+	// и буфер на дорогу между заказами одного клинера, в минутах.
+	DefaultOrderDurationMinutes int
+	TravelBufferMinutes         int
+
+	// Период фонового автоназначения клинеров, в минутах (0 — выключено).
+	AutoAssignIntervalMinutes int
+
+	// Политика отмены: бесплатно раньше чем за CancelFreeHours, иначе удерживается CancelFeePercent.
+	// CancelAllowInProgress разрешает клиенту отменять заказ уже после чекина клинера.
+	CancelFreeHours       int
+	CancelFeePercent      int
+	CancelAllowInProgress bool
+
+	// Налог в процентах, добавляемый к стоимости заказа при расчёте цены.
+	TaxPercent int
+
+	// Правила расчёта цены: надбавки за выходные и за заказ позже чем за ShortNoticeHours,
+	// минимальная сумма заказа (0 — без минимума) и срок жизни выданной цены.
+	WeekendSurchargePercent     int
+	ShortNoticeHours            int
+	ShortNoticeSurchargePercent int
+	MinOrderValue               int
+	QuoteTTLMinutes             int
+
+	// Допустимое расстояние от адреса заказа при отметке клинера, в метрах (0 — не проверять).
+	CheckInRadiusMeters int
+
+	// Время жизни закэшированных списков и страниц заказов, в секундах.
+	OrderCacheTTLSeconds int
+
+	// Relay outbox: как часто опрашивать коллекцию и сколько событий брать за проход.
+	OutboxPollSeconds int
+	OutboxBatchSize   int
+	// Через сколько минут после завершения заказа просить клиента оставить отзыв.
+	ReviewRequestDelayMinutes int
+
+	// За сколько до начала заказа напоминать клиенту (по возрастанию) и как часто проверять.
+	ReminderOffsets     []time.Duration
+	ReminderPollSeconds int
+
+	// Сколько часов хранится ответ по заголовку Idempotency-Key.
+	IdempotencyTTLHours int
+
+	// Средняя скорость переезда между заказами для дневного маршрута клинера, км/ч.
+	RouteSpeedKmh int
+
+	// Часовой пояс сервиса (SERVICE_TIMEZONE): в нём заданы рабочие часы клинеров
+	// и по нему режутся сутки маршрутов, если запрос не передал свой tz.
+	Location *time.Location
+
+	// Сколько часов после завершения клиент может открыть спор по заказу.
+	DisputeWindowHours int
+
+	// Заработок клинеров: комиссия платформы в процентах от выручки заказа и фиксированный
+	// сбор за каждую услугу заказа; EarningsServiceFees переопределяет сбор для отдельных услуг.
+	EarningsCommissionPercent int
+	EarningsServiceFee        int
+	EarningsServiceFees       map[string]float64
 }
 
 func LoadConfig() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		return nil, err
 	}
+	reminderOffsets, err := models.ParseReminderOffsets(getEnv("REMINDER_OFFSETS", "24h,2h"))
+	if err != nil {
+		return nil, err
+	}
+	serviceFees, err := models.ParseServiceFees(getEnv("EARNINGS_SERVICE_FEES", ""))
+	if err != nil {
+		return nil, err
+	}
+	location, err := time.LoadLocation(getEnv("SERVICE_TIMEZONE", "UTC"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SERVICE_TIMEZONE: %w", err)
+	}
 	raw := os.Getenv("USER_MANAGEMENT_SERVICE_URL")
 	trimmed := strings.Trim(raw, "\"")
 
@@ -33,5 +117,64 @@ func LoadConfig() (*Config, error) {
 		NotifiServiceURL:   os.Getenv("NOTIFI_SERVICE_URL"),
 		CleaningDetailsURL: os.Getenv("CLEANING_DETAILS_SERVICE_URL"),
 		UserManagementURL:  trimmed,
+		PaymentServiceURL:  os.Getenv("PAYMENT_SERVICE_URL"),
+		SupportServiceURL:  os.Getenv("SUPPORT_SERVICE_URL"),
+		MediaServiceURL:    os.Getenv("MEDIA_SERVICE_URL"),
+
+		PaymentWebhookSecret: getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+		InternalAPISecret:    getEnv("INTERNAL_API_SECRET", ""),
+
+		DefaultOrderDurationMinutes: getEnvInt("DEFAULT_ORDER_DURATION_MINUTES", 120),
+		TravelBufferMinutes:         getEnvInt("TRAVEL_BUFFER_MINUTES", 30),
+		AutoAssignIntervalMinutes:   getEnvInt("AUTO_ASSIGN_INTERVAL_MINUTES", 5),
+		CancelFreeHours:             getEnvInt("CANCEL_FREE_HOURS", 24),
+		CancelFeePercent:            getEnvInt("CANCEL_FEE_PERCENT", 50),
+		CancelAllowInProgress:       getEnvBool("CANCEL_ALLOW_IN_PROGRESS", false),
+		TaxPercent:                  getEnvInt("TAX_PERCENT", 0),
+		WeekendSurchargePercent:     getEnvInt("WEEKEND_SURCHARGE_PERCENT", 20),
+		ShortNoticeHours:            getEnvInt("SHORT_NOTICE_HOURS", 24),
+		ShortNoticeSurchargePercent: getEnvInt("SHORT_NOTICE_SURCHARGE_PERCENT", 15),
+		MinOrderValue:               getEnvInt("MIN_ORDER_VALUE", 0),
+		QuoteTTLMinutes:             getEnvInt("QUOTE_TTL_MINUTES", 30),
+		CheckInRadiusMeters:         getEnvInt("CHECK_IN_RADIUS_METERS", 300),
+		DisputeWindowHours:          getEnvInt("DISPUTE_WINDOW_HOURS", 72),
+		RouteSpeedKmh:               getEnvInt("ROUTE_SPEED_KMH", 25),
+		Location:                    location,
+		OrderCacheTTLSeconds:        getEnvInt("ORDER_CACHE_TTL_SECONDS", 300),
+		OutboxPollSeconds:           getEnvInt("OUTBOX_POLL_SECONDS", 2),
+		OutboxBatchSize:             getEnvInt("OUTBOX_BATCH_SIZE", 100),
+		ReviewRequestDelayMinutes:   getEnvInt("REVIEW_REQUEST_DELAY_MINUTES", 60),
+		IdempotencyTTLHours:         getEnvInt("IDEMPOTENCY_TTL_HOURS", 24),
+		ReminderOffsets:             reminderOffsets,
+		ReminderPollSeconds:         getEnvInt("REMINDER_POLL_SECONDS", 60),
+		EarningsCommissionPercent:   getEnvInt("EARNINGS_COMMISSION_PERCENT", 20),
+		EarningsServiceFee:          getEnvInt("EARNINGS_SERVICE_FEE", 0),
+		EarningsServiceFees:         serviceFees,
 	}, nil
 }
+
+// getEnv читает строку из окружения, при отсутствии — значение по умолчанию.
+func getEnv(key, def string) string {
+	if v := strings.Trim(os.Getenv(key), "\""); v != "" {
+		return v
+	}
+	return def
+}
+
+// getEnvInt читает целое из окружения, при отсутствии или ошибке — значение по умолчанию.
+func getEnvInt(key string, def int) int {
+	v, err := strconv.Atoi(strings.Trim(os.Getenv(key), "\""))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getEnvBool читает флаг из окружения, при отсутствии или ошибке — значение по умолчанию.
+func getEnvBool(key string, def bool) bool {
+	v, err := strconv.ParseBool(strings.Trim(os.Getenv(key), "\""))
+	if err != nil {
+		return def
+	}
+	return v
+}
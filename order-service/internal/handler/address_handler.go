@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+
+	"cleaning-app/order-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GET /addresses — сохранённые адреса текущего клиента
+func (h *OrderHandler) ListAddresses(c *gin.Context) {
+	addresses, err := h.service.ListAddresses(c.Request.Context(), c.GetString("userId"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, addresses)
+}
+
+// GET /addresses/:id
+func (h *OrderHandler) GetAddress(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	addr, err := h.service.GetAddress(c.Request.Context(), id, c.GetString("userId"), c.GetString("role"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, addr)
+}
+
+// POST /addresses — body: { "label": "Дом", "city": "...", "street": "...", "building": "...",
+// "apartment": "...", "floor": "...", "entrance_code": "...", "location": { "lat": .., "lng": .. }, "notes": "..." }
+func (h *OrderHandler) CreateAddress(c *gin.Context) {
+	var addr models.Address
+	if err := c.ShouldBindJSON(&addr); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if err := h.service.CreateAddress(c.Request.Context(), c.GetString("userId"), &addr); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, addr)
+}
+
+// PUT /addresses/:id — тело как при создании
+func (h *OrderHandler) UpdateAddress(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	var addr models.Address
+	if err := c.ShouldBindJSON(&addr); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if err := h.service.UpdateAddress(c.Request.Context(), id, c.GetString("userId"), &addr); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, addr)
+}
+
+// DELETE /addresses/:id
+func (h *OrderHandler) DeleteAddress(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	if err := h.service.DeleteAddress(c.Request.Context(), id, c.GetString("userId")); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// parseAnalyticsQuery читает общие параметры отчётов: from, to (RFC3339), group_by, tz.
+// Без tz дни, недели и месяцы режутся в часовом поясе сервиса (SERVICE_TIMEZONE).
+func (h *OrderHandler) parseAnalyticsQuery(c *gin.Context) (*models.AnalyticsQuery, bool) {
+	from, err := time.Parse(time.RFC3339, c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'from', expected RFC3339"})
+		return nil, false
+	}
+	to, err := time.Parse(time.RFC3339, c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'to', expected RFC3339"})
+		return nil, false
+	}
+	return &models.AnalyticsQuery{
+		From:     from.UTC(),
+		To:       to.UTC(),
+		GroupBy:  models.AnalyticsGroupBy(c.Query("group_by")),
+		Timezone: c.DefaultQuery("tz", h.serviceTimezone()),
+	}, true
+}
+
+// serviceTimezone — имя часового пояса сервиса для параметров tz по умолчанию.
+func (h *OrderHandler) serviceTimezone() string {
+	if h.cfg == nil || h.cfg.Location == nil {
+		return "UTC"
+	}
+	return h.cfg.Location.String()
+}
+
+// wantsCSV — ?format=csv; пусто или json — JSON, прочее — ошибка.
+func wantsCSV(c *gin.Context) (bool, bool) {
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		return false, true
+	case "csv":
+		return true, true
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'format', expected json or csv"})
+	return false, false
+}
+
+func respondAnalyticsError(c *gin.Context, err error) {
+	if errors.Is(err, models.ErrValidation) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// writeCSV отдаёт строки как файл name.csv.
+func writeCSV(c *gin.Context, name string, rows [][]string) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	_ = w.WriteAll(rows)
+}
+
+func formatMoney(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func formatRate(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
+
+// GET /analytics/revenue?from=RFC3339&to=RFC3339[&group_by=day|week|month|service|cleaner|zone][&tz=Europe/Moscow, по умолчанию SERVICE_TIMEZONE][&format=json|csv]
+func (h *OrderHandler) GetRevenueReport(c *gin.Context) {
+	q, ok := h.parseAnalyticsQuery(c)
+	if !ok {
+		return
+	}
+	asCSV, ok := wantsCSV(c)
+	if !ok {
+		return
+	}
+	report, err := h.service.RevenueReport(c.Request.Context(), q)
+	if err != nil {
+		respondAnalyticsError(c, err)
+		return
+	}
+	if !asCSV {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	rows := [][]string{{"key", "label", "revenue", "cancellation_fees", "orders", "average_order_value"}}
+	for _, b := range append(report.Buckets, report.Total) {
+		rows = append(rows, []string{b.Key, b.Label, formatMoney(b.Revenue), formatMoney(b.CancellationFees), strconv.FormatInt(b.Orders, 10), formatMoney(b.AverageOrderValue)})
+	}
+	writeCSV(c, fmt.Sprintf("revenue_%s_%s_%s", report.GroupBy, report.From.Format("20060102"), report.To.Format("20060102")), rows)
+}
+
+// GET /analytics/kpi?from=RFC3339&to=RFC3339[&format=json|csv]
+func (h *OrderHandler) GetKPIReport(c *gin.Context) {
+	q, ok := h.parseAnalyticsQuery(c)
+	if !ok {
+		return
+	}
+	asCSV, ok := wantsCSV(c)
+	if !ok {
+		return
+	}
+	report, err := h.service.KPIReport(c.Request.Context(), q)
+	if err != nil {
+		respondAnalyticsError(c, err)
+		return
+	}
+	if !asCSV {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	rows := [][]string{
+		{"metric", "value"},
+		{"revenue", formatMoney(report.Revenue)},
+		{"cancellation_fees", formatMoney(report.CancellationFees)},
+		{"orders", strconv.FormatInt(report.Orders, 10)},
+		{"average_order_value", formatMoney(report.AverageOrderValue)},
+		{"subscription_revenue", formatMoney(report.SubscriptionRevenue)},
+		{"subscription_share", formatRate(report.SubscriptionShare)},
+		{"total_orders", strconv.FormatInt(report.TotalOrders, 10)},
+		{"cancelled_orders", strconv.FormatInt(report.CancelledOrders, 10)},
+		{"cancellation_rate", formatRate(report.CancellationRate)},
+		{"clients", strconv.FormatInt(report.Clients, 10)},
+		{"repeat_clients", strconv.FormatInt(report.RepeatClients, 10)},
+		{"repeat_client_rate", formatRate(report.RepeatClientRate)},
+	}
+	writeCSV(c, fmt.Sprintf("kpi_%s_%s", report.From.Format("20060102"), report.To.Format("20060102")), rows)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"cleaning-app/order-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GET /orders/:id/auto-assign/preview — ранжирование кандидатов без назначения
+func (h *OrderHandler) PreviewAutoAssign(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	plan, err := h.service.PreviewAutoAssign(c.Request.Context(), id)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
+// POST /orders/:id/auto-assign — body (необязательно): { "cleaner_ids": [...] } из превью
+func (h *OrderHandler) AutoAssign(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	var body struct {
+		CleanerIDs []string `json:"cleaner_ids"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+	}
+
+	plan, err := h.service.AutoAssign(c.Request.Context(), id, body.CleanerIDs)
+	if err != nil {
+		if errors.Is(err, models.ErrNotEnoughCleaners) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "plan": plan})
+			return
+		}
+		var transitionErr *models.TransitionError
+		if errors.As(err, &transitionErr) {
+			handleServiceError(c, err)
+			return
+		}
+		respondAssignError(c, err)
+		return
+	}
+	h.clearCache(c.Request.Context())
+	c.JSON(http.StatusOK, plan)
+}
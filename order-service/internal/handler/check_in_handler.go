@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"cleaning-app/order-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// bindLocation разбирает :id заказа и координаты клинера из тела { "lat": .., "lng": .. }.
+func bindLocation(c *gin.Context) (primitive.ObjectID, models.GeoPoint, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return id, models.GeoPoint{}, false
+	}
+	var loc models.GeoPoint
+	if err := c.ShouldBindJSON(&loc); err != nil || !loc.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "valid lat and lng are required"})
+		return id, loc, false
+	}
+	return id, loc, true
+}
+
+func respondCheckInError(c *gin.Context, err error) {
+	var tooFar *models.TooFarError
+	if errors.As(err, &tooFar) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":           err.Error(),
+			"distance_meters": tooFar.DistanceMeters,
+			"radius_meters":   tooFar.RadiusMeters,
+		})
+		return
+	}
+	handleServiceError(c, err)
+}
+
+// POST /cleaner/:id/check-in — body: { "lat": 43.2, "lng": 76.9 }
+func (h *OrderHandler) CheckIn(c *gin.Context) {
+	id, loc, ok := bindLocation(c)
+	if !ok {
+		return
+	}
+	order, err := h.service.CheckIn(c.Request.Context(), id, c.GetString("userId"), loc)
+	if err != nil {
+		respondCheckInError(c, err)
+		return
+	}
+	h.clearCache(c.Request.Context())
+	c.JSON(http.StatusOK, order)
+}
+
+// POST /cleaner/:id/check-out — body: { "lat": 43.2, "lng": 76.9 }
+func (h *OrderHandler) CheckOut(c *gin.Context) {
+	id, loc, ok := bindLocation(c)
+	if !ok {
+		return
+	}
+	order, err := h.service.CheckOut(c.Request.Context(), id, c.GetString("userId"), loc)
+	if err != nil {
+		respondCheckInError(c, err)
+		return
+	}
+	h.clearCache(c.Request.Context())
+	c.JSON(http.StatusOK, order)
+}
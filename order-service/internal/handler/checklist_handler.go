@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"cleaning-app/order-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GET /orders/:id/checklist
+func (h *OrderHandler) GetChecklist(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+	items, err := h.service.GetChecklist(c.Request.Context(), id, c.GetString("userId"), c.GetString("role"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// PUT /cleaner/:id/checklist/:itemId — body: { "status": "done" | "skipped" | "pending", "reason": "..." }
+func (h *OrderHandler) UpdateChecklistItem(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+	var body struct {
+		Status models.ChecklistStatus `json:"status" binding:"required"`
+		Reason string                 `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	item, err := h.service.UpdateChecklistItem(c.Request.Context(), id, c.Param("itemId"), c.GetString("userId"), body.Status, body.Reason)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
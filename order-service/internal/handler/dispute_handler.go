@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+
+	"cleaning-app/order-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// POST /orders/:id/dispute — body: { "category": "quality", "description": "...", "photo_urls": [...] }
+// Фото предварительно загружаются в media-service: POST /media/dispute/:orderId.
+func (h *OrderHandler) OpenDispute(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	var body struct {
+		Category    models.DisputeCategory `json:"category" binding:"required"`
+		Description string                 `json:"description" binding:"required"`
+		PhotoURLs   []string               `json:"photo_urls"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	dispute := &models.Dispute{Category: body.Category, Description: body.Description, PhotoURLs: body.PhotoURLs}
+	order, err := h.service.OpenDispute(c.Request.Context(), id, c.GetString("userId"), dispute, c.GetHeader("Authorization"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	h.clearCache(c.Request.Context())
+	c.JSON(http.StatusCreated, order)
+}
+
+// POST /orders/:id/dispute/resolve — body: { "outcome": "reclean|partial_refund|full_refund|rejected",
+// "refund_amount": 1000, "reclean_date": RFC3339, "note": "..." }
+func (h *OrderHandler) ResolveDispute(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	var res models.Resolution
+	if err := c.ShouldBindJSON(&res); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	order, err := h.service.ResolveDispute(c.Request.Context(), id, c.GetString("userId"), &res, c.GetHeader("Authorization"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	h.clearCache(c.Request.Context())
+	c.JSON(http.StatusOK, order)
+}
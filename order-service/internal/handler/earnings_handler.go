@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GET /cleaner/earnings — баланс, записи вне ведомостей и последние ведомости клинера.
+func (h *OrderHandler) GetMyEarnings(c *gin.Context) {
+	summary, err := h.service.GetCleanerEarnings(c.Request.Context(), c.GetString("userId"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// GET /cleaner/earnings/statements/:id и GET /earnings/statements/:id — ведомость с записями.
+func (h *OrderHandler) GetStatement(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid statement ID"})
+		return
+	}
+	st, err := h.service.GetStatement(c.Request.Context(), id, c.GetString("userId"), c.GetString("role"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// GET /earnings/statements?cleaner_id=&status=pending|paid
+func (h *OrderHandler) ListStatements(c *gin.Context) {
+	status := models.StatementStatus(c.Query("status"))
+	if status != "" && status != models.StatementPending && status != models.StatementPaid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'status', expected pending or paid"})
+		return
+	}
+	statements, err := h.service.ListStatements(c.Request.Context(), c.Query("cleaner_id"), status)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, statements)
+}
+
+// POST /earnings/statements — body: { "period_end": RFC3339 }; без period_end период
+// закрывается на начало текущих суток (UTC).
+func (h *OrderHandler) GenerateStatements(c *gin.Context) {
+	var body struct {
+		PeriodEnd *time.Time `json:"period_end"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	periodEnd := time.Now().UTC().Truncate(24 * time.Hour)
+	if body.PeriodEnd != nil {
+		periodEnd = body.PeriodEnd.UTC()
+	}
+	statements, err := h.service.GenerateStatements(c.Request.Context(), periodEnd)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, statements)
+}
+
+// PUT /earnings/statements/:id/paid — body: { "payment_ref": "..." }
+func (h *OrderHandler) MarkStatementPaid(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid statement ID"})
+		return
+	}
+	var body struct {
+		PaymentRef string `json:"payment_ref"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	st, err := h.service.MarkStatementPaid(c.Request.Context(), id, c.GetString("userId"), body.PaymentRef)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
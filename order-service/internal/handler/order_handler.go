@@ -3,14 +3,15 @@ package handler
 import (
 	"cleaning-app/order-service/internal/config"
 	"cleaning-app/order-service/internal/models"
-	"cleaning-app/order-service/internal/utils"
 	"context"
-	"fmt"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -32,18 +33,87 @@ type OrderService interface {
 	ConfirmCompletion(ctx context.Context, id primitive.ObjectID, photoURL string) error
 
 	GetOrderByID(ctx context.Context, id primitive.ObjectID) (*models.Order, error)
-	GetAllOrders(ctx context.Context) ([]models.Order, error)
 	GetOrdersByClient(ctx context.Context, clientID string) ([]models.Order, error)
-	FilterOrders(ctx context.Context, filter map[string]interface{}) ([]models.Order, error)
+	SearchOrders(ctx context.Context, q *models.OrderSearch) (*models.OrderPage, error)
 	GetActiveOrdersCount(ctx context.Context) (int64, error)
 	GetTotalRevenue(ctx context.Context) (float64, error)
+	CacheStats() []models.CacheStats
 	UpdatePaymentStatus(ctx context.Context, orderID string, status string) error
 
 	CountJobsDone(ctx context.Context, cleanerID primitive.ObjectID) (int64, error)
 	FinishOrder(ctx context.Context, orderID primitive.ObjectID, cleanerID primitive.ObjectID, photoURL string) error
 	GetOrderForCleaner(ctx context.Context, orderID primitive.ObjectID, cleanerID primitive.ObjectID) (*models.Order, error)
 	GetOrdersForCleaner(ctx context.Context, cleanerID primitive.ObjectID) ([]models.Order, error)
-	AddReview(ctx context.Context, orderID string, rating int, comment string, authHeader string) error
+	GetStatusHistory(ctx context.Context, id primitive.ObjectID, userID, role string) ([]models.StatusHistoryEntry, error)
+
+	GetSchedule(ctx context.Context, cleanerID string) (*models.CleanerSchedule, error)
+	SaveSchedule(ctx context.Context, cleanerID string, schedule *models.CleanerSchedule) error
+	GetAvailability(ctx context.Context, from, to time.Time, duration time.Duration, cleanerIDs []string) ([]models.CleanerAvailability, error)
+
+	PreviewAutoAssign(ctx context.Context, id primitive.ObjectID) (*models.AssignmentPlan, error)
+	AutoAssign(ctx context.Context, id primitive.ObjectID, cleanerIDs []string) (*models.AssignmentPlan, error)
+
+	QuoteCancellation(ctx context.Context, id primitive.ObjectID, userID, role string) (*models.CancellationQuote, error)
+	CancelOrder(ctx context.Context, id primitive.ObjectID, userID, role, reason, authHeader string) (*models.Order, error)
+
+	GetReschedule(ctx context.Context, id primitive.ObjectID, userID, role string) (*models.RescheduleRequest, error)
+	RequestReschedule(ctx context.Context, id primitive.ObjectID, clientID string, slots []time.Time, reason string) (*models.RescheduleRequest, error)
+	RespondReschedule(ctx context.Context, id primitive.ObjectID, cleanerID string, accept bool, slot *time.Time, reason string) (*models.RescheduleRequest, error)
+
+	RequoteOrder(ctx context.Context, id primitive.ObjectID, userID, role string, serviceIDs []string) (*models.Order, error)
+	QuoteOrder(ctx context.Context, clientID string, req *models.QuoteRequest) (*models.Quote, error)
+
+	CreatePromo(ctx context.Context, code *models.PromoCode) error
+	UpdatePromo(ctx context.Context, id primitive.ObjectID, code *models.PromoCode) error
+	DeletePromo(ctx context.Context, id primitive.ObjectID) error
+	GetPromo(ctx context.Context, id primitive.ObjectID) (*models.PromoCode, error)
+	ListPromos(ctx context.Context) ([]models.PromoCode, error)
+	PromoStats(ctx context.Context, id *primitive.ObjectID) ([]models.PromoStats, error)
+
+	CheckIn(ctx context.Context, orderID primitive.ObjectID, cleanerID string, loc models.GeoPoint) (*models.Order, error)
+	CheckOut(ctx context.Context, orderID primitive.ObjectID, cleanerID string, loc models.GeoPoint) (*models.Order, error)
+
+	GetChecklist(ctx context.Context, id primitive.ObjectID, userID, role string) ([]models.ChecklistItem, error)
+	UpdateChecklistItem(ctx context.Context, id primitive.ObjectID, itemID, cleanerID string, status models.ChecklistStatus, reason string) (*models.ChecklistItem, error)
+
+	OpenDispute(ctx context.Context, id primitive.ObjectID, clientID string, dispute *models.Dispute, authHeader string) (*models.Order, error)
+	ResolveDispute(ctx context.Context, id primitive.ObjectID, managerID string, res *models.Resolution, authHeader string) (*models.Order, error)
+
+	ListAddresses(ctx context.Context, clientID string) ([]models.Address, error)
+	GetAddress(ctx context.Context, id primitive.ObjectID, userID, role string) (*models.Address, error)
+	CreateAddress(ctx context.Context, clientID string, addr *models.Address) error
+	UpdateAddress(ctx context.Context, id primitive.ObjectID, clientID string, addr *models.Address) error
+	DeleteAddress(ctx context.Context, id primitive.ObjectID, clientID string) error
+
+	CreateZone(ctx context.Context, zone *models.Zone) error
+	UpdateZone(ctx context.Context, id primitive.ObjectID, zone *models.Zone) error
+	DeleteZone(ctx context.Context, id primitive.ObjectID) error
+	GetZone(ctx context.Context, id primitive.ObjectID) (*models.Zone, error)
+	ListZones(ctx context.Context) ([]models.Zone, error)
+	LookupZone(ctx context.Context, loc models.GeoPoint) (*models.Zone, error)
+
+	AddReviews(ctx context.Context, orderID primitive.ObjectID, clientID string, inputs []models.ReviewInput) ([]*models.Review, error)
+	GetOrderReviews(ctx context.Context, orderID primitive.ObjectID, userID, role string) ([]models.Review, error)
+	GetCleanerReviews(ctx context.Context, cleanerID, role, cursor string, limit int) (*models.ReviewPage, error)
+	ReplyToReview(ctx context.Context, reviewID primitive.ObjectID, cleanerID, text string) (*models.Review, error)
+	ModerateReview(ctx context.Context, reviewID primitive.ObjectID, moderatorID string, in models.ReviewModerationInput) (*models.Review, error)
+
+	GetCleanerRoute(ctx context.Context, cleanerID, date, tz string) (*models.CleanerRoute, error)
+	GetRoutes(ctx context.Context, date, tz string) ([]models.CleanerRoute, error)
+
+	RevenueReport(ctx context.Context, q *models.AnalyticsQuery) (*models.RevenueReport, error)
+	KPIReport(ctx context.Context, q *models.AnalyticsQuery) (*models.KPIReport, error)
+
+	GetCleanerEarnings(ctx context.Context, cleanerID string) (*models.EarningsSummary, error)
+	GetStatement(ctx context.Context, id primitive.ObjectID, userID, role string) (*models.StatementDetails, error)
+	ListStatements(ctx context.Context, cleanerID string, status models.StatementStatus) ([]models.PayoutStatement, error)
+	GenerateStatements(ctx context.Context, periodEnd time.Time) ([]models.PayoutStatement, error)
+	MarkStatementPaid(ctx context.Context, id primitive.ObjectID, adminID, paymentRef string) (*models.PayoutStatement, error)
+
+	TipOrder(ctx context.Context, orderID primitive.ObjectID, clientID string, amount float64, message, authHeader string) (*models.Tip, error)
+	UpdateTipPaymentStatus(ctx context.Context, tipID string, status string) error
+	GetTip(ctx context.Context, id primitive.ObjectID, userID, role string) (*models.Tip, error)
+	GetOrderTips(ctx context.Context, orderID primitive.ObjectID, userID, role string) ([]models.Tip, error)
 }
 
 // NewOrderHandler создаёт новый хендлер для заказов и получает конфиг
@@ -51,25 +121,82 @@ func NewOrderHandler(service OrderService, rdb *redis.Client, cfg *config.Config
 	return &OrderHandler{service: service, rdb: rdb, cfg: cfg}
 }
 
-// POST /orders/:id/review
-func (h *OrderHandler) AddOrderReview(c *gin.Context) {
-	orderID := c.Param("id")
-	var req struct {
-		Rating  int    `json:"rating" binding:"required,min=1,max=5"`
-		Comment string `json:"comment"`
-	}
-	if err := c.ShouldBindJSON(&req); err != nil {
+// handleServiceError переводит ошибки сервиса в HTTP-ответ.
+func handleServiceError(c *gin.Context, err error) {
+	var transitionErr *models.TransitionError
+	var checklistErr *models.ChecklistIncompleteError
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+	case errors.Is(err, models.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrValidation):
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrQuoteExpired):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrNoRescheduleOpen), errors.Is(err, models.ErrChecklistItemNotFound),
+		errors.Is(err, models.ErrNoOpenDispute), errors.Is(err, models.ErrAddressNotFound),
+		errors.Is(err, models.ErrZoneNotFound), errors.Is(err, models.ErrReviewNotFound),
+		errors.Is(err, models.ErrStatementNotFound), errors.Is(err, models.ErrTipNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrTipPaymentFailed):
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrRefundPending):
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrOutsideServiceArea):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrRescheduleRequired), errors.Is(err, models.ErrRescheduleOpen),
+		errors.Is(err, models.ErrRequoteRequired), errors.Is(err, models.ErrPromoExhausted),
+		errors.Is(err, models.ErrAlreadyCheckedIn), errors.Is(err, models.ErrNotCheckedIn),
+		errors.Is(err, models.ErrDisputeExists), errors.Is(err, models.ErrDisputeWindowClosed),
+		errors.Is(err, models.ErrVersionConflict), errors.Is(err, models.ErrAlreadyReviewed),
+		errors.Is(err, models.ErrOrderNotReviewable), errors.Is(err, models.ErrStatementPaid),
+		errors.Is(err, models.ErrStatementsConflict), errors.Is(err, models.ErrOrderNotTippable),
+		errors.Is(err, models.ErrOrderInDispute), errors.Is(err, models.ErrFirstOrderPromoUsed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &checklistErr):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "pending_items": checklistErr.Pending})
+	case errors.As(err, &transitionErr):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "from": transitionErr.From, "to": transitionErr.To})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// respondAssignError отвечает 409 на ошибки назначения; при конфликте расписания
+// дополнительно называет пересекающийся заказ.
+func respondAssignError(c *gin.Context, err error) {
+	var busyErr *models.CleanerBusyError
+	if errors.As(err, &busyErr) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":             err.Error(),
+			"cleaner_id":        busyErr.CleanerID,
+			"conflict_order_id": busyErr.ConflictOrderID,
+		})
 		return
 	}
+	c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+}
 
-	authHeader := c.GetHeader("Authorization")
+// isAssignConflict — клинер занят другим заказом или интервал вне его графика.
+func isAssignConflict(err error) bool {
+	var busyErr *models.CleanerBusyError
+	return errors.As(err, &busyErr) || errors.Is(err, models.ErrOutsideWorkingHours)
+}
 
-	if err := h.service.AddReview(c.Request.Context(), orderID, req.Rating, req.Comment, authHeader); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+// GET /orders/:id/history
+func (h *OrderHandler) GetStatusHistory(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
 		return
 	}
-	c.Status(http.StatusCreated)
+	history, err := h.service.GetStatusHistory(c.Request.Context(), id, c.GetString("userId"), c.GetString("role"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, history)
 }
 
 func (h *OrderHandler) GetOrderByIDHTTP(c *gin.Context) {
@@ -94,47 +221,33 @@ func (h *OrderHandler) GetOrderByIDHTTP(c *gin.Context) {
 
 func (h *OrderHandler) HandlePaymentNotification(c *gin.Context) {
 	var note struct {
-		EntityID string `json:"entity_id"`
-		Status   string `json:"status"`
+		EntityType string `json:"entity_type"` // пусто — заказ (старые уведомления)
+		EntityID   string `json:"entity_id"`
+		Status     string `json:"status"`
 	}
 	if err := c.ShouldBindJSON(&note); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
 		return
 	}
-	if err := h.service.UpdatePaymentStatus(c.Request.Context(), note.EntityID, note.Status); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if note.EntityType == "tip" {
+		if err := h.service.UpdateTipPaymentStatus(c.Request.Context(), note.EntityID, note.Status); err != nil {
+			handleServiceError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
 		return
 	}
-
-	// ── Уведомление о статусе платежа ──
-	orderIDHex := note.EntityID
-	// Преобразуем строку в ObjectID
-	orderID, errID := primitive.ObjectIDFromHex(orderIDHex)
-	if errID == nil {
-		// Достаём order, чтобы узнать clientID и сумму, если нужно
-		order, err := h.service.GetOrderByID(c.Request.Context(), orderID)
-		if err == nil {
-			clientID := order.ClientID
-			evtType := "payment_failed"
-			if note.Status == "success" {
-				evtType = "payment_successful"
-			}
-			go func() {
-				_ = utils.SendNotificationEvent(context.Background(), utils.NotificationEvent{
-					UserID:    clientID,
-					Role:      "client",
-					Type:      evtType,
-					ExtraData: map[string]string{"order_id": orderIDHex, "amount": fmt.Sprintf("%.2f", order.TotalPrice)},
-				})
-			}()
-		}
+	// Уведомление клиенту о результате платежа ставится в outbox сервисом.
+	if err := h.service.UpdatePaymentStatus(c.Request.Context(), note.EntityID, note.Status); err != nil {
+		handleServiceError(c, err)
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "ok"})
 }
 
 func (h *OrderHandler) clearCache(ctx context.Context) {
-	h.rdb.Del(ctx, "orders:activeCount", "orders:totalRevenue", "orders:all")
+	h.rdb.Del(ctx, "orders:activeCount", "orders:totalRevenue")
 }
 
 // CreateOrder остаётся без изменений по логике, но вызываем SendNotification с новым контекстом
@@ -147,7 +260,7 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	}
 	order.ClientID = userID
 	if err := h.service.CreateOrder(c.Request.Context(), &order); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handleServiceError(c, err)
 		return
 	}
 	h.clearCache(c.Request.Context())
@@ -177,136 +290,111 @@ func (h *OrderHandler) UpdateOrder(c *gin.Context) {
 		return
 	}
 
-	// Предполагаем, что поле Status приходит в JSON как orderUpdate.Status
-	oldOrder, _ := h.service.GetOrderByID(c.Request.Context(), id)
-	oldStatus := ""
-	if oldOrder != nil {
-		oldStatus = string(oldOrder.Status)
-	}
-
+	// version (опц.) — версия заказа, которую видел клиент; при расхождении ответ 409.
+	// Клиент и назначенные клинеры получают order_updated через outbox.
 	if err := h.service.UpdateOrder(c.Request.Context(), id, &orderUpdate); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handleServiceError(c, err)
 		return
 	}
 	h.clearCache(c.Request.Context())
 
-	// ── Если статус переключился на "confirmed" ──
-	if orderUpdate.Status == "confirmed" && oldStatus != "confirmed" {
-		// Получим clientID из сохранённого заказа (до обновления) или из orderUpdate.ClientID
-		clientID := orderUpdate.ClientID
-		if clientID == "" && oldOrder != nil {
-			clientID = oldOrder.ClientID
-		}
-		go func() {
-			_ = utils.SendNotificationEvent(context.Background(), utils.NotificationEvent{
-				UserID:    clientID,
-				Role:      "client",
-				Type:      "order_confirmed",
-				ExtraData: map[string]string{"order_id": id.Hex()},
-			})
-		}()
-	}
-
-	// ── Если статус переключился на "cancelled" ──
-	if orderUpdate.Status == "cancelled" && oldStatus != "cancelled" {
-		clientID := orderUpdate.ClientID
-		if clientID == "" && oldOrder != nil {
-			clientID = oldOrder.ClientID
-		}
-		go func() {
-			_ = utils.SendNotificationEvent(context.Background(), utils.NotificationEvent{
-				UserID:    clientID,
-				Role:      "client",
-				Type:      "order_cancelled",
-				ExtraData: map[string]string{"order_id": id.Hex()},
-			})
-		}()
-	}
-
-	// ── Во всех остальных случаях (любое обновление полей) ──
-	//    отправляем "order_updated" клиенту и всем назначенным cleaner-ам
-	clientID := orderUpdate.ClientID
-	if clientID == "" && oldOrder != nil {
-		clientID = oldOrder.ClientID
-	}
-	// Получаем список cleanerIDs из базы (предполагается, что orderUpdate.CleanerID хранит массив или single string)
-	var cleanerIDs []string
-	if len(orderUpdate.CleanerID) > 0 {
-		cleanerIDs = orderUpdate.CleanerID
-	} else if oldOrder != nil {
-		cleanerIDs = oldOrder.CleanerID
-	}
-
-	// Отправляем уведомление клиенту
-	go func() {
-		_ = utils.SendNotificationEvent(context.Background(), utils.NotificationEvent{
-			UserID:    clientID,
-			Role:      "client",
-			Type:      "order_updated",
-			ExtraData: map[string]string{"order_id": id.Hex()},
-		})
-	}()
+	c.JSON(http.StatusOK, gin.H{"message": "Order updated"})
+}
 
-	// И каждому назначенному cleaner-у
-	for _, cid := range cleanerIDs {
-		go func(cleanerID string) {
-			_ = utils.SendNotificationEvent(context.Background(), utils.NotificationEvent{
-				UserID:    cleanerID,
-				Role:      "cleaner",
-				Type:      "order_updated",
-				ExtraData: map[string]string{"order_id": id.Hex()},
-			})
-		}(cid)
+// GET /orders/:id/cancel/quote — сколько будет удержано при отмене сейчас
+func (h *OrderHandler) QuoteCancellation(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
 	}
+	quote, err := h.service.QuoteCancellation(c.Request.Context(), id, c.GetString("userId"), c.GetString("role"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, quote)
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Order updated"})
+// POST /orders/quote — предварительный расчёт цены с quote_id для CreateOrder
+func (h *OrderHandler) QuoteOrder(c *gin.Context) {
+	var req models.QuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	quote, err := h.service.QuoteOrder(c.Request.Context(), c.GetString("userId"), &req)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, quote)
 }
 
-func (h *OrderHandler) DeleteOrder(c *gin.Context) {
+// POST /orders/:id/requote — body (опц.): { "service_ids": ["..."] }
+func (h *OrderHandler) RequoteOrder(c *gin.Context) {
 	id, err := primitive.ObjectIDFromHex(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
 		return
 	}
-	// Сначала вытащим старый заказ, чтобы знать clientID и cleanerIDs
-	oldOrder, err := h.service.GetOrderByID(c.Request.Context(), id)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+	var body struct {
+		ServiceIDs []string `json:"service_ids"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+
+	order, err := h.service.RequoteOrder(c.Request.Context(), id, c.GetString("userId"), c.GetString("role"), body.ServiceIDs)
+	if err != nil {
+		handleServiceError(c, err)
 		return
 	}
+	h.clearCache(c.Request.Context())
+	c.JSON(http.StatusOK, order)
+}
 
-	if err := h.service.DeleteOrder(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+// POST /orders/:id/cancel — body: { "reason": "..." }
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+	}
+
+	order, err := h.service.CancelOrder(c.Request.Context(), id, c.GetString("userId"), c.GetString("role"), body.Reason, c.GetHeader("Authorization"))
+	if err != nil {
+		handleServiceError(c, err)
 		return
 	}
 	h.clearCache(c.Request.Context())
 
-	// ── Уведомляем клиента ──
-	clientID := oldOrder.ClientID
-	go func() {
-		_ = utils.SendNotificationEvent(context.Background(), utils.NotificationEvent{
-			UserID:    clientID,
-			Role:      "client",
-			Type:      "order_deleted",
-			ExtraData: map[string]string{"order_id": id.Hex()},
-		})
-	}()
+	c.JSON(http.StatusOK, gin.H{"message": "Order cancelled", "cancellation": order.Cancellation})
+}
 
-	// ── Уведомляем всех назначенных cleaner-ов ──
-	for _, cid := range oldOrder.CleanerID {
-		go func(cleanerID string) {
-			_ = utils.SendNotificationEvent(context.Background(), utils.NotificationEvent{
-				UserID:    cleanerID,
-				Role:      "cleaner",
-				Type:      "order_deleted",
-				ExtraData: map[string]string{"order_id": id.Hex()},
-			})
-		}(cid)
+func (h *OrderHandler) DeleteOrder(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
 	}
+	if err := h.service.DeleteOrder(c.Request.Context(), id); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	h.clearCache(c.Request.Context())
 
 	c.JSON(http.StatusOK, gin.H{"message": "Order deleted"})
 }
@@ -327,20 +415,10 @@ func (h *OrderHandler) AssignCleaners(c *gin.Context) {
 	}
 
 	if err := h.service.AssignCleaners(c.Request.Context(), id, body.CleanerIDs); err != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		respondAssignError(c, err)
 		return
 	}
 	h.clearCache(c.Request.Context())
-	for _, cleanerID := range body.CleanerIDs {
-		go func(cid string) {
-			_ = utils.SendNotificationEvent(context.Background(), utils.NotificationEvent{
-				UserID:    cid,
-				Role:      "cleaner",
-				Type:      "assigned_order",
-				ExtraData: map[string]string{"order_id": id.Hex()},
-			})
-		}(cleanerID)
-	}
 	c.JSON(http.StatusOK, gin.H{"message": "Cleaners assigned"})
 }
 
@@ -359,18 +437,10 @@ func (h *OrderHandler) AssignCleaner(c *gin.Context) {
 		return
 	}
 	if err := h.service.AssignCleaners(c.Request.Context(), id, []string{body.CleanerID}); err != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		respondAssignError(c, err)
 		return
 	}
 	h.clearCache(c.Request.Context())
-	go func() {
-		_ = utils.SendNotificationEvent(context.Background(), utils.NotificationEvent{
-			UserID:    body.CleanerID,
-			Role:      "cleaner",
-			Type:      "assigned_order",
-			ExtraData: map[string]string{"order_id": id.Hex()},
-		})
-	}()
 	c.JSON(http.StatusOK, gin.H{"message": "Cleaner assigned"})
 }
 
@@ -389,7 +459,7 @@ func (h *OrderHandler) UnassignCleaner(c *gin.Context) {
 		return
 	}
 	if err := h.service.UnassignCleaner(c.Request.Context(), id, body.CleanerID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handleServiceError(c, err)
 		return
 	}
 	h.clearCache(c.Request.Context())
@@ -397,7 +467,7 @@ func (h *OrderHandler) UnassignCleaner(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Cleaner unassigned"})
 }
 
-// ConfirmCompletion — получаем clientID из заказа и отправляем уведомление
+// ConfirmCompletion — завершение заказа; уведомление, запрос отзыва и XP уходят через outbox
 func (h *OrderHandler) ConfirmCompletion(c *gin.Context) {
 	id, err := primitive.ObjectIDFromHex(c.Param("id"))
 	if err != nil {
@@ -414,70 +484,87 @@ func (h *OrderHandler) ConfirmCompletion(c *gin.Context) {
 
 	// 1) Меняем статус заказа в БД (service.ConfirmCompletion → устанавливает статус "completed")
 	if err := h.service.ConfirmCompletion(c.Request.Context(), id, body.PhotoURL); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handleServiceError(c, err)
 		return
 	}
 	h.clearCache(c.Request.Context())
 
-	// 2) Достаём заказ, чтобы узнать clientID
-	completedOrder, err := h.service.GetOrderByID(c.Request.Context(), id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	clientID := completedOrder.ClientID
-
-	// ── Уведомление: уборка завершена ──
-	go func() {
-		_ = utils.SendNotificationEvent(context.Background(), utils.NotificationEvent{
-			UserID:    clientID,
-			Role:      "client",
-			Type:      "cleaning_completed",
-			ExtraData: map[string]string{"order_id": id.Hex()},
-		})
-	}()
-
-	// ── Отложенный запрос отзыва через час ──
-	go func(orderID, uID string) {
-		time.AfterFunc(time.Hour, func() {
-			_ = utils.SendNotificationEvent(context.Background(), utils.NotificationEvent{
-				UserID:    uID,
-				Role:      "client",
-				Type:      "review_request",
-				ExtraData: map[string]string{"order_id": orderID},
-			})
-		})
-	}(id.Hex(), clientID)
-
 	c.JSON(http.StatusOK, gin.H{"message": "Order marked as completed"})
 }
 
+// GET /orders/all?sort=-created_at&limit=50&cursor=... — все заказы постранично
 func (h *OrderHandler) GetAllOrders(c *gin.Context) {
-	orders, err := h.service.GetAllOrders(c.Request.Context())
+	q, ok := parsePaging(c)
+	if !ok {
+		return
+	}
+	page, err := h.service.SearchOrders(c.Request.Context(), q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		handleServiceError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, orders)
+	c.JSON(http.StatusOK, page)
 }
 
+// GET /orders/filter — фильтры: status (через запятую), client_id, cleaner_id, service_id, zone_id,
+// date_from, date_to (RFC3339), price_min, price_max, q (поиск по адресу и комментарию);
+// плюс sort, limit, cursor как в /orders/all.
 func (h *OrderHandler) FilterOrders(c *gin.Context) {
-	filters := make(map[string]interface{})
-	if status := c.Query("status"); status != "" {
-		filters["status"] = status
+	q, ok := parsePaging(c)
+	if !ok {
+		return
 	}
-	if cleaner := c.Query("cleaner_id"); cleaner != "" {
-		filters["cleaner_id"] = cleaner
+	if v := c.Query("status"); v != "" {
+		for _, st := range strings.Split(v, ",") {
+			q.Statuses = append(q.Statuses, models.OrderStatus(strings.TrimSpace(st)))
+		}
+	}
+	q.ClientID = c.Query("client_id")
+	q.CleanerID = c.Query("cleaner_id")
+	q.ServiceID = c.Query("service_id")
+	q.ZoneID = c.Query("zone_id")
+	q.Text = strings.TrimSpace(c.Query("q"))
+	for param, dst := range map[string]**time.Time{"date_from": &q.DateFrom, "date_to": &q.DateTo} {
+		if v := c.Query(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid '" + param + "', expected RFC3339"})
+				return
+			}
+			*dst = &t
+		}
 	}
-	if client := c.Query("client_id"); client != "" {
-		filters["client_id"] = client
+	for param, dst := range map[string]**float64{"price_min": &q.PriceMin, "price_max": &q.PriceMax} {
+		if v := c.Query(param); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid '" + param + "'"})
+				return
+			}
+			*dst = &f
+		}
 	}
-	orders, err := h.service.FilterOrders(c.Request.Context(), filters)
+
+	page, err := h.service.SearchOrders(c.Request.Context(), q)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		handleServiceError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, orders)
+	c.JSON(http.StatusOK, page)
+}
+
+// parsePaging читает sort, limit и cursor.
+func parsePaging(c *gin.Context) (*models.OrderSearch, bool) {
+	q := &models.OrderSearch{Sort: c.Query("sort"), Cursor: c.Query("cursor")}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'limit'"})
+			return nil, false
+		}
+		q.Limit = limit
+	}
+	return q, true
 }
 
 func (h *OrderHandler) GetActiveOrdersCount(c *gin.Context) {
@@ -498,6 +585,11 @@ func (h *OrderHandler) GetTotalRevenue(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"revenue": revenue})
 }
 
+// GetCacheStats отдаёт счётчики попаданий/промахов кэша заказов этого экземпляра.
+func (h *OrderHandler) GetCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"families": h.service.CacheStats()})
+}
+
 func (h *OrderHandler) GetCleanerOrders(c *gin.Context) {
 	// 1) Получаем userId из JWT (middleware кладет в context)
 	cleanerHex := c.GetString("userId")
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"cleaning-app/order-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// respondPromoError переводит ошибки промокодов в HTTP-ответ.
+func respondPromoError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrPromoNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrPromoDuplicate):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrValidation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// POST /promo-codes
+func (h *OrderHandler) CreatePromo(c *gin.Context) {
+	var code models.PromoCode
+	if err := c.ShouldBindJSON(&code); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if err := h.service.CreatePromo(c.Request.Context(), &code); err != nil {
+		respondPromoError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, code)
+}
+
+// GET /promo-codes
+func (h *OrderHandler) ListPromos(c *gin.Context) {
+	codes, err := h.service.ListPromos(c.Request.Context())
+	if err != nil {
+		respondPromoError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, codes)
+}
+
+// GET /promo-codes/:id
+func (h *OrderHandler) GetPromo(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	code, err := h.service.GetPromo(c.Request.Context(), id)
+	if err != nil {
+		respondPromoError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, code)
+}
+
+// PUT /promo-codes/:id
+func (h *OrderHandler) UpdatePromo(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	var code models.PromoCode
+	if err := c.ShouldBindJSON(&code); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if err := h.service.UpdatePromo(c.Request.Context(), id, &code); err != nil {
+		respondPromoError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "promo code updated"})
+}
+
+// DELETE /promo-codes/:id
+func (h *OrderHandler) DeletePromo(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	if err := h.service.DeletePromo(c.Request.Context(), id); err != nil {
+		respondPromoError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "promo code deleted"})
+}
+
+// GET /promo-codes/stats — по всем кодам
+func (h *OrderHandler) GetPromoStats(c *gin.Context) {
+	stats, err := h.service.PromoStats(c.Request.Context(), nil)
+	if err != nil {
+		respondPromoError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GET /promo-codes/:id/stats
+func (h *OrderHandler) GetPromoCodeStats(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	stats, err := h.service.PromoStats(c.Request.Context(), &id)
+	if err != nil {
+		respondPromoError(c, err)
+		return
+	}
+	if len(stats) == 0 {
+		c.JSON(http.StatusOK, gin.H{"code_id": id, "redemptions": 0, "unique_users": 0, "total_discount": 0})
+		return
+	}
+	c.JSON(http.StatusOK, stats[0])
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GET /orders/:id/reschedule — последний запрос на перенос и ответы клинеров
+func (h *OrderHandler) GetReschedule(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	req, err := h.service.GetReschedule(c.Request.Context(), id, c.GetString("userId"), c.GetString("role"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+// POST /orders/:id/reschedule — body: { "slots": ["2025-06-01T10:00:00Z", ...], "reason": "..." }
+func (h *OrderHandler) RequestReschedule(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	var body struct {
+		Slots  []time.Time `json:"slots" binding:"required"`
+		Reason string      `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	req, err := h.service.RequestReschedule(c.Request.Context(), id, c.GetString("userId"), body.Slots, body.Reason)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	h.clearCache(c.Request.Context())
+	c.JSON(http.StatusCreated, req)
+}
+
+// POST /orders/:id/reschedule/respond — body: { "accept": true, "slot": "2025-06-01T10:00:00Z", "reason": "..." }
+func (h *OrderHandler) RespondReschedule(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	var body struct {
+		Accept bool       `json:"accept"`
+		Slot   *time.Time `json:"slot"`
+		Reason string     `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	req, err := h.service.RespondReschedule(c.Request.Context(), id, c.GetString("userId"), body.Accept, body.Slot, body.Reason)
+	if err != nil {
+		// Конфликт с графиком или другим заказом клинера — 409 с деталями.
+		if isAssignConflict(err) {
+			respondAssignError(c, err)
+			return
+		}
+		handleServiceError(c, err)
+		return
+	}
+	h.clearCache(c.Request.Context())
+	c.JSON(http.StatusOK, req)
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"cleaning-app/order-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// POST /orders/:id/review — body: { "reviews": [{ "cleaner_id": "...",
+// "ratings": { "quality": 5, "punctuality": 4, "politeness": 5 }, "comment": "..." }] }.
+// Старый формат { "rating": 5, "comment": "..." } ставит одинаковую оценку всем клинерам заказа.
+func (h *OrderHandler) AddOrderReview(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+	var body struct {
+		Reviews []models.ReviewInput `json:"reviews"`
+		Rating  int                  `json:"rating"`
+		Comment string               `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(body.Reviews) == 0 && body.Rating != 0 {
+		r := body.Rating
+		body.Reviews = []models.ReviewInput{{
+			Ratings: models.ReviewRatings{Quality: r, Punctuality: r, Politeness: r},
+			Comment: body.Comment,
+		}}
+	}
+
+	reviews, err := h.service.AddReviews(c.Request.Context(), id, c.GetString("userId"), body.Reviews)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, reviews)
+}
+
+// GET /orders/:id/reviews
+func (h *OrderHandler) GetOrderReviews(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+	reviews, err := h.service.GetOrderReviews(c.Request.Context(), id, c.GetString("userId"), c.GetString("role"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reviews)
+}
+
+// GET /cleaners/:id/reviews?limit=20&cursor=... — рейтинг клинера и его отзывы, новые первыми.
+func (h *OrderHandler) GetCleanerReviews(c *gin.Context) {
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'limit'"})
+			return
+		}
+		limit = n
+	}
+	page, err := h.service.GetCleanerReviews(c.Request.Context(), c.Param("id"), c.GetString("role"), c.Query("cursor"), limit)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// PUT /reviews/:id/reply — body: { "text": "..." }; отвечает клинер, о котором отзыв.
+func (h *OrderHandler) ReplyToReview(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review ID"})
+		return
+	}
+	var body struct {
+		Text string `json:"text" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	review, err := h.service.ReplyToReview(c.Request.Context(), id, c.GetString("userId"), body.Text)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// PUT /reviews/:id/moderation — body: { "hidden": true, "flagged": false, "note": "..." }
+func (h *OrderHandler) ModerateReview(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review ID"})
+		return
+	}
+	var body models.ReviewModerationInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	review, err := h.service.ModerateReview(c.Request.Context(), id, c.GetString("userId"), body)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
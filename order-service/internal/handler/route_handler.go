@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /cleaner/route?date=2025-06-02[&tz=Asia/Almaty] — маршрут текущего клинера на день
+func (h *OrderHandler) GetMyRoute(c *gin.Context) {
+	route, err := h.service.GetCleanerRoute(c.Request.Context(), c.GetString("userId"), c.Query("date"), c.Query("tz"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, route)
+}
+
+// GET /cleaners/:id/route?date=2025-06-02[&tz=Asia/Almaty] — маршрут клинера для менеджера
+func (h *OrderHandler) GetCleanerRoute(c *gin.Context) {
+	route, err := h.service.GetCleanerRoute(c.Request.Context(), c.Param("id"), c.Query("date"), c.Query("tz"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, route)
+}
+
+// GET /cleaners/routes?date=2025-06-02[&tz=Asia/Almaty] — маршруты всех клинеров, недостижимые — первыми
+func (h *OrderHandler) GetRoutes(c *gin.Context) {
+	routes, err := h.service.GetRoutes(c.Request.Context(), c.Query("date"), c.Query("tz"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, routes)
+}
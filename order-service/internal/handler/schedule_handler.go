@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /cleaners/me/schedule
+func (h *OrderHandler) GetMySchedule(c *gin.Context) {
+	h.getSchedule(c, c.GetString("userId"))
+}
+
+// PUT /cleaners/me/schedule
+func (h *OrderHandler) UpdateMySchedule(c *gin.Context) {
+	h.saveSchedule(c, c.GetString("userId"))
+}
+
+// GET /cleaners/:id/schedule
+func (h *OrderHandler) GetCleanerSchedule(c *gin.Context) {
+	h.getSchedule(c, c.Param("id"))
+}
+
+// PUT /cleaners/:id/schedule
+func (h *OrderHandler) UpdateCleanerSchedule(c *gin.Context) {
+	h.saveSchedule(c, c.Param("id"))
+}
+
+func (h *OrderHandler) getSchedule(c *gin.Context, cleanerID string) {
+	schedule, err := h.service.GetSchedule(c.Request.Context(), cleanerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *OrderHandler) saveSchedule(c *gin.Context, cleanerID string) {
+	var schedule models.CleanerSchedule
+	if err := c.ShouldBindJSON(&schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if err := h.service.SaveSchedule(c.Request.Context(), cleanerID, &schedule); err != nil {
+		if errors.Is(err, models.ErrValidation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+// GET /cleaners/availability?from=RFC3339&to=RFC3339&duration=минуты[&cleaner_ids=a,b]
+func (h *OrderHandler) GetCleanersAvailability(c *gin.Context) {
+	from, err := time.Parse(time.RFC3339, c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'from', expected RFC3339"})
+		return
+	}
+	to, err := time.Parse(time.RFC3339, c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'to', expected RFC3339"})
+		return
+	}
+	var duration time.Duration
+	if v := c.Query("duration"); v != "" {
+		minutes, err := strconv.Atoi(v)
+		if err != nil || minutes <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'duration', expected minutes"})
+			return
+		}
+		duration = time.Duration(minutes) * time.Minute
+	}
+	var cleanerIDs []string
+	if v := c.Query("cleaner_ids"); v != "" {
+		cleanerIDs = strings.Split(v, ",")
+	}
+
+	result, err := h.service.GetAvailability(c.Request.Context(), from, to, duration, cleanerIDs)
+	if err != nil {
+		if errors.Is(err, models.ErrValidation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
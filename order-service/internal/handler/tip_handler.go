@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// POST /orders/:id/tip — body: { "amount": 500, "message": "..." }; сумма делится
+// поровну между клинерами заказа.
+func (h *OrderHandler) TipOrder(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+	var body struct {
+		Amount  float64 `json:"amount" binding:"required"`
+		Message string  `json:"message"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	tip, err := h.service.TipOrder(c.Request.Context(), id, c.GetString("userId"), body.Amount, body.Message, c.GetHeader("Authorization"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, tip)
+}
+
+// GET /orders/:id/tips
+func (h *OrderHandler) GetOrderTips(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+	tips, err := h.service.GetOrderTips(c.Request.Context(), id, c.GetString("userId"), c.GetString("role"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tips)
+}
+
+// GET /tips/:id — в том числе для проверки суммы в payment-service.
+func (h *OrderHandler) GetTip(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tip ID"})
+		return
+	}
+	tip, err := h.service.GetTip(c.Request.Context(), id, c.GetString("userId"), c.GetString("role"))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tip)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"cleaning-app/order-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// POST /zones — body: { "name": "...", "area": { "type": "Polygon", "coordinates": [[[lng, lat], ...]] },
+// "active": true, "min_notice_hours": 4, "price_multiplier": 1.2, "cleaner_ids": [...] }
+func (h *OrderHandler) CreateZone(c *gin.Context) {
+	var zone models.Zone
+	if err := c.ShouldBindJSON(&zone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if err := h.service.CreateZone(c.Request.Context(), &zone); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, zone)
+}
+
+// GET /zones
+func (h *OrderHandler) ListZones(c *gin.Context) {
+	zones, err := h.service.ListZones(c.Request.Context())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, zones)
+}
+
+// GET /zones/:id
+func (h *OrderHandler) GetZone(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	zone, err := h.service.GetZone(c.Request.Context(), id)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, zone)
+}
+
+// PUT /zones/:id — тело как при создании
+func (h *OrderHandler) UpdateZone(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	var zone models.Zone
+	if err := c.ShouldBindJSON(&zone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	if err := h.service.UpdateZone(c.Request.Context(), id, &zone); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, zone)
+}
+
+// DELETE /zones/:id
+func (h *OrderHandler) DeleteZone(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	if err := h.service.DeleteZone(c.Request.Context(), id); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /zones/lookup?lat=..&lng=.. — обслуживается ли адрес; 422, если он вне зон.
+func (h *OrderHandler) LookupZone(c *gin.Context) {
+	lat, err1 := strconv.ParseFloat(c.Query("lat"), 64)
+	lng, err2 := strconv.ParseFloat(c.Query("lng"), 64)
+	loc := models.GeoPoint{Lat: lat, Lng: lng}
+	if err1 != nil || err2 != nil || !loc.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "valid lat and lng are required"})
+		return
+	}
+	zone, err := h.service.LookupZone(c.Request.Context(), loc)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+	if zone == nil {
+		c.JSON(http.StatusOK, gin.H{"served": true})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"served": true, "zone_id": zone.ID.Hex(), "zone": zone.Name, "min_notice_hours": zone.MinNoticeHours})
+}
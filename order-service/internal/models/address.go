@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrAddressNotFound = errors.New("address not found")
+
+// AddressSnapshot — структурированный адрес. Копия сохраняется в заказе и подписке,
+// чтобы последующие правки сохранённого адреса не меняли уже созданные заказы.
+type AddressSnapshot struct {
+	City         string    `bson:"city" json:"city"`
+	Street       string    `bson:"street" json:"street"`
+	Building     string    `bson:"building" json:"building"`
+	Apartment    string    `bson:"apartment,omitempty" json:"apartment,omitempty"`
+	Floor        string    `bson:"floor,omitempty" json:"floor,omitempty"`
+	EntranceCode string    `bson:"entrance_code,omitempty" json:"entrance_code,omitempty"`
+	Location     *GeoPoint `bson:"location,omitempty" json:"location,omitempty"`
+	Notes        string    `bson:"notes,omitempty" json:"notes,omitempty"` // как пройти, домофон, парковка
+}
+
+// Address — сохранённый адрес клиента (коллекция addresses).
+type Address struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ClientID        string             `bson:"client_id" json:"client_id"`
+	Label           string             `bson:"label,omitempty" json:"label,omitempty"` // "Дом", "Офис"
+	AddressSnapshot `bson:",inline"`
+	CreatedAt       time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time  `bson:"updated_at" json:"updated_at"`
+	DeletedAt       *time.Time `bson:"deleted_at,omitempty" json:"-"` // удалённые скрываются и не подставляются в новые заказы
+}
+
+func (a *AddressSnapshot) Validate() error {
+	if strings.TrimSpace(a.City) == "" || strings.TrimSpace(a.Street) == "" || strings.TrimSpace(a.Building) == "" {
+		return fmt.Errorf("%w: city, street and building are required", ErrValidation)
+	}
+	if a.Location != nil && !a.Location.Valid() {
+		return fmt.Errorf("%w: invalid lat/lng", ErrValidation)
+	}
+	return nil
+}
+
+// Line — адрес одной строкой для Order.Address и уведомлений.
+func (a *AddressSnapshot) Line() string {
+	parts := []string{a.City, a.Street + " " + a.Building}
+	if a.Apartment != "" {
+		parts = append(parts, "кв. "+a.Apartment)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Snapshot возвращает копию адреса для сохранения в заказе.
+func (a *Address) Snapshot() *AddressSnapshot {
+	snap := a.AddressSnapshot
+	if a.Location != nil {
+		loc := *a.Location
+		snap.Location = &loc
+	}
+	return &snap
+}
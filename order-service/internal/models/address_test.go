@@ -0,0 +1,37 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddressValidate(t *testing.T) {
+	a := AddressSnapshot{City: "Almaty", Street: "Abay", Building: "10"}
+	if err := a.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	a.Location = &GeoPoint{Lat: 120, Lng: 76.9}
+	if err := a.Validate(); !errors.Is(err, ErrValidation) {
+		t.Errorf("invalid lat error = %v, want ErrValidation", err)
+	}
+	if err := (&AddressSnapshot{City: "Almaty"}).Validate(); !errors.Is(err, ErrValidation) {
+		t.Errorf("missing street error = %v, want ErrValidation", err)
+	}
+}
+
+func TestAddressSnapshot_IsCopy(t *testing.T) {
+	addr := &Address{AddressSnapshot: AddressSnapshot{
+		City: "Almaty", Street: "Abay", Building: "10", Apartment: "5",
+		Location: &GeoPoint{Lat: 43.24, Lng: 76.91},
+	}}
+	snap := addr.Snapshot()
+	if got := snap.Line(); got != "Almaty, Abay 10, кв. 5" {
+		t.Errorf("Line = %q", got)
+	}
+
+	addr.Street = "Dostyk"
+	addr.Location.Lat = 43.0
+	if snap.Street != "Abay" || snap.Location.Lat != 43.24 {
+		t.Errorf("snapshot changed with the saved address: %+v", snap)
+	}
+}
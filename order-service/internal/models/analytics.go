@@ -0,0 +1,143 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// AnalyticsGroupBy — разрез отчёта по выручке.
+type AnalyticsGroupBy string
+
+const (
+	GroupByDay     AnalyticsGroupBy = "day"
+	GroupByWeek    AnalyticsGroupBy = "week" // ISO-неделя: 2025-W07
+	GroupByMonth   AnalyticsGroupBy = "month"
+	GroupByService AnalyticsGroupBy = "service"
+	GroupByCleaner AnalyticsGroupBy = "cleaner"
+	GroupByZone    AnalyticsGroupBy = "zone"
+)
+
+// MaxAnalyticsRange — наибольший период одного отчёта.
+const MaxAnalyticsRange = 366 * 24 * time.Hour
+
+// RevenueStatuses — статусы оплаченных заказов, которые входят в выручку.
+var RevenueStatuses = []OrderStatus{
+	StatusPaid, StatusPrePaid, StatusAssigned, StatusInProgress, StatusCompleted, StatusDisputed,
+}
+
+// AnalyticsQuery — период [From, To) по дате заказа, разрез и часовой пояс для
+// разбиения по дням/неделям/месяцам.
+type AnalyticsQuery struct {
+	From     time.Time
+	To       time.Time
+	GroupBy  AnalyticsGroupBy
+	Timezone string
+}
+
+// Validate проверяет период и разрез; пустой разрез — по дням. Пояс по умолчанию
+// (SERVICE_TIMEZONE) подставляет обработчик, пустой здесь означает UTC.
+func (q *AnalyticsQuery) Validate() error {
+	if q.From.IsZero() || q.To.IsZero() {
+		return fmt.Errorf("%w: from and to are required", ErrValidation)
+	}
+	if !q.From.Before(q.To) {
+		return fmt.Errorf("%w: to must be after from", ErrValidation)
+	}
+	if q.To.Sub(q.From) > MaxAnalyticsRange {
+		return fmt.Errorf("%w: period must not exceed %d days", ErrValidation, int(MaxAnalyticsRange.Hours()/24))
+	}
+	if q.GroupBy == "" {
+		q.GroupBy = GroupByDay
+	}
+	switch q.GroupBy {
+	case GroupByDay, GroupByWeek, GroupByMonth, GroupByService, GroupByCleaner, GroupByZone:
+	default:
+		return fmt.Errorf("%w: unsupported group_by %q", ErrValidation, q.GroupBy)
+	}
+	if q.Timezone == "" {
+		q.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(q.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrValidation, q.Timezone)
+	}
+	return nil
+}
+
+// IsTimeSeries — разбиение по времени (сортируется по ключу, а не по выручке).
+func (g AnalyticsGroupBy) IsTimeSeries() bool {
+	return g == GroupByDay || g == GroupByWeek || g == GroupByMonth
+}
+
+// RevenueBucket — выручка, число заказов и средний чек в одной группе.
+// Выручка заказа — total_price за вычетом возврата по спору, у отменённого — удержанный
+// штраф; у заказа с несколькими клинерами или услугами она делится между ними (поровну
+// или пропорционально позициям). Штрафы входят в Revenue, но не в Orders и средний чек.
+type RevenueBucket struct {
+	Key               string  `bson:"_id" json:"key"`
+	Label             string  `bson:"label,omitempty" json:"label,omitempty"`
+	Revenue           float64 `bson:"revenue" json:"revenue"`
+	CancellationFees  float64 `bson:"cancellation_fees" json:"cancellation_fees"`
+	Orders            int64   `bson:"orders" json:"orders"`
+	AverageOrderValue float64 `bson:"-" json:"average_order_value"`
+}
+
+// Finalize округляет выручку и считает средний чек по оплаченным заказам без штрафов.
+func (b *RevenueBucket) Finalize() {
+	b.Revenue = roundMoney(b.Revenue)
+	b.CancellationFees = roundMoney(b.CancellationFees)
+	b.AverageOrderValue = 0
+	if b.Orders > 0 {
+		b.AverageOrderValue = roundMoney((b.Revenue - b.CancellationFees) / float64(b.Orders))
+	}
+}
+
+// RevenueReport — ответ GET /analytics/revenue.
+type RevenueReport struct {
+	From     time.Time        `json:"from"`
+	To       time.Time        `json:"to"`
+	GroupBy  AnalyticsGroupBy `json:"group_by"`
+	Timezone string           `json:"timezone"`
+	Total    RevenueBucket    `json:"total"`
+	Buckets  []RevenueBucket  `json:"buckets"`
+}
+
+// KPIReport — ответ GET /analytics/kpi: сводные показатели за период.
+type KPIReport struct {
+	From              time.Time `json:"from"`
+	To                time.Time `json:"to"`
+	Revenue           float64   `json:"revenue"`           // включая штрафы за отмену
+	CancellationFees  float64   `json:"cancellation_fees"` // удержанные при отмене штрафы
+	Orders            int64     `json:"orders"`
+	AverageOrderValue float64   `json:"average_order_value"`
+
+	SubscriptionRevenue float64 `json:"subscription_revenue"`
+	SubscriptionShare   float64 `json:"subscription_share"` // доля выручки заказов из подписок, 0..1
+
+	TotalOrders      int64   `json:"total_orders"` // все заказы периода, включая неоплаченные и отменённые
+	CancelledOrders  int64   `json:"cancelled_orders"`
+	CancellationRate float64 `json:"cancellation_rate"`
+
+	Clients          int64   `json:"clients"`        // клиенты с оплаченными заказами в периоде
+	RepeatClients    int64   `json:"repeat_clients"` // из них — с двумя и более оплаченными заказами к концу периода
+	RepeatClientRate float64 `json:"repeat_client_rate"`
+}
+
+// Finalize считает средний чек и доли.
+func (r *KPIReport) Finalize() {
+	r.Revenue = roundMoney(r.Revenue)
+	r.CancellationFees = roundMoney(r.CancellationFees)
+	r.SubscriptionRevenue = roundMoney(r.SubscriptionRevenue)
+	r.AverageOrderValue = ratio(r.Revenue-r.CancellationFees, float64(r.Orders), 100)
+	r.SubscriptionShare = ratio(r.SubscriptionRevenue, r.Revenue, 10000)
+	r.CancellationRate = ratio(float64(r.CancelledOrders), float64(r.TotalOrders), 10000)
+	r.RepeatClientRate = ratio(float64(r.RepeatClients), float64(r.Clients), 10000)
+}
+
+// ratio делит a на b с округлением до 1/precision; при b == 0 — 0.
+func ratio(a, b, precision float64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return math.Round(a/b*precision) / precision
+}
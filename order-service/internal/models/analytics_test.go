@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAnalyticsQueryValidate(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	q := AnalyticsQuery{From: from, To: from.AddDate(0, 1, 0)}
+	if err := q.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if q.GroupBy != GroupByDay || q.Timezone != "UTC" {
+		t.Errorf("defaults = (%q, %q), want (day, UTC)", q.GroupBy, q.Timezone)
+	}
+
+	bad := []AnalyticsQuery{
+		{To: from},
+		{From: from, To: from},
+		{From: from, To: from.AddDate(2, 0, 0)},
+		{From: from, To: from.AddDate(0, 0, 7), GroupBy: "year"},
+		{From: from, To: from.AddDate(0, 0, 7), Timezone: "Mars/Olympus"},
+	}
+	for i, q := range bad {
+		if err := q.Validate(); !errors.Is(err, ErrValidation) {
+			t.Errorf("case %d: Validate = %v, want ErrValidation", i, err)
+		}
+	}
+}
+
+func TestReportFinalize(t *testing.T) {
+	b := RevenueBucket{Revenue: 100.005, Orders: 3}
+	b.Finalize()
+	if b.AverageOrderValue != 33.34 {
+		t.Errorf("average = %v, want 33.34", b.AverageOrderValue)
+	}
+
+	withFees := RevenueBucket{Revenue: 350, CancellationFees: 50, Orders: 2}
+	withFees.Finalize()
+	if withFees.AverageOrderValue != 150 {
+		t.Errorf("average with fees = %v, want 150", withFees.AverageOrderValue)
+	}
+
+	empty := RevenueBucket{}
+	empty.Finalize()
+	if empty.AverageOrderValue != 0 {
+		t.Errorf("average of empty bucket = %v, want 0", empty.AverageOrderValue)
+	}
+
+	r := KPIReport{
+		Revenue: 1000, Orders: 8, SubscriptionRevenue: 250,
+		TotalOrders: 12, CancelledOrders: 3,
+		Clients: 6, RepeatClients: 2,
+	}
+	r.Finalize()
+	if r.AverageOrderValue != 125 || r.SubscriptionShare != 0.25 || r.CancellationRate != 0.25 || r.RepeatClientRate != 0.3333 {
+		t.Errorf("unexpected KPI: %+v", r)
+	}
+
+	fees := KPIReport{Revenue: 1100, CancellationFees: 100, Orders: 8}
+	fees.Finalize()
+	if fees.AverageOrderValue != 125 {
+		t.Errorf("KPI average with fees = %v, want 125", fees.AverageOrderValue)
+	}
+}
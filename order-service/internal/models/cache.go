@@ -0,0 +1,9 @@
+package models
+
+// CacheStats — счётчики попаданий в кэш одного семейства ключей с момента запуска.
+type CacheStats struct {
+	Family  string  `json:"family"`
+	Hits    uint64  `json:"hits"`
+	Misses  uint64  `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// ErrRefundPending — заказ уже отменён, но payment-service не подтвердил возврат;
+// повторная отмена заказа повторяет запрос возврата.
+var ErrRefundPending = errors.New("order cancelled, but the refund is still pending: repeat the cancellation to retry it")
+
+// CancellationPolicy — правила отмены заказа клиентом:
+// бесплатно раньше чем за FreeHours до начала, внутри окна удерживается FeePercent,
+// после чекина клинера деньги не возвращаются. Сам клиент отменяет заказ только до
+// начала работ, а начатый — лишь при AllowInProgress.
+type CancellationPolicy struct {
+	FreeHours       int
+	FeePercent      float64
+	AllowInProgress bool
+}
+
+// ClientMayCancel сообщает, может ли клиент сам отменить заказ в статусе status.
+func (p CancellationPolicy) ClientMayCancel(status OrderStatus) bool {
+	switch status {
+	case StatusPending, StatusPaid, StatusPrePaid, StatusAssigned:
+		return true
+	case StatusInProgress:
+		return p.AllowInProgress
+	}
+	return false
+}
+
+// CancellationQuote — во что обойдётся отмена прямо сейчас.
+type CancellationQuote struct {
+	PaidAmount   float64 `json:"paid_amount"`
+	Fee          float64 `json:"fee"`
+	RefundAmount float64 `json:"refund_amount"`
+	Rule         string  `json:"rule"`
+}
+
+// Evaluate считает удержание и сумму возврата для заказа, начинающегося в start.
+func (p CancellationPolicy) Evaluate(paidAmount float64, start, now time.Time, checkedIn bool) CancellationQuote {
+	q := CancellationQuote{PaidAmount: paidAmount}
+	switch {
+	case checkedIn:
+		q.Fee, q.Rule = paidAmount, "cleaner_checked_in"
+	case start.Sub(now) > time.Duration(p.FreeHours)*time.Hour:
+		q.Rule = "free_cancellation"
+	default:
+		q.Fee, q.Rule = roundMoney(paidAmount*p.FeePercent/100), "late_cancellation_fee"
+	}
+	q.RefundAmount = roundMoney(paidAmount - q.Fee)
+	return q
+}
+
+// CancellationInfo сохраняется в заказе после отмены.
+type CancellationInfo struct {
+	CancelledBy   string    `bson:"cancelled_by" json:"cancelled_by"`
+	Role          string    `bson:"role" json:"role"`
+	Reason        string    `bson:"reason,omitempty" json:"reason,omitempty"`
+	Rule          string    `bson:"rule" json:"rule"`
+	Fee           float64   `bson:"fee" json:"fee"`
+	RefundAmount  float64   `bson:"refund_amount" json:"refund_amount"`
+	RefundID      string    `bson:"refund_id,omitempty" json:"refund_id,omitempty"`
+	RefundPending bool      `bson:"refund_pending,omitempty" json:"refund_pending,omitempty"` // возврат ещё не подтверждён payment-service
+	CancelledAt   time.Time `bson:"cancelled_at" json:"cancelled_at"`
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCancellationPolicyEvaluate(t *testing.T) {
+	policy := CancellationPolicy{FreeHours: 24, FeePercent: 50}
+	start := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		checkedIn bool
+		rule      string
+		fee       float64
+		refund    float64
+	}{
+		{"well before the window", start.Add(-72 * time.Hour), false, "free_cancellation", 0, 100},
+		{"just before the window", start.Add(-24*time.Hour - time.Second), false, "free_cancellation", 0, 100},
+		{"window boundary", start.Add(-24 * time.Hour), false, "late_cancellation_fee", 50, 50},
+		{"inside the window", start.Add(-time.Hour), false, "late_cancellation_fee", 50, 50},
+		{"after start", start.Add(time.Hour), false, "late_cancellation_fee", 50, 50},
+		{"cleaner checked in", start.Add(-48 * time.Hour), true, "cleaner_checked_in", 100, 0},
+	}
+	for _, tt := range tests {
+		q := policy.Evaluate(100, start, tt.now, tt.checkedIn)
+		if q.Rule != tt.rule || q.Fee != tt.fee || q.RefundAmount != tt.refund || q.PaidAmount != 100 {
+			t.Errorf("%s: got %+v, want rule=%s fee=%v refund=%v", tt.name, q, tt.rule, tt.fee, tt.refund)
+		}
+	}
+
+	// Неоплаченный заказ отменяется без удержания, копейки округляются.
+	if q := policy.Evaluate(0, start, start, false); q.Fee != 0 || q.RefundAmount != 0 {
+		t.Errorf("unpaid order: got %+v", q)
+	}
+	if q := (CancellationPolicy{FeePercent: 33}).Evaluate(10.01, start, start, false); q.Fee != 3.3 || q.RefundAmount != 6.71 {
+		t.Errorf("rounding: got %+v", q)
+	}
+}
+
+func TestClientMayCancel(t *testing.T) {
+	policy := CancellationPolicy{}
+	for _, status := range []OrderStatus{StatusPending, StatusPaid, StatusPrePaid, StatusAssigned} {
+		if !policy.ClientMayCancel(status) {
+			t.Errorf("client must be able to cancel %s order", status)
+		}
+	}
+	for _, status := range []OrderStatus{StatusInProgress, StatusCompleted, StatusDisputed, StatusCancelled, StatusNoShow} {
+		if policy.ClientMayCancel(status) {
+			t.Errorf("client must not be able to cancel %s order", status)
+		}
+	}
+	policy.AllowInProgress = true
+	if !policy.ClientMayCancel(StatusInProgress) {
+		t.Errorf("AllowInProgress must let client cancel an in-progress order")
+	}
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrChecklistItemNotFound = errors.New("checklist item not found")
+
+// ChecklistTask — пункт чек-листа услуги в каталоге cleaning-details-service.
+type ChecklistTask struct {
+	Title    string `json:"title"`
+	Required bool   `json:"required"`
+}
+
+type ChecklistStatus string
+
+const (
+	ChecklistPending ChecklistStatus = "pending"
+	ChecklistDone    ChecklistStatus = "done"
+	ChecklistSkipped ChecklistStatus = "skipped"
+)
+
+// ChecklistItem — копия пункта чек-листа в заказе с отметкой клинера.
+type ChecklistItem struct {
+	ID         string          `bson:"id" json:"id"`
+	ServiceID  string          `bson:"service_id" json:"service_id"`
+	Title      string          `bson:"title" json:"title"`
+	Required   bool            `bson:"required" json:"required"`
+	Status     ChecklistStatus `bson:"status" json:"status"`
+	SkipReason string          `bson:"skip_reason,omitempty" json:"skip_reason,omitempty"`
+	UpdatedBy  string          `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	UpdatedAt  *time.Time      `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+}
+
+// ChecklistIncompleteError — заказ нельзя завершить, пока обязательные пункты не отмечены.
+type ChecklistIncompleteError struct {
+	Pending []string // названия неотмеченных обязательных пунктов
+}
+
+func (e *ChecklistIncompleteError) Error() string {
+	return fmt.Sprintf("checklist incomplete: %s", strings.Join(e.Pending, ", "))
+}
+
+// BuildChecklist копирует чек-листы выбранных услуг в заказ.
+func BuildChecklist(services []Service) []ChecklistItem {
+	var items []ChecklistItem
+	for _, svc := range services {
+		for _, task := range svc.Checklist {
+			items = append(items, ChecklistItem{
+				ID:        primitive.NewObjectID().Hex(),
+				ServiceID: svc.ID,
+				Title:     task.Title,
+				Required:  task.Required,
+				Status:    ChecklistPending,
+			})
+		}
+	}
+	return items
+}
+
+// MarkChecklistItem отмечает пункт чек-листа. Пропуск требует причины.
+func (o *Order) MarkChecklistItem(itemID, cleanerID string, status ChecklistStatus, reason string, at time.Time) (*ChecklistItem, error) {
+	switch status {
+	case ChecklistDone, ChecklistPending:
+		reason = ""
+	case ChecklistSkipped:
+		if strings.TrimSpace(reason) == "" {
+			return nil, fmt.Errorf("%w: reason is required to skip an item", ErrValidation)
+		}
+	default:
+		return nil, fmt.Errorf("%w: status must be done, skipped or pending", ErrValidation)
+	}
+	for i := range o.Checklist {
+		item := &o.Checklist[i]
+		if item.ID != itemID {
+			continue
+		}
+		item.Status = status
+		item.SkipReason = reason
+		item.UpdatedBy = cleanerID
+		item.UpdatedAt = &at
+		return item, nil
+	}
+	return nil, ErrChecklistItemNotFound
+}
+
+// ChecklistComplete возвращает *ChecklistIncompleteError, если какой-то обязательный
+// пункт не выполнен и не пропущен с причиной.
+func (o *Order) ChecklistComplete() error {
+	var pending []string
+	for _, item := range o.Checklist {
+		if item.Required && item.Status != ChecklistDone && item.Status != ChecklistSkipped {
+			pending = append(pending, item.Title)
+		}
+	}
+	if len(pending) > 0 {
+		return &ChecklistIncompleteError{Pending: pending}
+	}
+	return nil
+}
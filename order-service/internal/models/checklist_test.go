@@ -0,0 +1,37 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChecklistComplete(t *testing.T) {
+	order := &Order{Checklist: BuildChecklist([]Service{{
+		ID: "svc",
+		Checklist: []ChecklistTask{
+			{Title: "Vacuum floors", Required: true},
+			{Title: "Clean oven interior", Required: true},
+			{Title: "Water plants"},
+		},
+	}})}
+
+	var incomplete *ChecklistIncompleteError
+	if err := order.ChecklistComplete(); !errors.As(err, &incomplete) || len(incomplete.Pending) != 2 {
+		t.Fatalf("ChecklistComplete = %v, want 2 pending items", err)
+	}
+
+	now := time.Now()
+	if _, err := order.MarkChecklistItem(order.Checklist[1].ID, "c1", ChecklistSkipped, "", now); !errors.Is(err, ErrValidation) {
+		t.Errorf("skip without reason error = %v, want ErrValidation", err)
+	}
+	if _, err := order.MarkChecklistItem(order.Checklist[0].ID, "c1", ChecklistDone, "", now); err != nil {
+		t.Fatalf("mark done: %v", err)
+	}
+	if _, err := order.MarkChecklistItem(order.Checklist[1].ID, "c1", ChecklistSkipped, "no oven", now); err != nil {
+		t.Fatalf("skip: %v", err)
+	}
+	if err := order.ChecklistComplete(); err != nil {
+		t.Errorf("ChecklistComplete = %v, want nil (optional items may stay pending)", err)
+	}
+}
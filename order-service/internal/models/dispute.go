@@ -0,0 +1,149 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrDisputeExists       = errors.New("order already has a dispute")
+	ErrDisputeWindowClosed = errors.New("dispute window for this order has closed")
+	ErrNoOpenDispute       = errors.New("order has no open dispute")
+	ErrOrderInDispute      = errors.New("order is under dispute: it can only be closed by resolving the dispute")
+)
+
+type DisputeCategory string
+
+const (
+	DisputeQuality      DisputeCategory = "quality"
+	DisputeDamage       DisputeCategory = "damage"
+	DisputeMissedItems  DisputeCategory = "missed_items"
+	DisputeNoShow       DisputeCategory = "no_show"
+	DisputeOtherProblem DisputeCategory = "other"
+)
+
+type DisputeStatus string
+
+const (
+	DisputeOpen      DisputeStatus = "open"
+	DisputeResolving DisputeStatus = "resolving" // решение с возвратом принято, возврат ещё не подтверждён
+	DisputeResolved  DisputeStatus = "resolved"
+)
+
+// DisputeOutcome — решение менеджера по спору.
+type DisputeOutcome string
+
+const (
+	OutcomeReclean       DisputeOutcome = "reclean"
+	OutcomePartialRefund DisputeOutcome = "partial_refund"
+	OutcomeFullRefund    DisputeOutcome = "full_refund"
+	OutcomeRejected      DisputeOutcome = "rejected"
+)
+
+// Dispute — претензия клиента к выполненному заказу. Хранится в самом заказе,
+// переписка ведётся в тикете support-service (TicketID).
+type Dispute struct {
+	ClientID       string          `bson:"client_id" json:"client_id"`
+	Category       DisputeCategory `bson:"category" json:"category"`
+	Description    string          `bson:"description" json:"description"`
+	PhotoURLs      []string        `bson:"photo_urls,omitempty" json:"photo_urls,omitempty"` // загружены через media-service
+	TicketID       string          `bson:"ticket_id,omitempty" json:"ticket_id,omitempty"`
+	Status         DisputeStatus   `bson:"status" json:"status"`
+	Outcome        DisputeOutcome  `bson:"outcome,omitempty" json:"outcome,omitempty"`
+	RefundAmount   float64         `bson:"refund_amount,omitempty" json:"refund_amount,omitempty"`
+	RefundID       string          `bson:"refund_id,omitempty" json:"refund_id,omitempty"`
+	RecleanOrderID string          `bson:"reclean_order_id,omitempty" json:"reclean_order_id,omitempty"`
+	ResolutionNote string          `bson:"resolution_note,omitempty" json:"resolution_note,omitempty"`
+	ResolvedBy     string          `bson:"resolved_by,omitempty" json:"resolved_by,omitempty"`
+	CreatedAt      time.Time       `bson:"created_at" json:"created_at"`
+	ResolvedAt     *time.Time      `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+}
+
+var disputeCategories = map[DisputeCategory]bool{
+	DisputeQuality: true, DisputeDamage: true, DisputeMissedItems: true, DisputeNoShow: true, DisputeOtherProblem: true,
+}
+
+// Validate проверяет категорию и описание новой претензии.
+func (d *Dispute) Validate() error {
+	if !disputeCategories[d.Category] {
+		return fmt.Errorf("%w: unknown dispute category %q", ErrValidation, d.Category)
+	}
+	if d.Description == "" {
+		return fmt.Errorf("%w: description is required", ErrValidation)
+	}
+	return nil
+}
+
+// Resolution — решение менеджера. RefundAmount нужен для partial_refund,
+// RecleanDate — для reclean.
+type Resolution struct {
+	Outcome      DisputeOutcome `json:"outcome" binding:"required"`
+	RefundAmount float64        `json:"refund_amount"`
+	RecleanDate  time.Time      `json:"reclean_date"`
+	Note         string         `json:"note"`
+}
+
+// Validate проверяет решение с учётом суммы, которую клиент фактически заплатил.
+func (r *Resolution) Validate(paid float64) error {
+	switch r.Outcome {
+	case OutcomeReclean:
+		if r.RecleanDate.IsZero() {
+			return fmt.Errorf("%w: reclean_date is required", ErrValidation)
+		}
+	case OutcomePartialRefund:
+		if r.RefundAmount <= 0 || r.RefundAmount > paid {
+			return fmt.Errorf("%w: refund_amount must be between 0 and %.2f", ErrValidation, paid)
+		}
+	case OutcomeFullRefund, OutcomeRejected:
+	default:
+		return fmt.Errorf("%w: unknown outcome %q", ErrValidation, r.Outcome)
+	}
+	return nil
+}
+
+// ResultStatus — статус, в который переходит спорный заказ после решения:
+// полный возврат отменяет заказ, остальные решения возвращают его в completed.
+func (r *Resolution) ResultStatus() OrderStatus {
+	if r.Outcome == OutcomeFullRefund {
+		return StatusCancelled
+	}
+	return StatusCompleted
+}
+
+// PayoutBlocked — выплата клинерам по заказу заморожена, пока спор не решён.
+func (o *Order) PayoutBlocked() bool {
+	return o.Status == StatusDisputed
+}
+
+// RecleanOrder готовит бесплатный повторный выезд по спорному заказу: тот же адрес
+// и услуги, нулевая цена, статус prepaid — оплачивать его не нужно.
+func (o *Order) RecleanOrder(date time.Time) *Order {
+	items := make([]PriceLineItem, 0, len(o.ServiceDetails))
+	if o.Pricing != nil {
+		for _, it := range o.Pricing.Items {
+			it.UnitPrice, it.Amount = 0, 0
+			items = append(items, it)
+		}
+	}
+	pricing := &PriceBreakdown{Items: items, PricedAt: time.Now().UTC()}
+	pricing.Calculate()
+	return &Order{
+		ClientID:       o.ClientID,
+		Address:        o.Address,
+		AddressID:      o.AddressID,
+		AddressDetails: o.AddressDetails,
+		ServiceType:    o.ServiceType,
+		ServiceIDs:     append([]string(nil), o.ServiceIDs...),
+		ServiceDetails: append([]Service(nil), o.ServiceDetails...),
+		Date:           date,
+		Status:         StatusPrePaid,
+		Comment:        fmt.Sprintf("Повторная уборка по спору к заказу %s", o.ID.Hex()),
+		Property:       o.Property,
+		Location:       o.Location,
+		ZoneID:         o.ZoneID,
+		Pricing:        pricing,
+		TotalPrice:     pricing.Total,
+		Checklist:      BuildChecklist(o.ServiceDetails),
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResolutionValidate(t *testing.T) {
+	cases := []struct {
+		name string
+		res  Resolution
+		ok   bool
+	}{
+		{"partial within paid", Resolution{Outcome: OutcomePartialRefund, RefundAmount: 400}, true},
+		{"partial above paid", Resolution{Outcome: OutcomePartialRefund, RefundAmount: 1200}, false},
+		{"partial zero", Resolution{Outcome: OutcomePartialRefund}, false},
+		{"reclean without date", Resolution{Outcome: OutcomeReclean}, false},
+		{"reclean with date", Resolution{Outcome: OutcomeReclean, RecleanDate: time.Now()}, true},
+		{"rejected", Resolution{Outcome: OutcomeRejected}, true},
+		{"unknown", Resolution{Outcome: "refund_twice"}, false},
+	}
+	for _, tc := range cases {
+		err := tc.res.Validate(1000)
+		if tc.ok && err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		}
+		if !tc.ok && !errors.Is(err, ErrValidation) {
+			t.Errorf("%s: error = %v, want ErrValidation", tc.name, err)
+		}
+	}
+}
+
+func TestRecleanOrder_FreeAndPrepaid(t *testing.T) {
+	b := &PriceBreakdown{Items: []PriceLineItem{{ServiceID: "a", Name: "Kitchen", Quantity: 2, UnitPrice: 150}}}
+	b.Calculate()
+	orig := &Order{
+		ClientID:       "client-1",
+		Address:        "Abay 1",
+		ServiceIDs:     []string{"a"},
+		ServiceDetails: []Service{{ID: "a", Name: "Kitchen", Checklist: []ChecklistTask{{Title: "Oven", Required: true}}}},
+		Status:         StatusDisputed,
+		Pricing:        b,
+		TotalPrice:     b.Total,
+	}
+
+	date := time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)
+	r := orig.RecleanOrder(date)
+	if r.Status != StatusPrePaid || r.TotalPrice != 0 || r.Pricing.Total != 0 {
+		t.Errorf("reclean status/total = %s/%v, want prepaid/0", r.Status, r.TotalPrice)
+	}
+	if len(r.Pricing.Items) != 1 || r.Pricing.Items[0].Quantity != 2 {
+		t.Errorf("reclean items = %+v, want the original line with zero price", r.Pricing.Items)
+	}
+	if !r.Date.Equal(date) || len(r.Checklist) != 1 {
+		t.Errorf("reclean date/checklist = %v/%d", r.Date, len(r.Checklist))
+	}
+	if orig.Pricing.Total != 300 {
+		t.Errorf("original pricing changed: %v", orig.Pricing.Total)
+	}
+}
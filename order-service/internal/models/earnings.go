@@ -0,0 +1,331 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrEarningsPosted     = errors.New("earnings for this order are already posted")
+	ErrStatementNotFound  = errors.New("payout statement not found")
+	ErrStatementPaid      = errors.New("payout statement is already paid out")
+	ErrStatementsConflict = errors.New("ledger entries were changed while building statements, retry")
+)
+
+// LedgerKind — вид записи в книге заработка клинеров.
+type LedgerKind string
+
+const (
+	LedgerEarning  LedgerKind = "earning"  // доля клинера за выполненный заказ
+	LedgerReversal LedgerKind = "reversal" // сторно начисления: спор, возврат
+	LedgerTip      LedgerKind = "tip"      // доля клинера в чаевых клиента
+	LedgerPayout   LedgerKind = "payout"   // выплата по ведомости
+)
+
+// Счета книги. Каждая запись переводит Amount со счёта Debit на счёт Credit,
+// поэтому баланс клинера — кредиты его счёта минус дебеты.
+const (
+	AccountPlatformRevenue = "platform:revenue"
+	AccountPlatformPayouts = "platform:payouts"
+	AccountClientTips      = "clients:tips"
+)
+
+// CleanerAccount — счёт клинера в книге.
+func CleanerAccount(cleanerID string) string {
+	return "cleaner:" + cleanerID
+}
+
+// EarningSplit — как получена сумма начисления: доля клинера в выручке заказа
+// за вычетом комиссии платформы и сбора за услуги.
+type EarningSplit struct {
+	Base       float64 `bson:"base" json:"base"`     // выручка заказа за вычетом возвратов
+	Weight     float64 `bson:"weight" json:"weight"` // доля клинера в заказе, 0..1
+	Gross      float64 `bson:"gross" json:"gross"`
+	Commission float64 `bson:"commission" json:"commission"`
+	Fee        float64 `bson:"fee" json:"fee"`
+}
+
+// LedgerEntry — запись книги заработка. Записи не меняются и не удаляются:
+// ошибочное или отменённое начисление гасится сторно (Reverse), у исходной
+// записи только ставится Reversed.
+type LedgerEntry struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Kind        LedgerKind          `bson:"kind" json:"kind"`
+	CleanerID   string              `bson:"cleaner_id" json:"cleaner_id"`
+	OrderID     string              `bson:"order_id,omitempty" json:"order_id,omitempty"`
+	TipID       string              `bson:"tip_id,omitempty" json:"tip_id,omitempty"`
+	Debit       string              `bson:"debit" json:"debit"`
+	Credit      string              `bson:"credit" json:"credit"`
+	Amount      float64             `bson:"amount" json:"amount"` // всегда больше нуля
+	Split       *EarningSplit       `bson:"split,omitempty" json:"split,omitempty"`
+	Reversed    bool                `bson:"reversed" json:"reversed"`
+	ReversalOf  *primitive.ObjectID `bson:"reversal_of,omitempty" json:"reversal_of,omitempty"`
+	StatementID *primitive.ObjectID `bson:"statement_id,omitempty" json:"statement_id,omitempty"`
+	Note        string              `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// Balance — вклад записи в баланс клинера: плюс, если его счёт кредитуется.
+func (e *LedgerEntry) Balance() float64 {
+	if e.Credit == CleanerAccount(e.CleanerID) {
+		return e.Amount
+	}
+	return -e.Amount
+}
+
+// Reverse готовит сторно записи: та же сумма с переставленными счетами.
+func (e *LedgerEntry) Reverse(note string, now time.Time) *LedgerEntry {
+	id := e.ID
+	return &LedgerEntry{
+		Kind:       LedgerReversal,
+		CleanerID:  e.CleanerID,
+		OrderID:    e.OrderID,
+		Debit:      e.Credit,
+		Credit:     e.Debit,
+		Amount:     e.Amount,
+		ReversalOf: &id,
+		Note:       note,
+		CreatedAt:  now,
+	}
+}
+
+// EarningsPolicy — правила расчёта заработка: комиссия платформы в процентах
+// и фиксированный сбор за каждую услугу заказа (ServiceFees переопределяет
+// ServiceFee для отдельных услуг).
+type EarningsPolicy struct {
+	CommissionPercent float64
+	ServiceFee        float64
+	ServiceFees       map[string]float64
+}
+
+// ParseServiceFees разбирает список вида "serviceID:сумма,serviceID:сумма".
+func ParseServiceFees(s string) (map[string]float64, error) {
+	fees := map[string]float64{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, amount, ok := strings.Cut(part, ":")
+		if !ok || strings.TrimSpace(id) == "" {
+			return nil, fmt.Errorf("invalid service fee %q, expected serviceID:amount", part)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(amount), 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid service fee amount in %q", part)
+		}
+		fees[strings.TrimSpace(id)] = v
+	}
+	return fees, nil
+}
+
+func (p EarningsPolicy) serviceFee(serviceIDs []string) float64 {
+	var total float64
+	for _, id := range serviceIDs {
+		if fee, ok := p.ServiceFees[id]; ok {
+			total += fee
+			continue
+		}
+		total += p.ServiceFee
+	}
+	return total
+}
+
+// EarningsBase — выручка заказа, с которой считается заработок: цена за вычетом
+// возврата по спору.
+func (o *Order) EarningsBase() float64 {
+	base := o.TotalPrice
+	if o.Dispute != nil {
+		base -= o.Dispute.RefundAmount
+	}
+	if base < 0 {
+		return 0
+	}
+	return base
+}
+
+// earningWeights — доли клинеров в заказе: по отработанным минутам, если
+// отметились все назначенные, иначе поровну.
+func (o *Order) earningWeights() []float64 {
+	weights := make([]float64, len(o.CleanerID))
+	var total float64
+	for i, id := range o.CleanerID {
+		if l := o.WorkLogFor(id); l != nil && l.WorkedMinutes > 0 {
+			weights[i] = float64(l.WorkedMinutes)
+			total += weights[i]
+			continue
+		}
+		total = 0
+		break
+	}
+	for i := range weights {
+		if total > 0 {
+			weights[i] /= total
+		} else {
+			weights[i] = 1 / float64(len(weights))
+		}
+	}
+	return weights
+}
+
+// OrderEarnings считает начисления назначенным клинерам за заказ. Сумма долей
+// после округления совпадает с итогом: остаток копеек достаётся последнему.
+// Клинеры с нулевой долей (бесплатная повторная уборка) записей не получают.
+func (p EarningsPolicy) OrderEarnings(o *Order, now time.Time) []*LedgerEntry {
+	if len(o.CleanerID) == 0 {
+		return nil
+	}
+	base := o.EarningsBase()
+	commission := base * p.CommissionPercent / 100
+	fee := p.serviceFee(o.ServiceIDs)
+	pool := roundMoney(base - commission - fee)
+	if pool <= 0 {
+		return nil
+	}
+
+	weights := o.earningWeights()
+	entries := make([]*LedgerEntry, 0, len(o.CleanerID))
+	var allocated float64
+	for i, cleanerID := range o.CleanerID {
+		amount := roundMoney(pool * weights[i])
+		if i == len(o.CleanerID)-1 {
+			amount = roundMoney(pool - allocated)
+		}
+		allocated += amount
+		if amount <= 0 {
+			continue
+		}
+		entries = append(entries, &LedgerEntry{
+			Kind:      LedgerEarning,
+			CleanerID: cleanerID,
+			OrderID:   o.ID.Hex(),
+			Debit:     AccountPlatformRevenue,
+			Credit:    CleanerAccount(cleanerID),
+			Amount:    amount,
+			Split: &EarningSplit{
+				Base:       roundMoney(base),
+				Weight:     weights[i],
+				Gross:      roundMoney(base * weights[i]),
+				Commission: roundMoney(commission * weights[i]),
+				Fee:        roundMoney(fee * weights[i]),
+			},
+			CreatedAt: now,
+		})
+	}
+	return entries
+}
+
+// StatementStatus — статус ведомости на выплату.
+type StatementStatus string
+
+const (
+	StatementPending StatementStatus = "pending" // сформирована, ждёт выплаты
+	StatementPaid    StatementStatus = "paid"
+)
+
+// PayoutStatement — ведомость на выплату клинеру за период: все записи книги,
+// созданные до PeriodEnd и не попавшие в прошлые ведомости, кроме записей
+// по заказам в споре — те переходят в следующий период.
+type PayoutStatement struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CleanerID   string             `bson:"cleaner_id" json:"cleaner_id"`
+	PeriodStart time.Time          `bson:"period_start" json:"period_start"` // самая ранняя запись ведомости
+	PeriodEnd   time.Time          `bson:"period_end" json:"period_end"`
+	Entries     int                `bson:"entries" json:"entries"`
+	Earnings    float64            `bson:"earnings" json:"earnings"`
+	Tips        float64            `bson:"tips" json:"tips"`
+	Reversals   float64            `bson:"reversals" json:"reversals"` // сторно, положительным числом
+	Total       float64            `bson:"total" json:"total"`
+	Status      StatementStatus    `bson:"status" json:"status"`
+	PaidAt      *time.Time         `bson:"paid_at,omitempty" json:"paid_at,omitempty"`
+	PaidBy      string             `bson:"paid_by,omitempty" json:"paid_by,omitempty"`
+	PaymentRef  string             `bson:"payment_ref,omitempty" json:"payment_ref,omitempty"` // номер платёжного поручения
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// NewStatement собирает ведомость клинера из записей книги. Возвращает nil,
+// если к выплате ничего нет: записи с нулевым или отрицательным итогом
+// остаются в книге и зачитываются в следующем периоде.
+func NewStatement(cleanerID string, entries []LedgerEntry, periodEnd, now time.Time) *PayoutStatement {
+	st := &PayoutStatement{
+		CleanerID: cleanerID,
+		PeriodEnd: periodEnd,
+		Entries:   len(entries),
+		Status:    StatementPending,
+		CreatedAt: now,
+	}
+	for _, e := range entries {
+		if st.PeriodStart.IsZero() || e.CreatedAt.Before(st.PeriodStart) {
+			st.PeriodStart = e.CreatedAt
+		}
+		switch b := e.Balance(); {
+		case b < 0:
+			st.Reversals -= b
+		case e.Kind == LedgerTip:
+			st.Tips += b
+		default:
+			st.Earnings += b
+		}
+	}
+	st.Earnings = roundMoney(st.Earnings)
+	st.Tips = roundMoney(st.Tips)
+	st.Reversals = roundMoney(st.Reversals)
+	st.Total = roundMoney(st.Earnings + st.Tips - st.Reversals)
+	if st.Total <= 0 {
+		return nil
+	}
+	return st
+}
+
+// Payout — запись о выплате по ведомости: списание со счёта клинера.
+func (st *PayoutStatement) Payout(now time.Time) *LedgerEntry {
+	id := st.ID
+	return &LedgerEntry{
+		Kind:        LedgerPayout,
+		CleanerID:   st.CleanerID,
+		Debit:       CleanerAccount(st.CleanerID),
+		Credit:      AccountPlatformPayouts,
+		Amount:      st.Total,
+		StatementID: &id,
+		Note:        st.PaymentRef,
+		CreatedAt:   now,
+	}
+}
+
+// EarningsSummary — ответ GET /cleaner/earnings.
+type EarningsSummary struct {
+	CleanerID  string            `json:"cleaner_id"`
+	Balance    float64           `json:"balance"`  // начислено и ещё не выплачено: pending + unpaid
+	Pending    float64           `json:"pending"`  // ещё не попало в ведомость
+	Blocked    float64           `json:"blocked"`  // из pending: заказы в споре, в ведомость не попадут до решения
+	Unpaid     float64           `json:"unpaid"`   // в ведомостях, ожидающих выплаты
+	PaidOut    float64           `json:"paid_out"` // выплачено за всё время
+	Entries    []LedgerEntry     `json:"entries"`  // записи вне ведомостей
+	Statements []PayoutStatement `json:"statements"`
+}
+
+// Finalize считает pending и blocked по записям вне ведомостей и итоговый баланс;
+// blocked — заказы, выплата по которым заморожена спором.
+func (s *EarningsSummary) Finalize(blocked map[string]bool) {
+	s.Pending, s.Blocked = 0, 0
+	for i := range s.Entries {
+		s.Pending += s.Entries[i].Balance()
+		if blocked[s.Entries[i].OrderID] {
+			s.Blocked += s.Entries[i].Balance()
+		}
+	}
+	s.Pending = roundMoney(s.Pending)
+	s.Blocked = roundMoney(s.Blocked)
+	s.Balance = roundMoney(s.Pending + s.Unpaid)
+}
+
+// StatementDetails — ведомость вместе с её записями.
+type StatementDetails struct {
+	PayoutStatement
+	Items []LedgerEntry `json:"items"`
+}
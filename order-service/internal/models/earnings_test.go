@@ -0,0 +1,91 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestOrderEarnings(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	policy := EarningsPolicy{CommissionPercent: 20, ServiceFee: 5, ServiceFees: map[string]float64{"windows": 10}}
+	order := &Order{
+		ID:         primitive.NewObjectID(),
+		CleanerID:  []string{"a", "b", "c"},
+		ServiceIDs: []string{"general", "windows"},
+		TotalPrice: 100,
+	}
+
+	// 100 - 20% - (5 + 10) = 65, поровну между тремя; остаток копейки — последнему.
+	entries := policy.OrderEarnings(order, now)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	var sum float64
+	for _, e := range entries {
+		sum += e.Amount
+		if e.Balance() != e.Amount || e.Debit != AccountPlatformRevenue {
+			t.Errorf("entry %+v does not credit the cleaner", e)
+		}
+	}
+	if roundMoney(sum) != 65 || entries[0].Amount != 21.67 || entries[2].Amount != 21.66 {
+		t.Errorf("amounts = %v/%v/%v, want 21.67/21.67/21.66", entries[0].Amount, entries[1].Amount, entries[2].Amount)
+	}
+
+	// Когда отметились все, доли — по отработанным минутам.
+	order.CleanerID = []string{"a", "b"}
+	order.WorkLogs = []WorkLog{{CleanerID: "a", WorkedMinutes: 90}, {CleanerID: "b", WorkedMinutes: 30}}
+	entries = policy.OrderEarnings(order, now)
+	if entries[0].Amount != 48.75 || entries[1].Amount != 16.25 {
+		t.Errorf("weighted amounts = %v/%v, want 48.75/16.25", entries[0].Amount, entries[1].Amount)
+	}
+
+	// Частичный возврат уменьшает базу, полный — обнуляет начисления.
+	order.Dispute = &Dispute{RefundAmount: 50}
+	if e := policy.OrderEarnings(order, now); e[0].Split.Base != 50 || roundMoney(e[0].Amount+e[1].Amount) != 25 {
+		t.Errorf("after partial refund got %+v", e)
+	}
+	order.Dispute.RefundAmount = 100
+	if e := policy.OrderEarnings(order, now); len(e) != 0 {
+		t.Errorf("after full refund got %d entries, want none", len(e))
+	}
+}
+
+func TestLedgerReverseAndStatement(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	earning := LedgerEntry{
+		ID: primitive.NewObjectID(), Kind: LedgerEarning, CleanerID: "a", OrderID: "o1",
+		Debit: AccountPlatformRevenue, Credit: CleanerAccount("a"), Amount: 40, CreatedAt: now.Add(-48 * time.Hour),
+	}
+	rev := earning.Reverse("refund", now)
+	if rev.Balance() != -40 || *rev.ReversalOf != earning.ID || rev.Kind != LedgerReversal {
+		t.Errorf("unexpected reversal %+v", rev)
+	}
+	other := LedgerEntry{Kind: LedgerEarning, CleanerID: "a", Debit: AccountPlatformRevenue, Credit: CleanerAccount("a"), Amount: 25, CreatedAt: now}
+
+	tip := LedgerEntry{Kind: LedgerTip, CleanerID: "a", Debit: AccountClientTips, Credit: CleanerAccount("a"), Amount: 10, CreatedAt: now}
+
+	st := NewStatement("a", []LedgerEntry{earning, *rev, other, tip}, now.Add(time.Hour), now)
+	if st == nil || st.Earnings != 65 || st.Tips != 10 || st.Reversals != 40 || st.Total != 35 || !st.PeriodStart.Equal(earning.CreatedAt) {
+		t.Fatalf("unexpected statement %+v", st)
+	}
+	if p := st.Payout(now); p.Balance() != -35 || p.StatementID == nil {
+		t.Errorf("unexpected payout %+v", p)
+	}
+	if NewStatement("a", []LedgerEntry{*rev}, now, now) != nil {
+		t.Errorf("statement with negative total must not be created")
+	}
+}
+
+func TestParseServiceFees(t *testing.T) {
+	fees, err := ParseServiceFees(" general:5, windows:12.5 ")
+	if err != nil || fees["general"] != 5 || fees["windows"] != 12.5 {
+		t.Errorf("ParseServiceFees = %v, %v", fees, err)
+	}
+	for _, bad := range []string{"general", "general:-1", ":5", "general:abc"} {
+		if _, err := ParseServiceFees(bad); err == nil {
+			t.Errorf("ParseServiceFees(%q) succeeded, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package models
+
+import "math"
+
+const earthRadiusMeters = 6371000.0
+
+// GeoPoint — координаты в градусах.
+type GeoPoint struct {
+	Lat float64 `bson:"lat" json:"lat"`
+	Lng float64 `bson:"lng" json:"lng"`
+}
+
+func (p GeoPoint) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lng >= -180 && p.Lng <= 180 && (p.Lat != 0 || p.Lng != 0)
+}
+
+// DistanceMeters — расстояние по формуле гаверсинусов.
+func DistanceMeters(a, b GeoPoint) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(b.Lat - a.Lat)
+	dLng := toRad(b.Lng - a.Lng)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(a.Lat))*math.Cos(toRad(b.Lat))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceMeters(t *testing.T) {
+	// Один градус широты ≈ 111.19 км.
+	d := DistanceMeters(GeoPoint{Lat: 43, Lng: 76.9}, GeoPoint{Lat: 44, Lng: 76.9})
+	if math.Abs(d-111195) > 100 {
+		t.Errorf("DistanceMeters = %.0f, want ≈111195", d)
+	}
+	if d := DistanceMeters(GeoPoint{Lat: 43.2, Lng: 76.9}, GeoPoint{Lat: 43.2, Lng: 76.9}); d != 0 {
+		t.Errorf("DistanceMeters(same point) = %v, want 0", d)
+	}
+}
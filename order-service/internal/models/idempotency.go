@@ -0,0 +1,73 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrIdempotencyMismatch   = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyInProgress = errors.New("request with this idempotency key is still in progress")
+)
+
+type IdempotencyStatus string
+
+const (
+	IdempotencyInProgress IdempotencyStatus = "in_progress"
+	IdempotencyCompleted  IdempotencyStatus = "completed"
+)
+
+// IdempotencyLease — сколько запрос может держать ключ в статусе in_progress.
+// Если экземпляр упал посреди обработки, по истечении аренды ключ можно занять заново.
+const IdempotencyLease = 2 * time.Minute
+
+// MaxIdempotencyKeyLength — ограничение длины заголовка Idempotency-Key.
+const MaxIdempotencyKeyLength = 255
+
+// IdempotencyRecord — сохранённый результат первого запроса с данным Idempotency-Key.
+type IdempotencyRecord struct {
+	Key         string            `bson:"_id"`
+	RequestHash string            `bson:"request_hash"`
+	Status      IdempotencyStatus `bson:"status"`
+	StatusCode  int               `bson:"status_code,omitempty"`
+	ContentType string            `bson:"content_type,omitempty"`
+	Body        []byte            `bson:"body,omitempty"`
+	CreatedAt   time.Time         `bson:"created_at"`
+	LockedUntil time.Time         `bson:"locked_until"`
+	ExpiresAt   time.Time         `bson:"expires_at"`
+}
+
+// IdempotencyScope — ключ хранения: одинаковый Idempotency-Key разных пользователей
+// или разных маршрутов не пересекается.
+func IdempotencyScope(userID, method, route, key string) string {
+	return userID + "|" + method + " " + route + "|" + key
+}
+
+// RequestFingerprint — хэш запроса для сравнения повторов. JSON-тело нормализуется
+// (порядок полей и пробелы не важны), иначе хэшируется как есть.
+func RequestFingerprint(method, path string, body []byte) string {
+	var v interface{}
+	if len(body) > 0 && json.Unmarshal(body, &v) == nil {
+		if canonical, err := json.Marshal(v); err == nil {
+			body = canonical
+		}
+	}
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Check решает, что делать с повтором: nil — можно отдать сохранённый ответ.
+func (r *IdempotencyRecord) Check(requestHash string) error {
+	if r.RequestHash != requestHash {
+		return ErrIdempotencyMismatch
+	}
+	if r.Status != IdempotencyCompleted {
+		return ErrIdempotencyInProgress
+	}
+	return nil
+}
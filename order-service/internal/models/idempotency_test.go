@@ -0,0 +1,38 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRequestFingerprint_NormalizesJSON(t *testing.T) {
+	a := RequestFingerprint("POST", "/orders", []byte(`{"date":"2025-01-01T10:00:00Z","service_ids":["a","b"]}`))
+	b := RequestFingerprint("POST", "/orders", []byte("{\n  \"service_ids\": [\"a\", \"b\"],\n  \"date\": \"2025-01-01T10:00:00Z\"\n}"))
+	if a != b {
+		t.Errorf("fingerprints differ for equivalent JSON bodies")
+	}
+
+	if c := RequestFingerprint("POST", "/orders", []byte(`{"service_ids":["b","a"]}`)); c == a {
+		t.Errorf("fingerprint did not change with the payload")
+	}
+	if d := RequestFingerprint("POST", "/orders/1/cancel", []byte(`{"date":"2025-01-01T10:00:00Z","service_ids":["a","b"]}`)); d == a {
+		t.Errorf("fingerprint did not change with the path")
+	}
+}
+
+func TestIdempotencyRecord_Check(t *testing.T) {
+	hash := RequestFingerprint("POST", "/orders", []byte(`{}`))
+
+	done := &IdempotencyRecord{RequestHash: hash, Status: IdempotencyCompleted}
+	if err := done.Check(hash); err != nil {
+		t.Errorf("Check on completed record = %v, want nil", err)
+	}
+	if err := done.Check("other"); !errors.Is(err, ErrIdempotencyMismatch) {
+		t.Errorf("Check with different hash = %v, want ErrIdempotencyMismatch", err)
+	}
+
+	running := &IdempotencyRecord{RequestHash: hash, Status: IdempotencyInProgress}
+	if err := running.Check(hash); !errors.Is(err, ErrIdempotencyInProgress) {
+		t.Errorf("Check on in-progress record = %v, want ErrIdempotencyInProgress", err)
+	}
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -10,36 +11,82 @@ import (
 type OrderStatus string
 
 const (
-	StatusPending   OrderStatus = "pending"
-	StatusAssigned  OrderStatus = "assigned"
-	StatusCompleted OrderStatus = "completed"
-	StatusPaid      OrderStatus = "paid"
-	StatusPrePaid   OrderStatus = "prepaid"
+	StatusPending    OrderStatus = "pending"
+	StatusAssigned   OrderStatus = "assigned"
+	StatusInProgress OrderStatus = "in_progress"
+	StatusCompleted  OrderStatus = "completed"
+	StatusPaid       OrderStatus = "paid"
+	StatusPrePaid    OrderStatus = "prepaid"
+	StatusCancelled  OrderStatus = "cancelled"
+	StatusNoShow     OrderStatus = "no_show"
+	StatusDisputed   OrderStatus = "disputed"
+)
+
+var (
+	ErrForbidden  = errors.New("access denied")
+	ErrValidation = errors.New("validation error")
+	// ErrVersionConflict — заказ изменили с момента чтения (не совпала version).
+	ErrVersionConflict = errors.New("order was modified concurrently")
 )
 
 type Order struct {
-	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	ClientID       string             `bson:"client_id" json:"client_id"`
-	CleanerID      []string           `bson:"cleaner_id,omitempty" json:"cleaner_id,omitempty"`
-	Address        string             `bson:"address" json:"address"`
-	ServiceType    string             `bson:"service_type" json:"service_type"`
-	ServiceIDs     []string           `bson:"service_ids" json:"service_ids"`
-	ServiceDetails []Service          `bson:"service_details,omitempty" json:"service_details,omitempty"`
-	Date           time.Time          `bson:"date" json:"date"`
-	Status         OrderStatus        `bson:"status" json:"status"`
-	PhotoURL       *string            `bson:"photo_url,omitempty" json:"photo_url,omitempty"`
-	Comment        string             `bson:"comment,omitempty" json:"comment,omitempty"`
-	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
-	TotalPrice     float64            `bson:"total_price" json:"total_price"`
-	Rating         *int               `bson:"rating,omitempty" json:"rating,omitempty"`
-	ReviewComment  *string            `bson:"review_comment,omitempty" json:"review_comment,omitempty"`
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ClientID         string             `bson:"client_id" json:"client_id"`
+	CleanerID        []string           `bson:"cleaner_id,omitempty" json:"cleaner_id,omitempty"`
+	Address          string             `bson:"address" json:"address"`                                     // строка для отображения
+	AddressID        string             `bson:"address_id,omitempty" json:"address_id,omitempty"`           // сохранённый адрес клиента
+	AddressDetails   *AddressSnapshot   `bson:"address_details,omitempty" json:"address_details,omitempty"` // копия на момент заказа
+	ServiceType      string             `bson:"service_type" json:"service_type"`
+	ServiceIDs       []string           `bson:"service_ids" json:"service_ids"`
+	ServiceDetails   []Service          `bson:"service_details,omitempty" json:"service_details,omitempty"`
+	Date             time.Time          `bson:"date" json:"date"`
+	DurationMinutes  int                `bson:"duration_minutes,omitempty" json:"duration_minutes,omitempty"`   // сумма длительностей услуг
+	EndDate          time.Time          `bson:"end_date,omitempty" json:"end_date,omitempty"`                   // Date + DurationMinutes
+	CleanersRequired int                `bson:"cleaners_required,omitempty" json:"cleaners_required,omitempty"` // максимум по выбранным услугам
+	Status           OrderStatus        `bson:"status" json:"status"`
+	PhotoURL         *string            `bson:"photo_url,omitempty" json:"photo_url,omitempty"`
+	Comment          string             `bson:"comment,omitempty" json:"comment,omitempty"`
+	Property         *PropertySize      `bson:"property,omitempty" json:"property,omitempty"`
+	Location         *GeoPoint          `bson:"location,omitempty" json:"location,omitempty"` // координаты адреса
+	ZoneID           string             `bson:"zone_id,omitempty" json:"zone_id,omitempty"`   // зона обслуживания на момент заказа
+	QuoteID          string             `bson:"quote_id,omitempty" json:"quote_id,omitempty"`
+	PromoCode        string             `bson:"promo_code,omitempty" json:"promo_code,omitempty"`
+	CreatedAt        time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time          `bson:"updated_at" json:"updated_at"`
+	TotalPrice       float64            `bson:"total_price" json:"total_price"`             // = Pricing.Total
+	Pricing          *PriceBreakdown    `bson:"pricing,omitempty" json:"pricing,omitempty"` // фиксируется при создании
+	Cancellation     *CancellationInfo  `bson:"cancellation,omitempty" json:"cancellation,omitempty"`
+	Dispute          *Dispute           `bson:"dispute,omitempty" json:"dispute,omitempty"`
+	Checklist        []ChecklistItem    `bson:"checklist,omitempty" json:"checklist,omitempty"` // копия чек-листов услуг
+	WorkLogs         []WorkLog          `bson:"work_logs,omitempty" json:"work_logs,omitempty"`
+	StartedAt        *time.Time         `bson:"started_at,omitempty" json:"started_at,omitempty"`         // первая отметка о начале
+	WorkedMinutes    int                `bson:"worked_minutes,omitempty" json:"worked_minutes,omitempty"` // фактическая длительность
+	RemindersSent    []string           `bson:"reminders_sent,omitempty" json:"-"`                        // отправленные напоминания, см. ReminderKey
+	Version          int64              `bson:"version" json:"version"`                                   // растёт с каждой записью
 }
 
 type Service struct {
-	ID    string  `json:"id"`
-	Name  string  `json:"name"`
-	Price float64 `json:"price"`
+	ID               string          `json:"id"`
+	Name             string          `json:"name"`
+	Price            float64         `json:"price"`
+	PriceUnit        string          `json:"priceUnit,omitempty"`
+	DurationMinutes  int             `json:"durationMinutes"`
+	CleanersRequired int             `json:"cleanersRequired"`
+	Checklist        []ChecklistTask `json:"checklist,omitempty"`
+}
+
+// CleanerBusyError — клинер уже занят заказом, пересекающимся по времени.
+type CleanerBusyError struct {
+	CleanerID       string
+	ConflictOrderID string
+	ConflictStart   time.Time
+	ConflictEnd     time.Time
+}
+
+func (e *CleanerBusyError) Error() string {
+	return fmt.Sprintf("cleaner %s is busy: overlaps with order %s (%s - %s)",
+		e.CleanerID, e.ConflictOrderID,
+		e.ConflictStart.Format(time.RFC3339), e.ConflictEnd.Format(time.RFC3339))
 }
 
 func (o *Order) Validate() error {
@@ -48,3 +95,28 @@ func (o *Order) Validate() error {
 	}
 	return nil
 }
+
+var ErrNotEnoughCleaners = errors.New("not enough available cleaners")
+
+// CandidateScore — оценка одного клинера при автоназначении с разбивкой по факторам.
+type CandidateScore struct {
+	CleanerID     string  `json:"cleaner_id"`
+	Available     bool    `json:"available"`
+	Reason        string  `json:"reason,omitempty"`
+	AverageRating float64 `json:"average_rating"`
+	RatingScore   float64 `json:"rating_score"`
+	ActiveOrders  int     `json:"active_orders"`
+	WorkloadScore float64 `json:"workload_score"`
+	InZone        bool    `json:"in_zone"` // закреплён за зоной заказа
+	Level         int     `json:"level"`
+	LevelScore    float64 `json:"level_score"`
+	Total         float64 `json:"total"`
+}
+
+// AssignmentPlan — результат ранжирования: кого нужно назначить и почему.
+type AssignmentPlan struct {
+	OrderID          string           `json:"order_id"`
+	CleanersRequired int              `json:"cleaners_required"`
+	Selected         []string         `json:"selected"`
+	Candidates       []CandidateScore `json:"candidates"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// orderTransitions описывает жизненный цикл заказа:
+// pending → paid/prepaid → assigned → in_progress → completed,
+// плюс ветки cancelled / no_show / disputed.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	StatusPending:    {StatusPaid, StatusPrePaid, StatusCancelled},
+	StatusPaid:       {StatusAssigned, StatusCancelled},
+	StatusPrePaid:    {StatusAssigned, StatusCancelled},
+	StatusAssigned:   {StatusInProgress, StatusCompleted, StatusPaid, StatusPrePaid, StatusCancelled, StatusNoShow},
+	StatusInProgress: {StatusCompleted, StatusCancelled},
+	StatusCompleted:  {StatusDisputed},
+	StatusDisputed:   {StatusCompleted, StatusCancelled},
+}
+
+// TransitionError возвращается при попытке недопустимой смены статуса.
+type TransitionError struct {
+	From OrderStatus
+	To   OrderStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("illegal order status transition: %s -> %s", e.From, e.To)
+}
+
+// CanTransition сообщает, разрешён ли переход из from в to.
+func CanTransition(from, to OrderStatus) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition возвращает *TransitionError, если переход запрещён.
+func ValidateTransition(from, to OrderStatus) error {
+	if !CanTransition(from, to) {
+		return &TransitionError{From: from, To: to}
+	}
+	return nil
+}
+
+// StatusHistoryEntry — запись коллекции order_status_history.
+type StatusHistoryEntry struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrderID   primitive.ObjectID `bson:"order_id" json:"order_id"`
+	From      OrderStatus        `bson:"from,omitempty" json:"from,omitempty"`
+	To        OrderStatus        `bson:"to" json:"to"`
+	ActorID   string             `bson:"actor_id" json:"actor_id"`
+	ActorRole string             `bson:"actor_role" json:"actor_role"`
+	Reason    string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanTransition(t *testing.T) {
+	cases := []struct {
+		from, to OrderStatus
+		want     bool
+	}{
+		{StatusPending, StatusPaid, true},
+		{StatusPending, StatusPrePaid, true},
+		{StatusPaid, StatusAssigned, true},
+		{StatusPrePaid, StatusAssigned, true},
+		{StatusAssigned, StatusInProgress, true},
+		{StatusInProgress, StatusCompleted, true},
+		{StatusCompleted, StatusDisputed, true},
+		{StatusAssigned, StatusPrePaid, true},
+
+		{StatusPending, StatusAssigned, false},
+		{StatusCompleted, StatusPaid, false},
+		{StatusCancelled, StatusPending, false},
+		{StatusNoShow, StatusAssigned, false},
+	}
+
+	for _, tc := range cases {
+		if got := CanTransition(tc.from, tc.to); got != tc.want {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+func TestValidateTransition_TypedError(t *testing.T) {
+	err := ValidateTransition(StatusCompleted, StatusPaid)
+
+	var te *TransitionError
+	if !errors.As(err, &te) {
+		t.Fatalf("ValidateTransition error = %v, want *TransitionError", err)
+	}
+	if te.From != StatusCompleted || te.To != StatusPaid {
+		t.Errorf("TransitionError = %+v, want completed -> paid", te)
+	}
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OutboxKind — куда доставляется событие.
+type OutboxKind string
+
+const (
+	OutboxNotification   OutboxKind = "notification"    // стрим notification_events_stream в Redis
+	OutboxGamificationXP OutboxKind = "gamification_xp" // POST /users/gamification/add-xp
+	OutboxCleanerRating  OutboxKind = "cleaner_rating"  // PUT /auth/internal/cleaners/:id/rating
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"
+	OutboxDelivered OutboxStatus = "delivered"
+	OutboxDead      OutboxStatus = "dead" // исчерпаны попытки, нужен ручной разбор
+)
+
+// Параметры повторной доставки: экспоненциальная задержка от OutboxBaseBackoff
+// до OutboxMaxBackoff, после OutboxMaxAttempts неудач событие помечается dead.
+const (
+	OutboxMaxAttempts = 12
+	OutboxBaseBackoff = 5 * time.Second
+	OutboxMaxBackoff  = time.Hour
+)
+
+// NotificationPayload — уведомление для notification-service.
+type NotificationPayload struct {
+	UserID    string            `bson:"user_id" json:"user_id"`
+	Role      string            `bson:"role" json:"role"`
+	Type      string            `bson:"type" json:"type"`
+	ExtraData map[string]string `bson:"extra_data,omitempty" json:"extra_data,omitempty"`
+}
+
+// XPPayload — начисление опыта в user-management-service.
+type XPPayload struct {
+	UserID string `bson:"user_id" json:"user_id"`
+	XP     int    `bson:"xp" json:"xp"`
+}
+
+// RatingPayload — рейтинг клинера, пересчитанный по отзывам, для auth-service.
+// AsOf — момент пересчёта: auth-service не применяет обновления старше сохранённого.
+type RatingPayload struct {
+	CleanerID string    `bson:"cleaner_id" json:"cleaner_id"`
+	Average   float64   `bson:"average" json:"average"`
+	Count     int       `bson:"count" json:"count"`
+	AsOf      time.Time `bson:"as_of" json:"as_of"`
+}
+
+// OutboxEvent — запись коллекции outbox. Пишется вместе с изменением заказа,
+// доставляется фоновым relay «хотя бы один раз»; получатели отсекают повторы
+// по IdempotencyKey.
+type OutboxEvent struct {
+	ID             primitive.ObjectID   `bson:"_id" json:"id"`
+	Kind           OutboxKind           `bson:"kind" json:"kind"`
+	IdempotencyKey string               `bson:"idempotency_key" json:"idempotency_key"`
+	OrderID        string               `bson:"order_id,omitempty" json:"order_id,omitempty"`
+	Notification   *NotificationPayload `bson:"notification,omitempty" json:"notification,omitempty"`
+	XP             *XPPayload           `bson:"xp,omitempty" json:"xp,omitempty"`
+	Rating         *RatingPayload       `bson:"rating,omitempty" json:"rating,omitempty"`
+	Status         OutboxStatus         `bson:"status" json:"status"`
+	Attempts       int                  `bson:"attempts" json:"attempts"`
+	AvailableAt    time.Time            `bson:"available_at" json:"available_at"` // не доставлять раньше
+	LockedUntil    *time.Time           `bson:"locked_until,omitempty" json:"-"`
+	LastError      string               `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	CreatedAt      time.Time            `bson:"created_at" json:"created_at"`
+	DeliveredAt    *time.Time           `bson:"delivered_at,omitempty" json:"delivered_at,omitempty"`
+}
+
+func newOutboxEvent(kind OutboxKind, orderID string) *OutboxEvent {
+	now := time.Now().UTC()
+	id := primitive.NewObjectID()
+	return &OutboxEvent{
+		ID:             id,
+		Kind:           kind,
+		IdempotencyKey: string(kind) + ":" + id.Hex(),
+		OrderID:        orderID,
+		Status:         OutboxPending,
+		AvailableAt:    now,
+		CreatedAt:      now,
+	}
+}
+
+// NewNotificationEvent готовит уведомление пользователю по заказу orderID.
+func NewNotificationEvent(orderID, userID, role, eventType string, extra map[string]string) *OutboxEvent {
+	e := newOutboxEvent(OutboxNotification, orderID)
+	e.Notification = &NotificationPayload{UserID: userID, Role: role, Type: eventType, ExtraData: extra}
+	return e
+}
+
+// NewXPEvent готовит начисление опыта пользователю за заказ orderID.
+func NewXPEvent(orderID, userID string, xp int) *OutboxEvent {
+	e := newOutboxEvent(OutboxGamificationXP, orderID)
+	e.XP = &XPPayload{UserID: userID, XP: xp}
+	return e
+}
+
+// NewRatingEvent готовит передачу пересчитанного рейтинга клинера в auth-service.
+func NewRatingEvent(orderID string, r *CleanerRating, asOf time.Time) *OutboxEvent {
+	e := newOutboxEvent(OutboxCleanerRating, orderID)
+	e.Rating = &RatingPayload{CleanerID: r.CleanerID, Average: r.Average, Count: r.Count, AsOf: asOf}
+	return e
+}
+
+// Delay откладывает доставку события на d.
+func (e *OutboxEvent) Delay(d time.Duration) *OutboxEvent {
+	e.AvailableAt = e.AvailableAt.Add(d)
+	return e
+}
+
+// OutboxBackoff — задержка перед попыткой номер attempt+1 после attempt неудач.
+func OutboxBackoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := OutboxBaseBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= OutboxMaxBackoff {
+			return OutboxMaxBackoff
+		}
+	}
+	return d
+}
+
+// MarkFailed фиксирует неудачную попытку и планирует следующую (или переводит в dead).
+func (e *OutboxEvent) MarkFailed(err error, now time.Time) {
+	e.Attempts++
+	e.LastError = err.Error()
+	e.LockedUntil = nil
+	if e.Attempts >= OutboxMaxAttempts {
+		e.Status = OutboxDead
+		return
+	}
+	e.AvailableAt = now.Add(OutboxBackoff(e.Attempts))
+}
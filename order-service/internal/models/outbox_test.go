@@ -0,0 +1,54 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutboxBackoff(t *testing.T) {
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, OutboxBaseBackoff},
+		{1, OutboxBaseBackoff},
+		{2, 2 * OutboxBaseBackoff},
+		{4, 8 * OutboxBaseBackoff},
+		{30, OutboxMaxBackoff},
+	}
+	for _, tc := range cases {
+		if got := OutboxBackoff(tc.attempt); got != tc.want {
+			t.Errorf("OutboxBackoff(%d) = %v, want %v", tc.attempt, got, tc.want)
+		}
+	}
+}
+
+func TestOutboxEvent_MarkFailed(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	e := NewNotificationEvent("o1", "u1", "client", "order_updated", nil)
+	if !strings.HasPrefix(e.IdempotencyKey, "notification:") || e.Status != OutboxPending {
+		t.Fatalf("new event = %+v", e)
+	}
+
+	e.MarkFailed(errors.New("no subscribers"), now)
+	if e.Attempts != 1 || e.Status != OutboxPending || !e.AvailableAt.Equal(now.Add(OutboxBaseBackoff)) {
+		t.Errorf("after first failure: attempts=%d status=%s available=%v", e.Attempts, e.Status, e.AvailableAt)
+	}
+
+	for e.Status == OutboxPending {
+		e.MarkFailed(errors.New("still down"), now)
+	}
+	if e.Attempts != OutboxMaxAttempts || e.Status != OutboxDead || e.LastError != "still down" {
+		t.Errorf("dead event: attempts=%d status=%s err=%q", e.Attempts, e.Status, e.LastError)
+	}
+}
+
+func TestOutboxEvent_Delay(t *testing.T) {
+	e := NewXPEvent("o1", "u1", 10)
+	created := e.AvailableAt
+	if got := e.Delay(time.Hour).AvailableAt.Sub(created); got != time.Hour {
+		t.Errorf("Delay shifted by %v, want 1h", got)
+	}
+}
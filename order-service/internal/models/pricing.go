@@ -0,0 +1,237 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrRequoteRequired = errors.New("order services changed: re-quote the order to update its price")
+	ErrQuoteExpired    = errors.New("quote not found or expired")
+)
+
+// PriceLineItem — позиция расчёта: услуга, количество и цена за единицу на момент расчёта.
+type PriceLineItem struct {
+	ServiceID string  `bson:"service_id" json:"service_id"`
+	Name      string  `bson:"name" json:"name"`
+	Quantity  float64 `bson:"quantity" json:"quantity"`
+	UnitPrice float64 `bson:"unit_price" json:"unit_price"`
+	Amount    float64 `bson:"amount" json:"amount"`
+}
+
+// PriceAdjustment — скидка или надбавка. Amount всегда положительный,
+// знак определяется списком, в котором корректировка лежит.
+type PriceAdjustment struct {
+	Code        string  `bson:"code" json:"code"`
+	Description string  `bson:"description,omitempty" json:"description,omitempty"`
+	Amount      float64 `bson:"amount" json:"amount"`
+}
+
+// PriceBreakdown — зафиксированный расчёт стоимости заказа. Сохраняется при создании
+// и меняется только явным пересчётом (re-quote), а не при чтении заказа.
+type PriceBreakdown struct {
+	Items      []PriceLineItem   `bson:"items" json:"items"`
+	Subtotal   float64           `bson:"subtotal" json:"subtotal"`
+	Discounts  []PriceAdjustment `bson:"discounts,omitempty" json:"discounts,omitempty"`
+	Surcharges []PriceAdjustment `bson:"surcharges,omitempty" json:"surcharges,omitempty"`
+	TaxPercent float64           `bson:"tax_percent" json:"tax_percent"`
+	Tax        float64           `bson:"tax" json:"tax"`
+	Total      float64           `bson:"total" json:"total"`
+	PricedAt   time.Time         `bson:"priced_at" json:"priced_at"`
+}
+
+// Calculate пересчитывает суммы позиций, промежуточный итог, налог и итог.
+// Скидки не могут увести сумму ниже нуля.
+func (b *PriceBreakdown) Calculate() {
+	b.Subtotal = 0
+	for i := range b.Items {
+		b.Items[i].Amount = roundMoney(b.Items[i].Quantity * b.Items[i].UnitPrice)
+		b.Subtotal += b.Items[i].Amount
+	}
+	b.Subtotal = roundMoney(b.Subtotal)
+
+	base := b.Subtotal
+	for _, s := range b.Surcharges {
+		base += s.Amount
+	}
+	for _, d := range b.Discounts {
+		base -= d.Amount
+	}
+	if base < 0 {
+		base = 0
+	}
+	base = roundMoney(base)
+	b.Tax = roundMoney(base * b.TaxPercent / 100)
+	b.Total = roundMoney(base + b.Tax)
+}
+
+// Единицы, за которые берётся цена услуги (поле priceUnit в каталоге).
+const (
+	PriceUnitFixed    = "fixed"
+	PriceUnitM2       = "per_m2"
+	PriceUnitRoom     = "per_room"
+	PriceUnitBathroom = "per_bathroom"
+)
+
+// PropertySize — параметры помещения для поштучной цены.
+type PropertySize struct {
+	AreaM2    float64 `bson:"area_m2,omitempty" json:"area_m2,omitempty"`
+	Rooms     int     `bson:"rooms,omitempty" json:"rooms,omitempty"`
+	Bathrooms int     `bson:"bathrooms,omitempty" json:"bathrooms,omitempty"`
+}
+
+// quantity возвращает количество единиц для услуги с данной единицей цены.
+func (p *PropertySize) quantity(unit string) (float64, error) {
+	var size PropertySize
+	if p != nil {
+		size = *p
+	}
+	switch unit {
+	case "", PriceUnitFixed:
+		return 1, nil
+	case PriceUnitM2:
+		if size.AreaM2 <= 0 {
+			return 0, fmt.Errorf("%w: area_m2 is required", ErrValidation)
+		}
+		return size.AreaM2, nil
+	case PriceUnitRoom:
+		if size.Rooms <= 0 {
+			return 0, fmt.Errorf("%w: rooms is required", ErrValidation)
+		}
+		return float64(size.Rooms), nil
+	case PriceUnitBathroom:
+		if size.Bathrooms <= 0 {
+			return 0, fmt.Errorf("%w: bathrooms is required", ErrValidation)
+		}
+		return float64(size.Bathrooms), nil
+	}
+	return 0, fmt.Errorf("%w: unknown price unit %q", ErrValidation, unit)
+}
+
+// Коды надбавок в расчёте.
+const (
+	SurchargeWeekend     = "weekend"
+	SurchargeShortNotice = "short_notice"
+	SurchargeMinimum     = "minimum_order"
+	SurchargeZone        = "zone"
+
+	// DiscountZone — скидка зоны с множителем меньше 1.
+	DiscountZone = "zone"
+)
+
+// PricingRules — правила расчёта: надбавки за выходные и срочность, минимальная сумма заказа, налог.
+type PricingRules struct {
+	WeekendPercent     float64
+	ShortNoticeHours   int
+	ShortNoticePercent float64
+	MinOrderValue      float64
+	TaxPercent         float64
+	ZoneMultiplier     float64        // множитель цены зоны обслуживания; 0 и 1 — без изменения
+	Location           *time.Location // часовой пояс сервиса, в котором определяется день недели; nil — UTC
+}
+
+// Build считает позиции по услугам и размеру помещения и добавляет надбавки
+// за дату уборки относительно now. Скидки добавляются потом, затем вызывается Finalize.
+func (r PricingRules) Build(services []Service, size *PropertySize, date, now time.Time) (*PriceBreakdown, error) {
+	b := &PriceBreakdown{
+		Items:      []PriceLineItem{},
+		TaxPercent: r.TaxPercent,
+		PricedAt:   now,
+	}
+	for _, svc := range services {
+		qty, err := size.quantity(svc.PriceUnit)
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", svc.Name, err)
+		}
+		b.Items = append(b.Items, PriceLineItem{
+			ServiceID: svc.ID,
+			Name:      svc.Name,
+			Quantity:  qty,
+			UnitPrice: svc.Price,
+		})
+	}
+	b.Calculate()
+
+	loc := r.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	if day := date.In(loc).Weekday(); r.WeekendPercent > 0 && (day == time.Saturday || day == time.Sunday) {
+		b.Surcharges = append(b.Surcharges, PriceAdjustment{
+			Code:        SurchargeWeekend,
+			Description: fmt.Sprintf("weekend +%g%%", r.WeekendPercent),
+			Amount:      roundMoney(b.Subtotal * r.WeekendPercent / 100),
+		})
+	}
+	if r.ShortNoticePercent > 0 && date.Sub(now) < time.Duration(r.ShortNoticeHours)*time.Hour {
+		b.Surcharges = append(b.Surcharges, PriceAdjustment{
+			Code:        SurchargeShortNotice,
+			Description: fmt.Sprintf("less than %dh notice +%g%%", r.ShortNoticeHours, r.ShortNoticePercent),
+			Amount:      roundMoney(b.Subtotal * r.ShortNoticePercent / 100),
+		})
+	}
+	// Множитель больше 1 — надбавка, меньше 1 — скидка дешёвой зоны (Amount всегда положительный).
+	switch m := r.ZoneMultiplier; {
+	case m > 1:
+		b.Surcharges = append(b.Surcharges, PriceAdjustment{
+			Code:        SurchargeZone,
+			Description: fmt.Sprintf("service zone x%g", m),
+			Amount:      roundMoney(b.Subtotal * (m - 1)),
+		})
+	case m > 0 && m < 1:
+		b.Discounts = append(b.Discounts, PriceAdjustment{
+			Code:        DiscountZone,
+			Description: fmt.Sprintf("service zone x%g", m),
+			Amount:      roundMoney(b.Subtotal * (1 - m)),
+		})
+	}
+	r.Finalize(b)
+	return b, nil
+}
+
+// Finalize доводит сумму до минимальной стоимости заказа (после скидок, до налога)
+// и пересчитывает итог. Безопасно вызывать повторно после добавления скидок.
+func (r PricingRules) Finalize(b *PriceBreakdown) {
+	surcharges := b.Surcharges[:0]
+	for _, s := range b.Surcharges {
+		if s.Code != SurchargeMinimum {
+			surcharges = append(surcharges, s)
+		}
+	}
+	b.Surcharges = surcharges
+	b.Calculate()
+
+	if base := b.Total - b.Tax; r.MinOrderValue > 0 && len(b.Items) > 0 && base < r.MinOrderValue {
+		b.Surcharges = append(b.Surcharges, PriceAdjustment{
+			Code:        SurchargeMinimum,
+			Description: fmt.Sprintf("minimum order value %g", r.MinOrderValue),
+			Amount:      roundMoney(r.MinOrderValue - base),
+		})
+		b.Calculate()
+	}
+}
+
+// QuoteRequest — входные данные POST /orders/quote.
+type QuoteRequest struct {
+	ServiceIDs []string      `json:"service_ids" binding:"required,min=1"`
+	Property   *PropertySize `json:"property"`
+	Date       time.Time     `json:"date" binding:"required"`
+	PromoCode  string        `json:"promo_code,omitempty"`
+	AddressID  string        `json:"address_id,omitempty"` // для зоны обслуживания
+	Location   *GeoPoint     `json:"location,omitempty"`   // если адрес не сохранён
+}
+
+// Quote — выданная клиенту цена. Живёт ограниченное время; заказ, созданный
+// с quote_id, получает ровно этот расчёт.
+type Quote struct {
+	ID         string         `json:"quote_id"`
+	ClientID   string         `json:"client_id"`
+	ServiceIDs []string       `json:"service_ids"`
+	Property   *PropertySize  `json:"property,omitempty"`
+	Date       time.Time      `json:"date"`
+	PromoCode  string         `json:"promo_code,omitempty"`
+	ZoneID     string         `json:"zone_id,omitempty"`
+	Pricing    PriceBreakdown `json:"pricing"`
+	ExpiresAt  time.Time      `json:"expires_at"`
+}
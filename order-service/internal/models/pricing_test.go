@@ -0,0 +1,121 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPriceBreakdownCalculate(t *testing.T) {
+	b := PriceBreakdown{
+		Items: []PriceLineItem{
+			{ServiceID: "a", Quantity: 1, UnitPrice: 100},
+			{ServiceID: "b", Quantity: 2, UnitPrice: 25.5},
+		},
+		Surcharges: []PriceAdjustment{{Code: "weekend", Amount: 20}},
+		Discounts:  []PriceAdjustment{{Code: "promo", Amount: 41}},
+		TaxPercent: 10,
+	}
+	b.Calculate()
+
+	if b.Subtotal != 151 {
+		t.Errorf("Subtotal = %v, want 151", b.Subtotal)
+	}
+	if b.Tax != 13 {
+		t.Errorf("Tax = %v, want 13", b.Tax)
+	}
+	if b.Total != 143 {
+		t.Errorf("Total = %v, want 143", b.Total)
+	}
+}
+
+func TestPriceBreakdownCalculate_DiscountFloor(t *testing.T) {
+	b := PriceBreakdown{
+		Items:     []PriceLineItem{{ServiceID: "a", Quantity: 1, UnitPrice: 10}},
+		Discounts: []PriceAdjustment{{Code: "promo", Amount: 50}},
+	}
+	b.Calculate()
+
+	if b.Total != 0 {
+		t.Errorf("Total = %v, want 0", b.Total)
+	}
+}
+
+func TestPricingRulesBuild(t *testing.T) {
+	rules := PricingRules{WeekendPercent: 20, ShortNoticeHours: 24, ShortNoticePercent: 10}
+	now := time.Date(2025, 6, 6, 12, 0, 0, 0, time.UTC)  // пятница
+	date := time.Date(2025, 6, 7, 10, 0, 0, 0, time.UTC) // суббота, меньше чем через 24 часа
+	services := []Service{
+		{ID: "a", Name: "Floors", Price: 2, PriceUnit: PriceUnitM2},
+		{ID: "b", Name: "Bathroom", Price: 30, PriceUnit: PriceUnitBathroom},
+		{ID: "c", Name: "Windows", Price: 40},
+	}
+
+	b, err := rules.Build(services, &PropertySize{AreaM2: 50, Bathrooms: 2}, date, now)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if b.Subtotal != 200 {
+		t.Errorf("Subtotal = %v, want 200", b.Subtotal)
+	}
+	if len(b.Surcharges) != 2 {
+		t.Fatalf("Surcharges = %+v, want weekend and short notice", b.Surcharges)
+	}
+	if b.Total != 260 {
+		t.Errorf("Total = %v, want 260", b.Total)
+	}
+}
+
+func TestPricingRulesWeekendInLocation(t *testing.T) {
+	almaty, err := time.LoadLocation("Asia/Almaty")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	rules := PricingRules{WeekendPercent: 20, Location: almaty}
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	services := []Service{{ID: "a", Name: "Windows", Price: 100}}
+
+	cases := []struct {
+		date    time.Time
+		weekend bool
+	}{
+		{time.Date(2025, 6, 6, 20, 0, 0, 0, time.UTC), true},  // пятница в UTC, суббота 01:00 в Алматы
+		{time.Date(2025, 6, 8, 20, 0, 0, 0, time.UTC), false}, // воскресенье в UTC, понедельник 01:00 в Алматы
+		{time.Date(2025, 6, 8, 10, 0, 0, 0, time.UTC), true},  // воскресенье везде
+	}
+	for _, tc := range cases {
+		b, err := rules.Build(services, nil, tc.date, now)
+		if err != nil {
+			t.Fatalf("Build: %v", err)
+		}
+		if got := len(b.Surcharges) == 1; got != tc.weekend {
+			t.Errorf("%v: weekend surcharge = %v, want %v", tc.date, got, tc.weekend)
+		}
+	}
+}
+
+func TestPricingRulesBuild_MissingSize(t *testing.T) {
+	_, err := PricingRules{}.Build([]Service{{ID: "a", Price: 2, PriceUnit: PriceUnitM2}}, nil, time.Now(), time.Now())
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("Build error = %v, want ErrValidation", err)
+	}
+}
+
+func TestPricingRulesFinalize_Minimum(t *testing.T) {
+	rules := PricingRules{MinOrderValue: 100}
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	b, err := rules.Build([]Service{{ID: "a", Price: 60}}, nil, now.Add(72*time.Hour), now)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if b.Total != 100 {
+		t.Errorf("Total = %v, want 100", b.Total)
+	}
+
+	// После скидки минимум пересчитывается, а не накапливается.
+	b.Discounts = append(b.Discounts, PriceAdjustment{Code: "promo", Amount: 10})
+	rules.Finalize(b)
+	if b.Total != 100 || len(b.Surcharges) != 1 || b.Surcharges[0].Amount != 50 {
+		t.Errorf("after discount: total %v, surcharges %+v", b.Total, b.Surcharges)
+	}
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrPromoNotFound  = errors.New("promo code not found")
+	ErrPromoExhausted = errors.New("promo code usage limit reached")
+	ErrPromoDuplicate = errors.New("promo code already exists")
+	// ErrFirstOrderPromoUsed — клиент уже применил код «на первый заказ» к другому заказу.
+	ErrFirstOrderPromoUsed = errors.New("a first-order promo code has already been used")
+)
+
+type PromoType string
+
+const (
+	PromoPercentage PromoType = "percentage"
+	PromoFixed      PromoType = "fixed"
+)
+
+// PromoCode — скидочный код (коллекция promo_codes).
+type PromoCode struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Code           string             `bson:"code" json:"code"` // хранится в верхнем регистре
+	Description    string             `bson:"description,omitempty" json:"description,omitempty"`
+	Type           PromoType          `bson:"type" json:"type"`
+	Value          float64            `bson:"value" json:"value"`                                   // процент или сумма
+	MaxDiscount    float64            `bson:"max_discount,omitempty" json:"max_discount,omitempty"` // потолок для процентной скидки
+	ValidFrom      *time.Time         `bson:"valid_from,omitempty" json:"valid_from,omitempty"`
+	ValidTo        *time.Time         `bson:"valid_to,omitempty" json:"valid_to,omitempty"`
+	GlobalLimit    int                `bson:"global_limit" json:"global_limit"`     // 0 — без ограничения
+	PerUserLimit   int                `bson:"per_user_limit" json:"per_user_limit"` // 0 — без ограничения
+	FirstOrderOnly bool               `bson:"first_order_only" json:"first_order_only"`
+	ServiceIDs     []string           `bson:"service_ids,omitempty" json:"service_ids,omitempty"` // пусто — на все услуги
+	Active         bool               `bson:"active" json:"active"`
+	Redemptions    int                `bson:"redemption_count" json:"redemption_count"`
+	Usage          map[string]int     `bson:"usage,omitempty" json:"-"` // userID → число использований
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// PromoRedemption — факт применения кода к заказу (коллекция promo_redemptions).
+type PromoRedemption struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CodeID    primitive.ObjectID `bson:"code_id" json:"code_id"`
+	Code      string             `bson:"code" json:"code"`
+	UserID    string             `bson:"user_id" json:"user_id"`
+	OrderID   primitive.ObjectID `bson:"order_id" json:"order_id"`
+	Discount  float64            `bson:"discount" json:"discount"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// PromoStats — статистика использования кода.
+type PromoStats struct {
+	CodeID         primitive.ObjectID `bson:"_id" json:"code_id"`
+	Code           string             `bson:"code" json:"code"`
+	Redemptions    int                `bson:"redemptions" json:"redemptions"`
+	UniqueUsers    int                `bson:"unique_users" json:"unique_users"`
+	TotalDiscount  float64            `bson:"total_discount" json:"total_discount"`
+	LastRedeemedAt time.Time          `bson:"last_redeemed_at" json:"last_redeemed_at"`
+}
+
+// NormalizePromoCode приводит код к виду, в котором он хранится.
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (p *PromoCode) Validate() error {
+	p.Code = NormalizePromoCode(p.Code)
+	switch {
+	case p.Code == "":
+		return fmt.Errorf("%w: code is required", ErrValidation)
+	case p.Type != PromoPercentage && p.Type != PromoFixed:
+		return fmt.Errorf("%w: type must be percentage or fixed", ErrValidation)
+	case p.Value <= 0:
+		return fmt.Errorf("%w: value must be positive", ErrValidation)
+	case p.Type == PromoPercentage && p.Value > 100:
+		return fmt.Errorf("%w: percentage cannot exceed 100", ErrValidation)
+	case p.GlobalLimit < 0 || p.PerUserLimit < 0 || p.MaxDiscount < 0:
+		return fmt.Errorf("%w: limits cannot be negative", ErrValidation)
+	case p.ValidFrom != nil && p.ValidTo != nil && !p.ValidFrom.Before(*p.ValidTo):
+		return fmt.Errorf("%w: valid_to must be after valid_from", ErrValidation)
+	}
+	return nil
+}
+
+// CheckAvailable проверяет активность, окно действия и лимиты (без резервирования).
+func (p *PromoCode) CheckAvailable(userID string, now time.Time) error {
+	if !p.Active {
+		return fmt.Errorf("%w: promo code is inactive", ErrValidation)
+	}
+	if p.ValidFrom != nil && now.Before(*p.ValidFrom) {
+		return fmt.Errorf("%w: promo code is not active yet", ErrValidation)
+	}
+	if p.ValidTo != nil && !now.Before(*p.ValidTo) {
+		return fmt.Errorf("%w: promo code has expired", ErrValidation)
+	}
+	if p.GlobalLimit > 0 && p.Redemptions >= p.GlobalLimit {
+		return ErrPromoExhausted
+	}
+	if p.PerUserLimit > 0 && p.Usage[userID] >= p.PerUserLimit {
+		return ErrPromoExhausted
+	}
+	return nil
+}
+
+// Discount считает скидку по позициям расчёта. Если код ограничен услугами,
+// скидка берётся только с их суммы.
+func (p *PromoCode) Discount(b *PriceBreakdown) (PriceAdjustment, error) {
+	eligible := 0.0
+	for _, item := range b.Items {
+		if len(p.ServiceIDs) == 0 || containsID(p.ServiceIDs, item.ServiceID) {
+			eligible += item.Amount
+		}
+	}
+	if eligible == 0 {
+		return PriceAdjustment{}, fmt.Errorf("%w: promo code does not apply to the selected services", ErrValidation)
+	}
+
+	amount := p.Value
+	description := fmt.Sprintf("promo %s", p.Code)
+	if p.Type == PromoPercentage {
+		amount = eligible * p.Value / 100
+		if p.MaxDiscount > 0 && amount > p.MaxDiscount {
+			amount = p.MaxDiscount
+		}
+		description = fmt.Sprintf("promo %s -%g%%", p.Code, p.Value)
+	}
+	if amount > eligible {
+		amount = eligible
+	}
+	return PriceAdjustment{Code: p.Code, Description: description, Amount: roundMoney(amount)}, nil
+}
+
+func containsID(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
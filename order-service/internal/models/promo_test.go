@@ -0,0 +1,46 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPromoDiscount_RestrictedServicesAndCap(t *testing.T) {
+	b := &PriceBreakdown{Items: []PriceLineItem{
+		{ServiceID: "a", Quantity: 1, UnitPrice: 100},
+		{ServiceID: "b", Quantity: 1, UnitPrice: 300},
+	}}
+	b.Calculate()
+
+	promo := PromoCode{Code: "SPRING", Type: PromoPercentage, Value: 50, MaxDiscount: 120, ServiceIDs: []string{"b"}}
+	d, err := promo.Discount(b)
+	if err != nil {
+		t.Fatalf("Discount: %v", err)
+	}
+	if d.Amount != 120 {
+		t.Errorf("Amount = %v, want 120 (capped)", d.Amount)
+	}
+
+	promo.ServiceIDs = []string{"c"}
+	if _, err := promo.Discount(b); !errors.Is(err, ErrValidation) {
+		t.Errorf("Discount for other services error = %v, want ErrValidation", err)
+	}
+}
+
+func TestPromoCheckAvailable(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	promo := PromoCode{Active: true, ValidTo: &past}
+	if err := promo.CheckAvailable("u1", now); !errors.Is(err, ErrValidation) {
+		t.Errorf("expired code error = %v, want ErrValidation", err)
+	}
+
+	promo = PromoCode{Active: true, PerUserLimit: 1, Usage: map[string]int{"u1": 1}}
+	if err := promo.CheckAvailable("u1", now); !errors.Is(err, ErrPromoExhausted) {
+		t.Errorf("per-user limit error = %v, want ErrPromoExhausted", err)
+	}
+	if err := promo.CheckAvailable("u2", now); err != nil {
+		t.Errorf("other user error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReminderKey — метка отправленного напоминания в Order.RemindersSent: "24h", "2h", "90m".
+func ReminderKey(offset time.Duration) string {
+	if offset%time.Hour == 0 {
+		return fmt.Sprintf("%dh", offset/time.Hour)
+	}
+	return fmt.Sprintf("%dm", offset/time.Minute)
+}
+
+// ParseReminderOffsets разбирает список смещений вида "24h,2h" (за сколько до начала
+// заказа напоминать) и сортирует его по возрастанию.
+func ParseReminderOffsets(v string) ([]time.Duration, error) {
+	var offsets []time.Duration
+	seen := map[time.Duration]bool{}
+	for _, part := range strings.Split(v, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		d, err := time.ParseDuration(part)
+		if err != nil || d < time.Minute {
+			return nil, fmt.Errorf("invalid reminder offset %q", part)
+		}
+		d = d.Truncate(time.Minute)
+		if !seen[d] {
+			seen[d] = true
+			offsets = append(offsets, d)
+		}
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return offsets, nil
+}
+
+// DueReminder решает, какое напоминание по заказу пора отправить к моменту now.
+// offsets отсортированы по возрастанию. Если наступило сразу несколько (сервис
+// простаивал), отправляется только самое близкое к началу, остальные помечаются
+// пропущенными. Напоминание, срок которого прошёл ещё до создания заказа, тоже
+// пропускается. mark — ключи, которые нужно записать в RemindersSent;
+// send == false — отмечаем без отправки.
+func (o *Order) DueReminder(offsets []time.Duration, now time.Time) (offset time.Duration, mark []string, send bool) {
+	if !o.Date.After(now) {
+		return 0, nil, false
+	}
+	left := o.Date.Sub(now)
+	for _, off := range offsets {
+		key := ReminderKey(off)
+		if left > off || containsID(o.RemindersSent, key) {
+			continue
+		}
+		if mark == nil {
+			offset = off
+			send = o.CreatedAt.IsZero() || !o.Date.Add(-off).Before(o.CreatedAt)
+		}
+		mark = append(mark, key)
+	}
+	return offset, mark, send
+}
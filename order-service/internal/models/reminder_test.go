@@ -0,0 +1,54 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseReminderOffsets(t *testing.T) {
+	got, err := ParseReminderOffsets(" 2h, 24h,2h ,90m")
+	if err != nil {
+		t.Fatalf("ParseReminderOffsets: %v", err)
+	}
+	want := []time.Duration{90 * time.Minute, 2 * time.Hour, 24 * time.Hour}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("offsets = %v, want %v", got, want)
+	}
+	if ReminderKey(90*time.Minute) != "90m" || ReminderKey(24*time.Hour) != "24h" {
+		t.Errorf("unexpected reminder keys")
+	}
+	for _, bad := range []string{"tomorrow", "30s", "-2h"} {
+		if _, err := ParseReminderOffsets(bad); err == nil {
+			t.Errorf("ParseReminderOffsets(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestDueReminder(t *testing.T) {
+	offsets := []time.Duration{2 * time.Hour, 24 * time.Hour}
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	created := now.AddDate(0, 0, -7)
+
+	cases := []struct {
+		name     string
+		order    Order
+		wantOff  time.Duration
+		wantMark []string
+		wantSend bool
+	}{
+		{"not due yet", Order{Date: now.Add(30 * time.Hour), CreatedAt: created}, 0, nil, false},
+		{"24h due", Order{Date: now.Add(23 * time.Hour), CreatedAt: created}, 24 * time.Hour, []string{"24h"}, true},
+		{"24h already sent", Order{Date: now.Add(23 * time.Hour), CreatedAt: created, RemindersSent: []string{"24h"}}, 0, nil, false},
+		{"2h due after 24h", Order{Date: now.Add(time.Hour), CreatedAt: created, RemindersSent: []string{"24h"}}, 2 * time.Hour, []string{"2h"}, true},
+		{"catch-up sends only the closest", Order{Date: now.Add(time.Hour), CreatedAt: created}, 2 * time.Hour, []string{"2h", "24h"}, true},
+		{"booked after reminder time", Order{Date: now.Add(5 * time.Hour), CreatedAt: now.Add(-time.Hour)}, 24 * time.Hour, []string{"24h"}, false},
+		{"already started", Order{Date: now.Add(-time.Minute), CreatedAt: created}, 0, nil, false},
+	}
+	for _, tc := range cases {
+		off, mark, send := tc.order.DueReminder(offsets, now)
+		if off != tc.wantOff || !reflect.DeepEqual(mark, tc.wantMark) || send != tc.wantSend {
+			t.Errorf("%s: DueReminder = (%v, %v, %v), want (%v, %v, %v)", tc.name, off, mark, send, tc.wantOff, tc.wantMark, tc.wantSend)
+		}
+	}
+}
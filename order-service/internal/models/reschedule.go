@@ -0,0 +1,67 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrRescheduleRequired = errors.New("order has assigned cleaners: use the reschedule flow to change the date")
+	ErrRescheduleOpen     = errors.New("order already has an open reschedule request")
+	ErrNoRescheduleOpen   = errors.New("order has no open reschedule request")
+)
+
+type RescheduleStatus string
+
+const (
+	ReschedulePending   RescheduleStatus = "pending"
+	RescheduleConfirmed RescheduleStatus = "confirmed"
+	RescheduleFailed    RescheduleStatus = "failed"
+)
+
+// RescheduleResponse — ответ одного назначенного клинера.
+type RescheduleResponse struct {
+	CleanerID   string     `bson:"cleaner_id" json:"cleaner_id"`
+	Accepted    bool       `bson:"accepted" json:"accepted"`
+	Slot        *time.Time `bson:"slot,omitempty" json:"slot,omitempty"`
+	Reason      string     `bson:"reason,omitempty" json:"reason,omitempty"`
+	RespondedAt time.Time  `bson:"responded_at" json:"responded_at"`
+}
+
+// RescheduleRequest — предложение клиента перенести заказ (коллекция reschedule_requests).
+type RescheduleRequest struct {
+	ID            primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	OrderID       primitive.ObjectID   `bson:"order_id" json:"order_id"`
+	RequestedBy   string               `bson:"requested_by" json:"requested_by"`
+	Reason        string               `bson:"reason,omitempty" json:"reason,omitempty"`
+	OldDate       time.Time            `bson:"old_date" json:"old_date"`
+	ProposedSlots []time.Time          `bson:"proposed_slots" json:"proposed_slots"`
+	CleanerIDs    []string             `bson:"cleaner_ids" json:"cleaner_ids"`
+	Responses     []RescheduleResponse `bson:"responses" json:"responses"`
+	Status        RescheduleStatus     `bson:"status" json:"status"`
+	ChosenSlot    *time.Time           `bson:"chosen_slot,omitempty" json:"chosen_slot,omitempty"`
+	CreatedAt     time.Time            `bson:"created_at" json:"created_at"`
+	ResolvedAt    *time.Time           `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+}
+
+// HasSlot сообщает, входит ли slot в предложенные клиентом.
+func (r *RescheduleRequest) HasSlot(slot time.Time) bool {
+	for _, s := range r.ProposedSlots {
+		if s.Equal(slot) {
+			return true
+		}
+	}
+	return false
+}
+
+// Responded сообщает, ответил ли уже клинер.
+func (r *RescheduleRequest) Responded(cleanerID string) bool {
+	for _, resp := range r.Responses {
+		if resp.CleanerID == cleanerID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrReviewNotFound     = errors.New("review not found")
+	ErrAlreadyReviewed    = errors.New("cleaner has already been reviewed for this order")
+	ErrOrderNotReviewable = errors.New("only completed orders can be reviewed")
+)
+
+const MaxReviewTextLength = 2000
+
+// ReviewRatings — оценки по отдельным критериям, каждая от 1 до 5.
+type ReviewRatings struct {
+	Quality     int `bson:"quality" json:"quality"`
+	Punctuality int `bson:"punctuality" json:"punctuality"`
+	Politeness  int `bson:"politeness" json:"politeness"`
+}
+
+func (r ReviewRatings) Validate() error {
+	for name, v := range map[string]int{"quality": r.Quality, "punctuality": r.Punctuality, "politeness": r.Politeness} {
+		if v < 1 || v > 5 {
+			return fmt.Errorf("%w: %s rating must be between 1 and 5", ErrValidation, name)
+		}
+	}
+	return nil
+}
+
+// Overall — итоговая оценка отзыва: среднее по критериям, округлённое до сотых.
+func (r ReviewRatings) Overall() float64 {
+	return round2(float64(r.Quality+r.Punctuality+r.Politeness) / 3)
+}
+
+// ReviewReply — публичный ответ клинера на отзыв.
+type ReviewReply struct {
+	Text      string    `bson:"text" json:"text"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// ReviewModeration — решение менеджера: скрытый отзыв не показывается публично
+// и не учитывается в рейтинге, помеченный (flagged) ждёт разбора.
+type ReviewModeration struct {
+	Hidden      bool      `bson:"hidden" json:"hidden"`
+	Flagged     bool      `bson:"flagged" json:"flagged"`
+	Note        string    `bson:"note,omitempty" json:"note,omitempty"`
+	ModeratedBy string    `bson:"moderated_by" json:"moderated_by"`
+	ModeratedAt time.Time `bson:"moderated_at" json:"moderated_at"`
+}
+
+// ReviewModerationInput — изменения модератора; nil-поля не меняются.
+type ReviewModerationInput struct {
+	Hidden  *bool  `json:"hidden"`
+	Flagged *bool  `json:"flagged"`
+	Note    string `json:"note"`
+}
+
+// Review — отзыв клиента об одном клинере по одному заказу (коллекция reviews,
+// уникальна по паре order_id + cleaner_id).
+type Review struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrderID    primitive.ObjectID `bson:"order_id" json:"order_id"`
+	CleanerID  string             `bson:"cleaner_id" json:"cleaner_id"`
+	ClientID   string             `bson:"client_id" json:"client_id"`
+	Ratings    ReviewRatings      `bson:"ratings" json:"ratings"`
+	Overall    float64            `bson:"overall" json:"overall"`
+	Comment    string             `bson:"comment,omitempty" json:"comment,omitempty"`
+	Reply      *ReviewReply       `bson:"reply,omitempty" json:"reply,omitempty"`
+	Hidden     bool               `bson:"hidden" json:"hidden"`
+	Flagged    bool               `bson:"flagged" json:"flagged"`
+	Moderation *ReviewModeration  `bson:"moderation,omitempty" json:"moderation,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// ReviewInput — оценка одного клинера в запросе клиента.
+type ReviewInput struct {
+	CleanerID string        `json:"cleaner_id"`
+	Ratings   ReviewRatings `json:"ratings"`
+	Comment   string        `json:"comment"`
+}
+
+// NewReviews проверяет оценки клиента по заказу и готовит отзывы: заказ должен быть
+// завершён и принадлежать клиенту, каждый клинер — назначен на заказ и оценён не больше раза.
+// Единственная оценка без cleaner_id относится ко всем клинерам заказа.
+func NewReviews(order *Order, clientID string, inputs []ReviewInput, now time.Time) ([]*Review, error) {
+	if order.ClientID != clientID {
+		return nil, ErrForbidden
+	}
+	if order.Status != StatusCompleted {
+		return nil, ErrOrderNotReviewable
+	}
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("%w: at least one review is required", ErrValidation)
+	}
+	if len(inputs) == 1 && inputs[0].CleanerID == "" {
+		shared := inputs[0]
+		inputs = make([]ReviewInput, 0, len(order.CleanerID))
+		for _, cleanerID := range order.CleanerID {
+			in := shared
+			in.CleanerID = cleanerID
+			inputs = append(inputs, in)
+		}
+	}
+	seen := make(map[string]bool, len(inputs))
+	reviews := make([]*Review, 0, len(inputs))
+	for _, in := range inputs {
+		if !containsID(order.CleanerID, in.CleanerID) {
+			return nil, fmt.Errorf("%w: cleaner %s is not assigned to this order", ErrValidation, in.CleanerID)
+		}
+		if seen[in.CleanerID] {
+			return nil, fmt.Errorf("%w: cleaner %s is reviewed twice", ErrValidation, in.CleanerID)
+		}
+		seen[in.CleanerID] = true
+		if err := in.Ratings.Validate(); err != nil {
+			return nil, err
+		}
+		comment := strings.TrimSpace(in.Comment)
+		if len(comment) > MaxReviewTextLength {
+			return nil, fmt.Errorf("%w: comment is too long", ErrValidation)
+		}
+		reviews = append(reviews, &Review{
+			OrderID:   order.ID,
+			CleanerID: in.CleanerID,
+			ClientID:  clientID,
+			Ratings:   in.Ratings,
+			Overall:   in.Ratings.Overall(),
+			Comment:   comment,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+	return reviews, nil
+}
+
+// CleanerRating — рейтинг клинера, пересчитанный по видимым отзывам.
+type CleanerRating struct {
+	CleanerID   string  `bson:"_id" json:"cleaner_id"`
+	Count       int     `bson:"count" json:"count"`
+	Average     float64 `bson:"average" json:"average"`
+	Quality     float64 `bson:"quality" json:"quality"`
+	Punctuality float64 `bson:"punctuality" json:"punctuality"`
+	Politeness  float64 `bson:"politeness" json:"politeness"`
+}
+
+// Round округляет средние до сотых для ответа и передачи в auth-service.
+func (r *CleanerRating) Round() {
+	r.Average = round2(r.Average)
+	r.Quality = round2(r.Quality)
+	r.Punctuality = round2(r.Punctuality)
+	r.Politeness = round2(r.Politeness)
+}
+
+// ReviewPage — страница отзывов о клинере. NextCursor передаётся в следующий запрос как cursor.
+type ReviewPage struct {
+	Rating     CleanerRating `json:"rating"`
+	Items      []Review      `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+	HasMore    bool          `json:"has_more"`
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewReviews(t *testing.T) {
+	order := &Order{ClientID: "client-1", CleanerID: []string{"c1", "c2"}, Status: StatusCompleted}
+	good := ReviewRatings{Quality: 5, Punctuality: 4, Politeness: 3}
+	now := time.Now()
+
+	reviews, err := NewReviews(order, "client-1", []ReviewInput{
+		{CleanerID: "c1", Ratings: good, Comment: "  great  "},
+		{CleanerID: "c2", Ratings: ReviewRatings{Quality: 1, Punctuality: 1, Politeness: 2}},
+	}, now)
+	if err != nil {
+		t.Fatalf("NewReviews: %v", err)
+	}
+	if len(reviews) != 2 || reviews[0].Overall != 4 || reviews[1].Overall != 1.33 || reviews[0].Comment != "great" {
+		t.Errorf("unexpected reviews: %+v, %+v", reviews[0], reviews[1])
+	}
+
+	shared, err := NewReviews(order, "client-1", []ReviewInput{{Ratings: good}}, now)
+	if err != nil || len(shared) != 2 || shared[0].CleanerID != "c1" || shared[1].CleanerID != "c2" {
+		t.Errorf("shared rating: reviews = %+v, err = %v, want one per cleaner", shared, err)
+	}
+
+	cases := []struct {
+		name   string
+		order  *Order
+		client string
+		inputs []ReviewInput
+		want   error
+	}{
+		{"other client", order, "client-2", []ReviewInput{{CleanerID: "c1", Ratings: good}}, ErrForbidden},
+		{"not completed", &Order{ClientID: "client-1", CleanerID: []string{"c1"}, Status: StatusInProgress}, "client-1", []ReviewInput{{CleanerID: "c1", Ratings: good}}, ErrOrderNotReviewable},
+		{"unassigned cleaner", order, "client-1", []ReviewInput{{CleanerID: "c3", Ratings: good}}, ErrValidation},
+		{"duplicate cleaner", order, "client-1", []ReviewInput{{CleanerID: "c1", Ratings: good}, {CleanerID: "c1", Ratings: good}}, ErrValidation},
+		{"rating out of range", order, "client-1", []ReviewInput{{CleanerID: "c1", Ratings: ReviewRatings{Quality: 6, Punctuality: 5, Politeness: 5}}}, ErrValidation},
+		{"empty", order, "client-1", nil, ErrValidation},
+	}
+	for _, tc := range cases {
+		if _, err := NewReviews(tc.order, tc.client, tc.inputs, now); !errors.Is(err, tc.want) {
+			t.Errorf("%s: error = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
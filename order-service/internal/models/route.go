@@ -0,0 +1,122 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// routeDetourFactor переводит расстояние по прямой в приблизительное расстояние по дорогам.
+const routeDetourFactor = 1.3
+
+// RouteStop — один заказ в дневном маршруте клинера.
+type RouteStop struct {
+	Seq             int         `json:"seq"`
+	OrderID         string      `json:"order_id"`
+	Status          OrderStatus `json:"status"`
+	Address         string      `json:"address"`
+	Location        *GeoPoint   `json:"location,omitempty"`
+	Start           time.Time   `json:"start"`
+	End             time.Time   `json:"end"`
+	DistanceMeters  *float64    `json:"distance_from_prev_meters,omitempty"` // nil — первый заказ или нет координат
+	TravelMinutes   *int        `json:"travel_from_prev_minutes,omitempty"`
+	EarliestArrival *time.Time  `json:"earliest_arrival,omitempty"` // конец предыдущего заказа + дорога
+}
+
+// InfeasibleLeg — пара заказов, между которыми клинер не успевает доехать.
+type InfeasibleLeg struct {
+	FromOrderID      string `json:"from_order_id"`
+	ToOrderID        string `json:"to_order_id"`
+	GapMinutes       int    `json:"gap_minutes"`
+	TravelMinutes    int    `json:"travel_minutes"`
+	ShortfallMinutes int    `json:"shortfall_minutes"`
+}
+
+// CleanerRoute — предлагаемый порядок объезда заказов клинера за день.
+type CleanerRoute struct {
+	CleanerID           string          `json:"cleaner_id"`
+	Date                string          `json:"date"`
+	Stops               []RouteStop     `json:"stops"`
+	TotalDistanceMeters float64         `json:"total_distance_meters"`
+	TotalTravelMinutes  int             `json:"total_travel_minutes"`
+	Infeasible          []InfeasibleLeg `json:"infeasible"`
+	Feasible            bool            `json:"feasible"`
+	MissingLocations    []string        `json:"missing_locations,omitempty"` // заказы без координат: дорога не оценена
+}
+
+// TravelMinutes — оценка времени в пути по расстоянию гаверсинусов с поправкой на дороги.
+func TravelMinutes(a, b GeoPoint, speedKmh float64) (float64, int) {
+	distance := math.Round(DistanceMeters(a, b))
+	if speedKmh <= 0 {
+		return distance, 0
+	}
+	hours := distance * routeDetourFactor / 1000 / speedKmh
+	return distance, int(math.Ceil(hours * 60))
+}
+
+// PlanRoute упорядочивает заказы дня и оценивает переезды между ними.
+// Время заказов зафиксировано, поэтому порядок в первую очередь хронологический:
+// из заказов, пересекающихся с самым ранним оставшимся, выбирается ближайший
+// к текущей точке (эвристика ближайшего соседа по гаверсинусу).
+func PlanRoute(cleanerID, date string, stops []RouteStop, speedKmh float64) CleanerRoute {
+	route := CleanerRoute{CleanerID: cleanerID, Date: date, Stops: []RouteStop{}, Infeasible: []InfeasibleLeg{}}
+	remaining := append([]RouteStop(nil), stops...)
+	sort.SliceStable(remaining, func(i, j int) bool { return remaining[i].Start.Before(remaining[j].Start) })
+
+	var current *GeoPoint
+	for len(remaining) > 0 {
+		earliestEnd := remaining[0].End
+		pick := 0
+		best := math.Inf(1)
+		for i, st := range remaining {
+			if i > 0 && !st.Start.Before(earliestEnd) {
+				break
+			}
+			if current == nil || st.Location == nil {
+				continue
+			}
+			if d := DistanceMeters(*current, *st.Location); d < best {
+				best, pick = d, i
+			}
+		}
+		next := remaining[pick]
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+		if next.Location != nil {
+			current = next.Location
+		}
+		route.Stops = append(route.Stops, next)
+	}
+
+	for i := range route.Stops {
+		st := &route.Stops[i]
+		st.Seq = i + 1
+		if st.Location == nil {
+			route.MissingLocations = append(route.MissingLocations, st.OrderID)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := route.Stops[i-1]
+		if prev.Location == nil || st.Location == nil {
+			continue
+		}
+		distance, travel := TravelMinutes(*prev.Location, *st.Location, speedKmh)
+		arrival := prev.End.Add(time.Duration(travel) * time.Minute)
+		st.DistanceMeters, st.TravelMinutes, st.EarliestArrival = &distance, &travel, &arrival
+		route.TotalDistanceMeters += distance
+		route.TotalTravelMinutes += travel
+
+		if arrival.After(st.Start) {
+			gap := int(st.Start.Sub(prev.End).Minutes())
+			route.Infeasible = append(route.Infeasible, InfeasibleLeg{
+				FromOrderID:      prev.OrderID,
+				ToOrderID:        st.OrderID,
+				GapMinutes:       gap,
+				TravelMinutes:    travel,
+				ShortfallMinutes: travel - gap,
+			})
+		}
+	}
+	route.Feasible = len(route.Infeasible) == 0
+	return route
+}
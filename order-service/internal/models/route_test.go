@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlanRoute_NearestAmongOverlappingAndInfeasibleLeg(t *testing.T) {
+	day := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+	home := &GeoPoint{Lat: 43.238, Lng: 76.889}
+	near := &GeoPoint{Lat: 43.240, Lng: 76.895} // ~0.5 км
+	far := &GeoPoint{Lat: 43.350, Lng: 77.050}  // ~18 км
+
+	stops := []RouteStop{
+		{OrderID: "far", Location: far, Start: at(12, 0), End: at(14, 0)},
+		{OrderID: "first", Location: home, Start: at(9, 0), End: at(11, 0)},
+		{OrderID: "near", Location: near, Start: at(12, 0), End: at(13, 0)},
+		{OrderID: "late", Location: home, Start: at(14, 10), End: at(15, 0)},
+	}
+	route := PlanRoute("c1", "2025-06-02", stops, 25)
+
+	var got []string
+	for _, st := range route.Stops {
+		got = append(got, st.OrderID)
+	}
+	want := []string{"first", "near", "far", "late"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+	if route.Feasible {
+		t.Fatalf("route should be infeasible")
+	}
+	// near → far: 0 минут между заказами при ~18 км; far → late: 10 минут на ~18 км.
+	if len(route.Infeasible) != 2 || route.Infeasible[1].FromOrderID != "far" || route.Infeasible[1].ToOrderID != "late" {
+		t.Errorf("infeasible = %+v", route.Infeasible)
+	}
+	if route.Stops[1].TravelMinutes == nil || *route.Stops[1].TravelMinutes > 5 {
+		t.Errorf("first -> near travel = %v", route.Stops[1].TravelMinutes)
+	}
+}
+
+func TestPlanRoute_MissingLocation(t *testing.T) {
+	day := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	route := PlanRoute("c1", "2025-06-02", []RouteStop{
+		{OrderID: "a", Start: day, End: day.Add(time.Hour)},
+		{OrderID: "b", Location: &GeoPoint{Lat: 43.2, Lng: 76.9}, Start: day.Add(2 * time.Hour), End: day.Add(3 * time.Hour)},
+	}, 25)
+	if !route.Feasible || len(route.MissingLocations) != 1 || route.Stops[1].TravelMinutes != nil {
+		t.Errorf("route = %+v", route)
+	}
+}
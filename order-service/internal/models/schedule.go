@@ -0,0 +1,203 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrOutsideWorkingHours = errors.New("outside cleaner working hours")
+
+// TimeRange — полуинтервал [From, To).
+type TimeRange struct {
+	From   time.Time `bson:"from" json:"from"`
+	To     time.Time `bson:"to" json:"to"`
+	Reason string    `bson:"reason,omitempty" json:"reason,omitempty"`
+}
+
+func (r TimeRange) Overlaps(from, to time.Time) bool {
+	return r.From.Before(to) && from.Before(r.To)
+}
+
+// WorkingHours — рабочее окно в конкретный день недели, местное время ("09:00"–"18:00")
+// в часовом поясе, который передаёт сервис (SERVICE_TIMEZONE).
+type WorkingHours struct {
+	Day   string `bson:"day" json:"day"` // "Mon", "Tue", …, "Sun"
+	Start string `bson:"start" json:"start"`
+	End   string `bson:"end" json:"end"`
+}
+
+// CleanerSchedule — объявленный клинером график: недельные часы, выходные даты
+// и разовые периоды недоступности.
+type CleanerSchedule struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CleanerID   string             `bson:"cleaner_id" json:"cleaner_id"`
+	WeeklyHours []WorkingHours     `bson:"weekly_hours" json:"weekly_hours"`
+	DaysOff     []string           `bson:"days_off,omitempty" json:"days_off,omitempty"` // "2006-01-02"
+	Unavailable []TimeRange        `bson:"unavailable,omitempty" json:"unavailable,omitempty"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// CleanerAvailability — свободные окна одного клинера в ответе /cleaners/availability.
+type CleanerAvailability struct {
+	CleanerID string      `json:"cleaner_id"`
+	Slots     []TimeRange `json:"slots"`
+}
+
+const dayFormat = "2006-01-02"
+
+func parseClock(v string) (time.Duration, error) {
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", v)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func (s *CleanerSchedule) Validate() error {
+	for _, wh := range s.WeeklyHours {
+		if _, ok := weekdays[wh.Day]; !ok {
+			return fmt.Errorf("invalid day %q", wh.Day)
+		}
+		start, err := parseClock(wh.Start)
+		if err != nil {
+			return err
+		}
+		end, err := parseClock(wh.End)
+		if err != nil {
+			return err
+		}
+		if end <= start {
+			return fmt.Errorf("working hours on %s end before they start", wh.Day)
+		}
+	}
+	for _, d := range s.DaysOff {
+		if _, err := time.Parse(dayFormat, d); err != nil {
+			return fmt.Errorf("invalid day off %q, expected YYYY-MM-DD", d)
+		}
+	}
+	for _, u := range s.Unavailable {
+		if !u.From.Before(u.To) {
+			return errors.New("unavailability period must end after it starts")
+		}
+	}
+	return nil
+}
+
+// atClock — момент clock от начала суток day по местным часам: в день перехода
+// на летнее время сутки короче или длиннее 24 часов, поэтому не day.Add(clock).
+func atClock(day time.Time, clock time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, int(clock/time.Minute), 0, 0, day.Location())
+}
+
+var weekdays = map[string]time.Weekday{
+	"Sun": time.Sunday, "Mon": time.Monday, "Tue": time.Tuesday, "Wed": time.Wednesday,
+	"Thu": time.Thursday, "Fri": time.Friday, "Sat": time.Saturday,
+}
+
+func (s *CleanerSchedule) isDayOff(day time.Time) bool {
+	key := day.Format(dayFormat)
+	for _, d := range s.DaysOff {
+		if d == key {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkingWindows разворачивает недельный график в конкретные окна, пересекающиеся с [from, to).
+// Дни недели, часы и выходные даты берутся в поясе loc. Выходные даты пропускаются;
+// разовая недоступность здесь не вычитается.
+func (s *CleanerSchedule) WorkingWindows(from, to time.Time, loc *time.Location) []TimeRange {
+	from, to = from.In(loc), to.In(loc)
+	var windows []TimeRange
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if s.isDayOff(day) {
+			continue
+		}
+		for _, wh := range s.WeeklyHours {
+			if weekdays[wh.Day] != day.Weekday() {
+				continue
+			}
+			start, err1 := parseClock(wh.Start)
+			end, err2 := parseClock(wh.End)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			w := TimeRange{From: atClock(day, start), To: atClock(day, end)}
+			if w.From.Before(from) {
+				w.From = from
+			}
+			if w.To.After(to) {
+				w.To = to
+			}
+			if w.From.Before(w.To) {
+				windows = append(windows, w)
+			}
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].From.Before(windows[j].From) })
+	return windows
+}
+
+// Covers проверяет, что интервал [start, end) целиком попадает в рабочее окно
+// (часы графика — в поясе loc) и не задевает разовую недоступность.
+func (s *CleanerSchedule) Covers(start, end time.Time, loc *time.Location) error {
+	inside := false
+	for _, w := range s.WorkingWindows(start, end, loc) {
+		if !w.From.After(start) && !w.To.Before(end) {
+			inside = true
+			break
+		}
+	}
+	if !inside {
+		return fmt.Errorf("%w: %s - %s", ErrOutsideWorkingHours, start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339))
+	}
+	for _, u := range s.Unavailable {
+		if u.Overlaps(start, end) {
+			return fmt.Errorf("%w: unavailable %s - %s", ErrOutsideWorkingHours, u.From.UTC().Format(time.RFC3339), u.To.UTC().Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// FreeSlots возвращает свободные окна в [from, to) длиной не меньше minDuration:
+// рабочие окна (в поясе loc) минус разовая недоступность и минус занятые интервалы busy.
+func (s *CleanerSchedule) FreeSlots(busy []TimeRange, from, to time.Time, minDuration time.Duration, loc *time.Location) []TimeRange {
+	blocked := append(append([]TimeRange{}, s.Unavailable...), busy...)
+	var slots []TimeRange
+	for _, w := range s.WorkingWindows(from, to, loc) {
+		for _, free := range subtractRanges(w, blocked) {
+			if free.To.Sub(free.From) >= minDuration {
+				slots = append(slots, free)
+			}
+		}
+	}
+	return slots
+}
+
+// subtractRanges вычитает из окна w все интервалы blocked.
+func subtractRanges(w TimeRange, blocked []TimeRange) []TimeRange {
+	parts := []TimeRange{{From: w.From, To: w.To}}
+	for _, b := range blocked {
+		var next []TimeRange
+		for _, p := range parts {
+			if !b.Overlaps(p.From, p.To) {
+				next = append(next, p)
+				continue
+			}
+			if p.From.Before(b.From) {
+				next = append(next, TimeRange{From: p.From, To: b.From})
+			}
+			if b.To.Before(p.To) {
+				next = append(next, TimeRange{From: b.To, To: p.To})
+			}
+		}
+		parts = next
+	}
+	return parts
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFreeSlots_SubtractsBusyAndUnavailable(t *testing.T) {
+	// 2 июня 2025 — понедельник
+	day := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	schedule := CleanerSchedule{
+		WeeklyHours: []WorkingHours{{Day: "Mon", Start: "09:00", End: "18:00"}},
+		Unavailable: []TimeRange{{From: day.Add(16 * time.Hour), To: day.Add(18 * time.Hour)}},
+	}
+	busy := []TimeRange{{From: day.Add(10 * time.Hour), To: day.Add(13 * time.Hour)}}
+
+	got := schedule.FreeSlots(busy, day, day.AddDate(0, 0, 1), time.Hour, time.UTC)
+	want := []TimeRange{
+		{From: day.Add(9 * time.Hour), To: day.Add(10 * time.Hour)},
+		{From: day.Add(13 * time.Hour), To: day.Add(16 * time.Hour)},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FreeSlots = %v, want %v", got, want)
+	}
+}
+
+func TestFreeSlots_SkipsDaysOffAndShortGaps(t *testing.T) {
+	mon := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	tue := mon.AddDate(0, 0, 1)
+	schedule := CleanerSchedule{
+		WeeklyHours: []WorkingHours{
+			{Day: "Mon", Start: "09:00", End: "12:00"},
+			{Day: "Tue", Start: "09:00", End: "12:00"},
+		},
+		DaysOff: []string{"2025-06-02"},
+	}
+	busy := []TimeRange{{From: tue.Add(9*time.Hour + 30*time.Minute), To: tue.Add(12 * time.Hour)}}
+
+	got := schedule.FreeSlots(busy, mon, tue.AddDate(0, 0, 1), time.Hour, time.UTC)
+	if len(got) != 0 {
+		t.Errorf("FreeSlots = %v, want no slots", got)
+	}
+}
+
+func TestCovers(t *testing.T) {
+	mon := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	schedule := CleanerSchedule{
+		WeeklyHours: []WorkingHours{{Day: "Mon", Start: "09:00", End: "18:00"}},
+	}
+
+	if err := schedule.Covers(mon.Add(10*time.Hour), mon.Add(12*time.Hour), time.UTC); err != nil {
+		t.Errorf("Covers inside working hours = %v, want nil", err)
+	}
+	err := schedule.Covers(mon.Add(17*time.Hour), mon.Add(19*time.Hour), time.UTC)
+	if !errors.Is(err, ErrOutsideWorkingHours) {
+		t.Errorf("Covers past end of day = %v, want ErrOutsideWorkingHours", err)
+	}
+}
+
+// Часы графика — местные: 09:00–18:00 в Алматы (UTC+5) — это 04:00–13:00 UTC.
+func TestWorkingWindowsInLocation(t *testing.T) {
+	almaty := time.FixedZone("Asia/Almaty", 5*3600)
+	schedule := CleanerSchedule{
+		WeeklyHours: []WorkingHours{{Day: "Mon", Start: "09:00", End: "18:00"}},
+	}
+	// Понедельник 2 июня по местному времени начинается в 19:00 UTC воскресенья.
+	from := time.Date(2025, 6, 1, 19, 0, 0, 0, time.UTC)
+	got := schedule.WorkingWindows(from, from.AddDate(0, 0, 1), almaty)
+	if len(got) != 1 {
+		t.Fatalf("WorkingWindows = %v, want one window", got)
+	}
+	wantFrom := time.Date(2025, 6, 2, 4, 0, 0, 0, time.UTC)
+	if !got[0].From.Equal(wantFrom) || !got[0].To.Equal(wantFrom.Add(9*time.Hour)) {
+		t.Errorf("window = %v - %v, want 04:00-13:00 UTC", got[0].From.UTC(), got[0].To.UTC())
+	}
+
+	// 08:00 UTC — 13:00 в Алматы, внутри окна; 14:00 UTC — уже 19:00, хотя по UTC было бы внутри.
+	mon := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	if err := schedule.Covers(mon.Add(8*time.Hour), mon.Add(10*time.Hour), almaty); err != nil {
+		t.Errorf("Covers 13:00-15:00 local = %v, want nil", err)
+	}
+	if err := schedule.Covers(mon.Add(14*time.Hour), mon.Add(15*time.Hour), almaty); !errors.Is(err, ErrOutsideWorkingHours) {
+		t.Errorf("Covers 19:00-20:00 local = %v, want ErrOutsideWorkingHours", err)
+	}
+}
+
+// В день перехода на летнее время окно считается по местным часам, а не сдвигом от полуночи.
+func TestWorkingWindowsAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	schedule := CleanerSchedule{WeeklyHours: []WorkingHours{{Day: "Sun", Start: "09:00", End: "12:00"}}}
+	// 30 марта 2025 — воскресенье, часы переводятся в 02:00.
+	day := time.Date(2025, 3, 30, 0, 0, 0, 0, berlin)
+	got := schedule.WorkingWindows(day, day.AddDate(0, 0, 1), berlin)
+	if len(got) != 1 || got[0].From.In(berlin).Hour() != 9 || got[0].To.In(berlin).Hour() != 12 {
+		t.Fatalf("WorkingWindows = %v, want 09:00-12:00 local", got)
+	}
+}
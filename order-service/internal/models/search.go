@@ -0,0 +1,203 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// Поля, по которым разрешена сортировка списка заказов (ключ — параметр sort, значение — поле в Mongo).
+var sortFields = map[string]string{
+	"date":        "date",
+	"created_at":  "created_at",
+	"total_price": "total_price",
+}
+
+// OrderSearch — фильтры, сортировка и курсор для списка заказов персонала.
+type OrderSearch struct {
+	Statuses  []OrderStatus
+	ClientID  string
+	CleanerID string
+	ServiceID string
+	ZoneID    string
+	DateFrom  *time.Time
+	DateTo    *time.Time
+	PriceMin  *float64
+	PriceMax  *float64
+	Text      string // полнотекстовый поиск по адресу и комментарию
+
+	Sort   string // "date", "-date", "created_at", "-created_at", "total_price", "-total_price"
+	Limit  int
+	Cursor string
+}
+
+// OrderPage — страница результатов. NextCursor передаётся в следующий запрос как cursor.
+type OrderPage struct {
+	Items      []Order `json:"items"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+	HasMore    bool    `json:"has_more"`
+}
+
+// pageCursor — позиция последнего заказа страницы: значение поля сортировки и _id.
+type pageCursor struct {
+	Sort  string             `json:"s"`
+	Time  *time.Time         `json:"t,omitempty"`
+	Price *float64           `json:"p,omitempty"`
+	ID    primitive.ObjectID `json:"id"`
+}
+
+// Normalize проставляет значения по умолчанию и проверяет сортировку и размер страницы.
+func (q *OrderSearch) Normalize() error {
+	if q.Sort == "" {
+		q.Sort = "-created_at"
+	}
+	if _, ok := sortFields[strings.TrimPrefix(q.Sort, "-")]; !ok {
+		return fmt.Errorf("%w: unsupported sort %q", ErrValidation, q.Sort)
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultPageSize
+	}
+	if q.Limit > MaxPageSize {
+		q.Limit = MaxPageSize
+	}
+	if q.DateFrom != nil && q.DateTo != nil && !q.DateFrom.Before(*q.DateTo) {
+		return fmt.Errorf("%w: date_to must be after date_from", ErrValidation)
+	}
+	if q.PriceMin != nil && q.PriceMax != nil && *q.PriceMin > *q.PriceMax {
+		return fmt.Errorf("%w: price_max must not be less than price_min", ErrValidation)
+	}
+	return nil
+}
+
+func (q *OrderSearch) sortField() (string, bool) {
+	desc := strings.HasPrefix(q.Sort, "-")
+	return sortFields[strings.TrimPrefix(q.Sort, "-")], desc
+}
+
+// SortSpec — сортировка для Find; _id добавляется для однозначного порядка.
+func (q *OrderSearch) SortSpec() bson.D {
+	field, desc := q.sortField()
+	dir := 1
+	if desc {
+		dir = -1
+	}
+	return bson.D{{Key: field, Value: dir}, {Key: "_id", Value: dir}}
+}
+
+// Filter собирает Mongo-фильтр, включая условие продолжения после курсора.
+func (q *OrderSearch) Filter() (bson.M, error) {
+	filter := bson.M{}
+	if len(q.Statuses) > 0 {
+		filter["status"] = bson.M{"$in": q.Statuses}
+	}
+	if q.ClientID != "" {
+		filter["client_id"] = q.ClientID
+	}
+	if q.CleanerID != "" {
+		filter["cleaner_id"] = q.CleanerID
+	}
+	if q.ServiceID != "" {
+		filter["service_ids"] = q.ServiceID
+	}
+	if q.ZoneID != "" {
+		filter["zone_id"] = q.ZoneID
+	}
+	if q.DateFrom != nil || q.DateTo != nil {
+		rng := bson.M{}
+		if q.DateFrom != nil {
+			rng["$gte"] = *q.DateFrom
+		}
+		if q.DateTo != nil {
+			rng["$lt"] = *q.DateTo
+		}
+		filter["date"] = rng
+	}
+	if q.PriceMin != nil || q.PriceMax != nil {
+		rng := bson.M{}
+		if q.PriceMin != nil {
+			rng["$gte"] = *q.PriceMin
+		}
+		if q.PriceMax != nil {
+			rng["$lte"] = *q.PriceMax
+		}
+		filter["total_price"] = rng
+	}
+	if q.Text != "" {
+		filter["$text"] = bson.M{"$search": q.Text}
+	}
+
+	if q.Cursor != "" {
+		after, err := q.afterCursor()
+		if err != nil {
+			return nil, err
+		}
+		filter["$and"] = []bson.M{after}
+	}
+	return filter, nil
+}
+
+// afterCursor — keyset-условие «строго после последнего заказа предыдущей страницы».
+func (q *OrderSearch) afterCursor() (bson.M, error) {
+	c, err := decodeCursor(q.Cursor)
+	if err != nil || c.Sort != q.Sort {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrValidation)
+	}
+	field, desc := q.sortField()
+	var value interface{}
+	switch {
+	case c.Time != nil:
+		value = *c.Time
+	case c.Price != nil:
+		value = *c.Price
+	default:
+		return nil, fmt.Errorf("%w: invalid cursor", ErrValidation)
+	}
+	op := "$gt"
+	if desc {
+		op = "$lt"
+	}
+	return bson.M{"$or": []bson.M{
+		{field: bson.M{op: value}},
+		{field: value, "_id": bson.M{op: c.ID}},
+	}}, nil
+}
+
+// NextCursor кодирует позицию заказа o для запроса следующей страницы.
+func (q *OrderSearch) NextCursor(o *Order) string {
+	c := pageCursor{Sort: q.Sort, ID: o.ID}
+	switch field, _ := q.sortField(); field {
+	case "date":
+		t := o.Date
+		c.Time = &t
+	case "created_at":
+		t := o.CreatedAt
+		c.Time = &t
+	case "total_price":
+		p := o.TotalPrice
+		c.Price = &p
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
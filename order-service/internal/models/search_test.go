@@ -0,0 +1,52 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestOrderSearch_Defaults(t *testing.T) {
+	q := OrderSearch{Limit: 1000}
+	if err := q.Normalize(); err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	if q.Sort != "-created_at" || q.Limit != MaxPageSize {
+		t.Errorf("defaults = %q/%d", q.Sort, q.Limit)
+	}
+	if err := (&OrderSearch{Sort: "client_id"}).Normalize(); !errors.Is(err, ErrValidation) {
+		t.Errorf("unsupported sort error = %v, want ErrValidation", err)
+	}
+}
+
+func TestOrderSearch_CursorRoundTrip(t *testing.T) {
+	q := OrderSearch{Sort: "-date", Statuses: []OrderStatus{StatusPaid}}
+	_ = q.Normalize()
+	last := &Order{ID: primitive.NewObjectID(), Date: time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)}
+
+	q.Cursor = q.NextCursor(last)
+	filter, err := q.Filter()
+	if err != nil {
+		t.Fatalf("Filter: %v", err)
+	}
+	and, ok := filter["$and"].([]bson.M)
+	if !ok || len(and) != 1 {
+		t.Fatalf("filter = %v, want keyset condition", filter)
+	}
+	or := and[0]["$or"].([]bson.M)
+	if got := or[0]["date"].(bson.M)["$lt"].(time.Time); !got.Equal(last.Date) {
+		t.Errorf("cursor date = %v, want %v", got, last.Date)
+	}
+	if got := or[1]["_id"].(bson.M)["$lt"].(primitive.ObjectID); got != last.ID {
+		t.Errorf("cursor id = %v, want %v", got, last.ID)
+	}
+
+	// Курсор от другой сортировки не принимается.
+	q.Sort = "total_price"
+	if _, err := q.Filter(); !errors.Is(err, ErrValidation) {
+		t.Errorf("foreign cursor error = %v, want ErrValidation", err)
+	}
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrTipNotFound      = errors.New("tip not found")
+	ErrOrderNotTippable = errors.New("only completed orders with assigned cleaners can be tipped")
+	ErrTipPaymentFailed = errors.New("tip payment failed")
+)
+
+// MaxTipMessageLength — наибольшая длина сообщения клинерам к чаевым.
+const MaxTipMessageLength = 500
+
+type TipStatus string
+
+const (
+	TipPending TipStatus = "pending" // создан, ждёт подтверждения платежа
+	TipPaid    TipStatus = "paid"
+	TipFailed  TipStatus = "failed"
+)
+
+// TipShare — часть чаевых, которая достаётся клинеру.
+type TipShare struct {
+	CleanerID string  `bson:"cleaner_id" json:"cleaner_id"`
+	Amount    float64 `bson:"amount" json:"amount"`
+}
+
+// Tip — чаевые клиента клинерам выполненного заказа. Оплачиваются через
+// payment-service (entity_type "tip"), после оплаты доли попадают в книгу заработка.
+type Tip struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrderID       string             `bson:"order_id" json:"order_id"`
+	ClientID      string             `bson:"client_id" json:"client_id"`
+	Amount        float64            `bson:"amount" json:"amount"`
+	Shares        []TipShare         `bson:"shares" json:"shares"`
+	Message       string             `bson:"message,omitempty" json:"message,omitempty"`
+	Status        TipStatus          `bson:"status" json:"status"`
+	FailureReason string             `bson:"failure_reason,omitempty" json:"failure_reason,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	PaidAt        *time.Time         `bson:"paid_at,omitempty" json:"paid_at,omitempty"`
+}
+
+// NewTip проверяет, что клиент может оставить чаевые по заказу, и делит сумму
+// поровну между назначенными клинерами; остаток копеек достаётся последнему.
+func NewTip(order *Order, clientID string, amount float64, message string, now time.Time) (*Tip, error) {
+	if order.ClientID != clientID {
+		return nil, ErrForbidden
+	}
+	if order.Status != StatusCompleted || len(order.CleanerID) == 0 {
+		return nil, ErrOrderNotTippable
+	}
+	amount = roundMoney(amount)
+	if amount <= 0 || math.IsInf(amount, 0) || math.IsNaN(amount) {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrValidation)
+	}
+	if len([]rune(message)) > MaxTipMessageLength {
+		return nil, fmt.Errorf("%w: message must be at most %d characters", ErrValidation, MaxTipMessageLength)
+	}
+
+	n := len(order.CleanerID)
+	share := math.Floor(amount/float64(n)*100) / 100
+	if share <= 0 {
+		return nil, fmt.Errorf("%w: amount is too small to split between %d cleaners", ErrValidation, n)
+	}
+	shares := make([]TipShare, n)
+	var allocated float64
+	for i, cleanerID := range order.CleanerID {
+		v := share
+		if i == n-1 {
+			v = roundMoney(amount - allocated)
+		}
+		allocated += v
+		shares[i] = TipShare{CleanerID: cleanerID, Amount: v}
+	}
+	return &Tip{
+		OrderID:   order.ID.Hex(),
+		ClientID:  clientID,
+		Amount:    amount,
+		Shares:    shares,
+		Message:   message,
+		Status:    TipPending,
+		CreatedAt: now,
+	}, nil
+}
+
+// VisibleTo — чаевые видят клиент, получившие их клинеры и персонал.
+func (t *Tip) VisibleTo(userID, role string) bool {
+	if role == "manager" || role == "admin" || t.ClientID == userID {
+		return true
+	}
+	for _, s := range t.Shares {
+		if s.CleanerID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// LedgerEntries — записи книги заработка по оплаченным чаевым, по одной на клинера.
+func (t *Tip) LedgerEntries(now time.Time) []*LedgerEntry {
+	entries := make([]*LedgerEntry, 0, len(t.Shares))
+	for _, s := range t.Shares {
+		entries = append(entries, &LedgerEntry{
+			Kind:      LedgerTip,
+			CleanerID: s.CleanerID,
+			OrderID:   t.OrderID,
+			TipID:     t.ID.Hex(),
+			Debit:     AccountClientTips,
+			Credit:    CleanerAccount(s.CleanerID),
+			Amount:    s.Amount,
+			CreatedAt: now,
+		})
+	}
+	return entries
+}
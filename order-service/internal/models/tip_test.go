@@ -0,0 +1,52 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewTip(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	order := &Order{ID: primitive.NewObjectID(), ClientID: "client", CleanerID: []string{"a", "b", "c"}, Status: StatusCompleted}
+
+	tip, err := NewTip(order, "client", 100, "thanks!", now)
+	if err != nil {
+		t.Fatalf("NewTip: %v", err)
+	}
+	if tip.Status != TipPending || tip.Shares[0].Amount != 33.33 || tip.Shares[2].Amount != 33.34 {
+		t.Errorf("unexpected tip %+v", tip)
+	}
+	if !tip.VisibleTo("b", "cleaner") || tip.VisibleTo("stranger", "cleaner") {
+		t.Errorf("unexpected visibility")
+	}
+
+	tip.ID = primitive.NewObjectID()
+	entries := tip.LedgerEntries(now)
+	var sum float64
+	for _, e := range entries {
+		sum += e.Balance()
+		if e.Kind != LedgerTip || e.TipID != tip.ID.Hex() || e.Debit != AccountClientTips {
+			t.Errorf("unexpected tip entry %+v", e)
+		}
+	}
+	if roundMoney(sum) != 100 {
+		t.Errorf("tip entries sum to %v, want 100", sum)
+	}
+
+	if _, err := NewTip(order, "other", 100, "", now); !errors.Is(err, ErrForbidden) {
+		t.Errorf("tip by another client: %v, want ErrForbidden", err)
+	}
+	if _, err := NewTip(order, "client", 0, "", now); !errors.Is(err, ErrValidation) {
+		t.Errorf("zero tip: %v, want ErrValidation", err)
+	}
+	if _, err := NewTip(order, "client", 0.02, "", now); !errors.Is(err, ErrValidation) {
+		t.Errorf("tip too small to split: %v, want ErrValidation", err)
+	}
+	order.Status = StatusDisputed
+	if _, err := NewTip(order, "client", 100, "", now); !errors.Is(err, ErrOrderNotTippable) {
+		t.Errorf("tip on disputed order: %v, want ErrOrderNotTippable", err)
+	}
+}
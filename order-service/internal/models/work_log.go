@@ -0,0 +1,66 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrAlreadyCheckedIn = errors.New("cleaner has already checked in to this order")
+	ErrNotCheckedIn     = errors.New("cleaner has not checked in to this order")
+)
+
+// TooFarError — клинер отмечается дальше допустимого радиуса от адреса заказа.
+type TooFarError struct {
+	DistanceMeters float64
+	RadiusMeters   float64
+}
+
+func (e *TooFarError) Error() string {
+	return fmt.Sprintf("cleaner is %.0f m from the order address (allowed %.0f m)", e.DistanceMeters, e.RadiusMeters)
+}
+
+// WorkLog — отметки одного клинера о начале и окончании работы на заказе.
+type WorkLog struct {
+	CleanerID          string     `bson:"cleaner_id" json:"cleaner_id"`
+	CheckInAt          time.Time  `bson:"check_in_at" json:"check_in_at"`
+	CheckInLocation    GeoPoint   `bson:"check_in_location" json:"check_in_location"`
+	CheckInDistanceM   *float64   `bson:"check_in_distance_m,omitempty" json:"check_in_distance_m,omitempty"` // nil — у заказа нет координат
+	CheckOutAt         *time.Time `bson:"check_out_at,omitempty" json:"check_out_at,omitempty"`
+	CheckOutLocation   *GeoPoint  `bson:"check_out_location,omitempty" json:"check_out_location,omitempty"`
+	CheckOutDistanceM  *float64   `bson:"check_out_distance_m,omitempty" json:"check_out_distance_m,omitempty"`
+	WorkedMinutes      int        `bson:"worked_minutes,omitempty" json:"worked_minutes,omitempty"`
+	LocationUnverified bool       `bson:"location_unverified,omitempty" json:"location_unverified,omitempty"` // отметки без проверки расстояния
+}
+
+// WorkLogFor возвращает отметку клинера на заказе или nil.
+func (o *Order) WorkLogFor(cleanerID string) *WorkLog {
+	for i := range o.WorkLogs {
+		if o.WorkLogs[i].CleanerID == cleanerID {
+			return &o.WorkLogs[i]
+		}
+	}
+	return nil
+}
+
+// ActualDuration — от первой отметки о начале до последней об окончании;
+// ноль, пока хотя бы один отметившийся клинер не закончил.
+func (o *Order) ActualDuration() time.Duration {
+	var start, end time.Time
+	for _, l := range o.WorkLogs {
+		if l.CheckOutAt == nil {
+			return 0
+		}
+		if start.IsZero() || l.CheckInAt.Before(start) {
+			start = l.CheckInAt
+		}
+		if l.CheckOutAt.After(end) {
+			end = *l.CheckOutAt
+		}
+	}
+	if start.IsZero() {
+		return 0
+	}
+	return end.Sub(start)
+}
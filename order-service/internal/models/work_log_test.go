@@ -0,0 +1,49 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorkLogFor(t *testing.T) {
+	order := &Order{WorkLogs: []WorkLog{{CleanerID: "a"}, {CleanerID: "b"}}}
+	l := order.WorkLogFor("b")
+	if l == nil || l.CleanerID != "b" {
+		t.Fatalf("WorkLogFor(b) = %+v", l)
+	}
+	// Возвращается указатель на элемент заказа, а не копия.
+	l.WorkedMinutes = 10
+	if order.WorkLogs[1].WorkedMinutes != 10 {
+		t.Error("WorkLogFor must return a pointer into the order")
+	}
+	if order.WorkLogFor("c") != nil {
+		t.Error("WorkLogFor(c) must be nil")
+	}
+}
+
+func TestActualDuration(t *testing.T) {
+	base := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	at := func(min int) *time.Time {
+		ts := base.Add(time.Duration(min) * time.Minute)
+		return &ts
+	}
+	cases := []struct {
+		name string
+		logs []WorkLog
+		want time.Duration
+	}{
+		{"no check-ins", nil, 0},
+		{"single cleaner", []WorkLog{{CheckInAt: base, CheckOutAt: at(90)}}, 90 * time.Minute},
+		{"still working", []WorkLog{{CheckInAt: base, CheckOutAt: at(90)}, {CheckInAt: *at(10)}}, 0},
+		// От первой отметки о начале до последней об окончании, даже если клинеры работали не одновременно.
+		{"first in to last out", []WorkLog{{CheckInAt: *at(30), CheckOutAt: at(60)}, {CheckInAt: base, CheckOutAt: at(45)}}, 60 * time.Minute},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			order := &Order{WorkLogs: tc.logs}
+			if got := order.ActualDuration(); got != tc.want {
+				t.Errorf("ActualDuration = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrZoneNotFound       = errors.New("zone not found")
+	ErrOutsideServiceArea = errors.New("address is outside every active service zone")
+)
+
+// GeoPolygon — GeoJSON Polygon: кольца из позиций [lng, lat], первое кольцо — внешняя граница.
+type GeoPolygon struct {
+	Type        string        `bson:"type" json:"type"`
+	Coordinates [][][]float64 `bson:"coordinates" json:"coordinates"`
+}
+
+// Zone — зона обслуживания (коллекция zones, 2dsphere-индекс по area).
+type Zone struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name            string             `bson:"name" json:"name"`
+	Area            GeoPolygon         `bson:"area" json:"area"`
+	Active          bool               `bson:"active" json:"active"`
+	MinNoticeHours  int                `bson:"min_notice_hours" json:"min_notice_hours"` // за сколько часов минимум можно заказать
+	PriceMultiplier float64            `bson:"price_multiplier" json:"price_multiplier"` // 1 — без изменения цены
+	CleanerIDs      []string           `bson:"cleaner_ids,omitempty" json:"cleaner_ids"` // клинеры зоны — приоритет при назначении
+	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// Validate проверяет настройки и геометрию зоны; пустой множитель считается равным 1.
+func (z *Zone) Validate() error {
+	if strings.TrimSpace(z.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrValidation)
+	}
+	if z.MinNoticeHours < 0 {
+		return fmt.Errorf("%w: min_notice_hours must not be negative", ErrValidation)
+	}
+	if z.PriceMultiplier == 0 {
+		z.PriceMultiplier = 1
+	}
+	if z.PriceMultiplier < 0 {
+		return fmt.Errorf("%w: price_multiplier must be positive", ErrValidation)
+	}
+	return z.Area.Validate()
+}
+
+// Validate проверяет, что полигон — корректный GeoJSON: замкнутые кольца минимум из 4 позиций.
+func (p GeoPolygon) Validate() error {
+	if p.Type != "Polygon" {
+		return fmt.Errorf("%w: area.type must be Polygon", ErrValidation)
+	}
+	if len(p.Coordinates) == 0 {
+		return fmt.Errorf("%w: area has no rings", ErrValidation)
+	}
+	for _, ring := range p.Coordinates {
+		if len(ring) < 4 {
+			return fmt.Errorf("%w: polygon ring needs at least 4 positions", ErrValidation)
+		}
+		for _, pos := range ring {
+			if len(pos) != 2 || pos[0] < -180 || pos[0] > 180 || pos[1] < -90 || pos[1] > 90 {
+				return fmt.Errorf("%w: positions must be [lng, lat]", ErrValidation)
+			}
+		}
+		first, last := ring[0], ring[len(ring)-1]
+		if first[0] != last[0] || first[1] != last[1] {
+			return fmt.Errorf("%w: polygon ring must be closed", ErrValidation)
+		}
+	}
+	return nil
+}
+
+// CheckNotice проверяет минимальный срок заказа в зоне.
+func (z *Zone) CheckNotice(date, now time.Time) error {
+	if z.MinNoticeHours > 0 && date.Sub(now) < time.Duration(z.MinNoticeHours)*time.Hour {
+		return fmt.Errorf("%w: zone %s requires at least %dh notice", ErrValidation, z.Name, z.MinNoticeHours)
+	}
+	return nil
+}
+
+func (z *Zone) HasCleaner(cleanerID string) bool {
+	for _, id := range z.CleanerIDs {
+		if id == cleanerID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func square() GeoPolygon {
+	return GeoPolygon{Type: "Polygon", Coordinates: [][][]float64{{
+		{76.8, 43.2}, {77.0, 43.2}, {77.0, 43.3}, {76.8, 43.3}, {76.8, 43.2},
+	}}}
+}
+
+func TestZoneValidate(t *testing.T) {
+	z := Zone{Name: "Center", Area: square()}
+	if err := z.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if z.PriceMultiplier != 1 {
+		t.Errorf("default multiplier = %v, want 1", z.PriceMultiplier)
+	}
+
+	open := square()
+	open.Coordinates[0] = open.Coordinates[0][:4]
+	if err := (&Zone{Name: "Open", Area: open}).Validate(); !errors.Is(err, ErrValidation) {
+		t.Errorf("open ring error = %v, want ErrValidation", err)
+	}
+	if err := (&Zone{Name: "Point", Area: GeoPolygon{Type: "Point"}}).Validate(); !errors.Is(err, ErrValidation) {
+		t.Errorf("non-polygon error = %v, want ErrValidation", err)
+	}
+}
+
+func TestZoneCheckNotice(t *testing.T) {
+	now := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	z := Zone{Name: "Suburbs", MinNoticeHours: 12}
+	if err := z.CheckNotice(now.Add(6*time.Hour), now); !errors.Is(err, ErrValidation) {
+		t.Errorf("short notice error = %v, want ErrValidation", err)
+	}
+	if err := z.CheckNotice(now.Add(13*time.Hour), now); err != nil {
+		t.Errorf("enough notice: %v", err)
+	}
+}
+
+func TestBuild_ZoneMultiplier(t *testing.T) {
+	now := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC) // понедельник
+	services := []Service{{ID: "a", Name: "Basic", Price: 1000}}
+	b, err := PricingRules{ZoneMultiplier: 1.25}.Build(services, nil, now.Add(72*time.Hour), now)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if b.Total != 1250 {
+		t.Errorf("Total = %v, want 1250", b.Total)
+	}
+}
+
+func TestBuild_CheapZoneIsDiscount(t *testing.T) {
+	now := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC) // понедельник
+	services := []Service{{ID: "a", Name: "Basic", Price: 1000}}
+	b, err := PricingRules{ZoneMultiplier: 0.8}.Build(services, nil, now.Add(72*time.Hour), now)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if len(b.Surcharges) != 0 || len(b.Discounts) != 1 || b.Discounts[0].Code != DiscountZone || b.Discounts[0].Amount != 200 {
+		t.Errorf("surcharges %+v, discounts %+v; want one zone discount of 200", b.Surcharges, b.Discounts)
+	}
+	if b.Total != 800 {
+		t.Errorf("Total = %v, want 800", b.Total)
+	}
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type addressRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAddressRepository создаёт репозиторий сохранённых адресов клиентов.
+func NewAddressRepository(db *mongo.Database) *addressRepository {
+	return &addressRepository{collection: db.Collection("addresses")}
+}
+
+func (r *addressRepository) Create(ctx context.Context, addr *models.Address) error {
+	addr.ID = primitive.NewObjectID()
+	addr.CreatedAt = time.Now()
+	addr.UpdatedAt = addr.CreatedAt
+	_, err := r.collection.InsertOne(ctx, addr)
+	return err
+}
+
+func (r *addressRepository) Update(ctx context.Context, addr *models.Address) error {
+	addr.UpdatedAt = time.Now()
+	res, err := r.collection.ReplaceOne(ctx, bson.M{"_id": addr.ID, "deleted_at": bson.M{"$exists": false}}, addr)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return models.ErrAddressNotFound
+	}
+	return nil
+}
+
+// Delete помечает адрес удалённым, а не стирает: на него ссылаются address_id старых заказов и подписок.
+func (r *addressRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "deleted_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"deleted_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return models.ErrAddressNotFound
+	}
+	return nil
+}
+
+// GetByID возвращает адрес, в том числе удалённый.
+func (r *addressRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*models.Address, error) {
+	var addr models.Address
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&addr); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, models.ErrAddressNotFound
+		}
+		return nil, err
+	}
+	return &addr, nil
+}
+
+// ListByClient возвращает действующие адреса клиента, новые сверху.
+func (r *addressRepository) ListByClient(ctx context.Context, clientID string) ([]models.Address, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"client_id": clientID, "deleted_at": bson.M{"$exists": false}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	addresses := []models.Address{}
+	if err := cursor.All(ctx, &addresses); err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
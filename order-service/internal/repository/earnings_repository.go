@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type earningsRepository struct {
+	ledger     *mongo.Collection
+	statements *mongo.Collection
+}
+
+// NewEarningsRepository создаёт репозиторий книги заработка клинеров и ведомостей на выплату.
+func NewEarningsRepository(db *mongo.Database) *earningsRepository {
+	return &earningsRepository{
+		ledger:     db.Collection("earnings_ledger"),
+		statements: db.Collection("payout_statements"),
+	}
+}
+
+// EnsureIndexes: не больше одного действующего начисления клинеру по заказу и одной
+// записи на клинера по чаевым; выборки записей клинера и ведомости, ленты ведомостей.
+func (r *earningsRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.ledger.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "order_id", Value: 1}, {Key: "cleaner_id", Value: 1}, {Key: "kind", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"kind": models.LedgerEarning, "reversed": false,
+			}),
+		},
+		{
+			Keys: bson.D{{Key: "tip_id", Value: 1}, {Key: "cleaner_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"kind": models.LedgerTip,
+			}),
+		},
+		{Keys: bson.D{{Key: "cleaner_id", Value: 1}, {Key: "statement_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "statement_id", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.statements.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "cleaner_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+// Insert добавляет записи в книгу; повторное начисление по заказу или чаевым — ErrEarningsPosted.
+func (r *earningsRepository) Insert(ctx context.Context, entries ...*models.LedgerEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(entries))
+	for i, e := range entries {
+		if e.ID.IsZero() {
+			e.ID = primitive.NewObjectID()
+		}
+		docs[i] = e
+	}
+	_, err := r.ledger.InsertMany(ctx, docs)
+	if mongo.IsDuplicateKeyError(err) {
+		return models.ErrEarningsPosted
+	}
+	return err
+}
+
+// ActiveEarnings — несторнированные начисления по заказу.
+func (r *earningsRepository) ActiveEarnings(ctx context.Context, orderID string) ([]models.LedgerEntry, error) {
+	return r.find(ctx, bson.M{"order_id": orderID, "kind": models.LedgerEarning, "reversed": false})
+}
+
+// MarkReversed помечает запись сторнированной; false — её уже сторнировали.
+func (r *earningsRepository) MarkReversed(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	res, err := r.ledger.UpdateOne(ctx, bson.M{"_id": id, "reversed": false}, bson.M{"$set": bson.M{"reversed": true}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+// ListUnstated — записи, ещё не попавшие в ведомость, созданные до before;
+// пустой cleanerID — по всем клинерам.
+func (r *earningsRepository) ListUnstated(ctx context.Context, cleanerID string, before time.Time) ([]models.LedgerEntry, error) {
+	filter := bson.M{"statement_id": bson.M{"$exists": false}}
+	if cleanerID != "" {
+		filter["cleaner_id"] = cleanerID
+	}
+	if !before.IsZero() {
+		filter["created_at"] = bson.M{"$lt": before}
+	}
+	return r.find(ctx, filter)
+}
+
+func (r *earningsRepository) ListByStatement(ctx context.Context, statementID primitive.ObjectID) ([]models.LedgerEntry, error) {
+	return r.find(ctx, bson.M{"statement_id": statementID})
+}
+
+func (r *earningsRepository) find(ctx context.Context, filter bson.M) ([]models.LedgerEntry, error) {
+	cursor, err := r.ledger.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	entries := []models.LedgerEntry{}
+	err = cursor.All(ctx, &entries)
+	return entries, err
+}
+
+// CreateStatement сохраняет ведомость и привязывает к ней записи. Если часть записей
+// за это время попала в другую ведомость — ErrStatementsConflict (в транзакции всё откатится).
+func (r *earningsRepository) CreateStatement(ctx context.Context, st *models.PayoutStatement, entryIDs []primitive.ObjectID) error {
+	if st.ID.IsZero() {
+		st.ID = primitive.NewObjectID()
+	}
+	if _, err := r.statements.InsertOne(ctx, st); err != nil {
+		return err
+	}
+	res, err := r.ledger.UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": entryIDs}, "statement_id": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"statement_id": st.ID}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.ModifiedCount != int64(len(entryIDs)) {
+		return models.ErrStatementsConflict
+	}
+	return nil
+}
+
+func (r *earningsRepository) GetStatement(ctx context.Context, id primitive.ObjectID) (*models.PayoutStatement, error) {
+	var st models.PayoutStatement
+	err := r.statements.FindOne(ctx, bson.M{"_id": id}).Decode(&st)
+	if err == mongo.ErrNoDocuments {
+		return nil, models.ErrStatementNotFound
+	}
+	return &st, err
+}
+
+// ListStatements — ведомости, новые первыми; пустые cleanerID и status не фильтруют.
+func (r *earningsRepository) ListStatements(ctx context.Context, cleanerID string, status models.StatementStatus, limit int64) ([]models.PayoutStatement, error) {
+	filter := bson.M{}
+	if cleanerID != "" {
+		filter["cleaner_id"] = cleanerID
+	}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).SetLimit(limit)
+	cursor, err := r.statements.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	statements := []models.PayoutStatement{}
+	err = cursor.All(ctx, &statements)
+	return statements, err
+}
+
+// StatementTotals — суммы ведомостей клинера по статусам за всё время.
+func (r *earningsRepository) StatementTotals(ctx context.Context, cleanerID string) (map[models.StatementStatus]float64, error) {
+	cursor, err := r.statements.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"cleaner_id": cleanerID}},
+		{"$group": bson.M{"_id": "$status", "total": bson.M{"$sum": "$total"}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var out []struct {
+		Status models.StatementStatus `bson:"_id"`
+		Total  float64                `bson:"total"`
+	}
+	if err := cursor.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	totals := make(map[models.StatementStatus]float64, len(out))
+	for _, t := range out {
+		totals[t.Status] = t.Total
+	}
+	return totals, nil
+}
+
+// MarkStatementPaid переводит ожидающую ведомость в paid. Уже выплаченная — ErrStatementPaid.
+func (r *earningsRepository) MarkStatementPaid(ctx context.Context, st *models.PayoutStatement) error {
+	res, err := r.statements.UpdateOne(ctx,
+		bson.M{"_id": st.ID, "status": models.StatementPending},
+		bson.M{"$set": bson.M{
+			"status":      models.StatementPaid,
+			"paid_at":     st.PaidAt,
+			"paid_by":     st.PaidBy,
+			"payment_ref": st.PaymentRef,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return models.ErrStatementPaid
+	}
+	return nil
+}
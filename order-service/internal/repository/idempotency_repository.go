@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type idempotencyRepository struct {
+	collection *mongo.Collection
+}
+
+// NewIdempotencyRepository создаёт хранилище ответов по Idempotency-Key.
+func NewIdempotencyRepository(db *mongo.Database) *idempotencyRepository {
+	return &idempotencyRepository{collection: db.Collection("idempotency_keys")}
+}
+
+// EnsureIndexes: записи удаляются TTL-индексом по expires_at.
+func (r *idempotencyRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+// Begin пытается занять ключ под новый запрос. Если ключ уже есть, возвращает
+// существующую запись и false. Истёкшие записи (TTL-монитор удаляет их не сразу)
+// и брошенные in_progress с истёкшей арендой занимаются заново.
+func (r *idempotencyRepository) Begin(ctx context.Context, rec *models.IdempotencyRecord) (*models.IdempotencyRecord, bool, error) {
+	_, err := r.collection.InsertOne(ctx, rec)
+	if err == nil {
+		return rec, true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, false, err
+	}
+
+	now := rec.CreatedAt
+	res, err := r.collection.ReplaceOne(ctx, bson.M{
+		"_id": rec.Key,
+		"$or": []bson.M{
+			{"expires_at": bson.M{"$lte": now}},
+			{"status": models.IdempotencyInProgress, "locked_until": bson.M{"$lte": now}},
+		},
+	}, rec)
+	if err != nil {
+		return nil, false, err
+	}
+	if res.MatchedCount == 1 {
+		return rec, true, nil
+	}
+
+	var existing models.IdempotencyRecord
+	err = r.collection.FindOne(ctx, bson.M{"_id": rec.Key}).Decode(&existing)
+	if err == mongo.ErrNoDocuments {
+		// Запись успели удалить между вставкой и чтением — пробуем ещё раз.
+		return r.Begin(ctx, rec)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return &existing, false, nil
+}
+
+// Complete сохраняет ответ первого запроса для последующих повторов.
+func (r *idempotencyRepository) Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte, expiresAt time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": key, "status": models.IdempotencyInProgress}, bson.M{
+		"$set": bson.M{
+			"status":       models.IdempotencyCompleted,
+			"status_code":  statusCode,
+			"content_type": contentType,
+			"body":         body,
+			"expires_at":   expiresAt,
+		},
+	})
+	return err
+}
+
+// Release освобождает ключ, если запрос не удалось обработать, чтобы клиент мог повторить его.
+func (r *idempotencyRepository) Release(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": key, "status": models.IdempotencyInProgress})
+	return err
+}
@@ -10,34 +10,65 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type orderRepository struct {
 	collection *mongo.Collection
+	// legacyDuration — длительность старых заказов без end_date, как в orderInterval сервиса.
+	legacyDuration time.Duration
 }
 
-// NewOrderRepository создаёт репозиторий для заказов.
-func NewOrderRepository(db *mongo.Database) *orderRepository {
-	return &orderRepository{collection: db.Collection("orders")}
+// NewOrderRepository создаёт репозиторий для заказов. legacyDuration — сколько длится
+// заказ без end_date (DEFAULT_ORDER_DURATION_MINUTES).
+func NewOrderRepository(db *mongo.Database, legacyDuration time.Duration) *orderRepository {
+	return &orderRepository{collection: db.Collection("orders"), legacyDuration: legacyDuration}
 }
 
 func (r *orderRepository) Create(ctx context.Context, order *models.Order) error {
 	order.ID = primitive.NewObjectID()
 	order.CreatedAt = time.Now()
 	order.UpdatedAt = time.Now()
+	order.Version = 1
 	_, err := r.collection.InsertOne(ctx, order)
 	return err
 }
 
+// versionFilter — условие оптимистичной блокировки: документ не менялся с момента чтения.
+// У заказов, созданных до появления version, поля нет — они считаются версией 0.
+func versionFilter(id primitive.ObjectID, version int64) bson.M {
+	if version == 0 {
+		return bson.M{"_id": id, "version": bson.M{"$in": bson.A{0, nil}}}
+	}
+	return bson.M{"_id": id, "version": version}
+}
+
+// Update заменяет документ заказа целиком, если его version не изменилась с момента чтения,
+// и увеличивает version. Иначе — models.ErrVersionConflict, документ не трогается.
 func (r *orderRepository) Update(ctx context.Context, order *models.Order) error {
+	prevVersion, prevUpdatedAt := order.Version, order.UpdatedAt
+	order.Version++
 	order.UpdatedAt = time.Now()
-	_, err := r.collection.UpdateByID(ctx, order.ID, bson.M{"$set": order})
+	res, err := r.collection.ReplaceOne(ctx, versionFilter(order.ID, prevVersion), order)
+	if err == nil && res.MatchedCount == 0 {
+		err = models.ErrVersionConflict
+	}
+	if err != nil {
+		order.Version, order.UpdatedAt = prevVersion, prevUpdatedAt
+	}
 	return err
 }
 
-func (r *orderRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
-	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
-	return err
+// Delete удаляет заказ той версии, которую видел вызывающий.
+func (r *orderRepository) Delete(ctx context.Context, order *models.Order) error {
+	res, err := r.collection.DeleteOne(ctx, versionFilter(order.ID, order.Version))
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return models.ErrVersionConflict
+	}
+	return nil
 }
 
 func (r *orderRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*models.Order, error) {
@@ -76,123 +107,155 @@ func (r *orderRepository) Filter(ctx context.Context, filter bson.M) ([]models.O
 	return orders, err
 }
 
+// Search — страница заказов по фильтру в заданном порядке.
+func (r *orderRepository) Search(ctx context.Context, filter bson.M, sort bson.D, limit int64) ([]models.Order, error) {
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(sort).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	var orders []models.Order
+	err = cursor.All(ctx, &orders)
+	return orders, err
+}
+
+// EnsureIndexes создаёт индексы под списки заказов: keyset-пагинацию по полям сортировки,
+// частые фильтры и текстовый поиск по адресу и комментарию.
+func (r *orderRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "date", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "total_price", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "date", Value: -1}}},
+		{Keys: bson.D{{Key: "client_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "cleaner_id", Value: 1}, {Key: "date", Value: -1}}},
+		{Keys: bson.D{{Key: "service_ids", Value: 1}}},
+		{Keys: bson.D{{Key: "zone_id", Value: 1}}},
+		{Keys: bson.D{{Key: "address", Value: "text"}, {Key: "comment", Value: "text"}}},
+	})
+	return err
+}
+
 // -------------------- НОВЫЕ МЕТОДЫ --------------------
 
-// IsCleanerBusy проверяет, есть ли у данного клинера в указанную дату
-// активные заказы (status == assigned или status == pending, можно расширить).
-func (r *orderRepository) IsCleanerBusy(ctx context.Context, cleanerID string, date time.Time) (bool, error) {
-	// Будем считать, что «занят» означает: в массиве cleaner_id уже есть cleanerID
-	// и поле date ровно совпадает (точное время). При желании можно добавить
-	// диапазон +-час, но здесь проверяем простое совпадение.
+// overlapsFrom — условие «заказ заканчивается после from»; вместе с date < to даёт
+// пересечение с [from, to). Старый заказ без end_date длится legacyDuration от начала.
+func (r *orderRepository) overlapsFrom(from time.Time) []bson.M {
+	return []bson.M{
+		{"end_date": bson.M{"$gt": from}},
+		{"end_date": bson.M{"$exists": false}, "date": bson.M{"$gt": from.Add(-r.legacyDuration)}},
+	}
+}
+
+// FindCleanerConflict ищет активный заказ клинера, пересекающийся с интервалом [from, to).
+// Интервал уже должен включать буфер на дорогу. Возвращает nil, если конфликта нет.
+func (r *orderRepository) FindCleanerConflict(ctx context.Context, cleanerID string, from, to time.Time, excludeID primitive.ObjectID) (*models.Order, error) {
 	filter := bson.M{
+		"_id":        bson.M{"$ne": excludeID},
 		"cleaner_id": cleanerID,
-		"date":       date,
 		"status": bson.M{
-			"$in": []models.OrderStatus{models.StatusAssigned, models.StatusPending},
+			"$in": []models.OrderStatus{models.StatusAssigned, models.StatusInProgress},
 		},
+		"date": bson.M{"$lt": to},
+		"$or":  r.overlapsFrom(from),
+	}
+	var order models.Order
+	err := r.collection.FindOne(ctx, filter).Decode(&order)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
 	}
-	count, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	return count > 0, nil
+	return &order, nil
 }
 
-// AddCleanerToOrder добавляет одного клинера в массив поле cleaner_id,
-// предварительно проверив, что он не занят на эту дату.
-func (r *orderRepository) AddCleanerToOrder(ctx context.Context, orderID primitive.ObjectID, cleanerID string) error {
-	// Сначала получим заказ, чтобы узнать поле date.
-	order, err := r.GetByID(ctx, orderID)
-	if err != nil {
-		return err
+// FindCleanersOrdersInRange возвращает активные заказы указанных клинеров,
+// пересекающиеся с [from, to).
+func (r *orderRepository) FindCleanersOrdersInRange(ctx context.Context, cleanerIDs []string, from, to time.Time) ([]models.Order, error) {
+	filter := bson.M{
+		"cleaner_id": bson.M{"$in": cleanerIDs},
+		"status": bson.M{
+			"$in": []models.OrderStatus{models.StatusAssigned, models.StatusInProgress},
+		},
+		"date": bson.M{"$lt": to},
+		"$or":  r.overlapsFrom(from),
 	}
-	// Проверяем занятость:
-	busy, err := r.IsCleanerBusy(ctx, cleanerID, order.Date)
+	cursor, err := r.collection.Find(ctx, filter)
 	if err != nil {
-		return err
-	}
-	if busy {
-		return errors.New("selected cleaner is busy at this date")
-	}
-	// Уточним, не дублируется ли уже этот cleaner:
-	for _, existing := range order.CleanerID {
-		if existing == cleanerID {
-			return errors.New("cleaner already assigned to this order")
-		}
-	}
-	// Добавляем в массив (MongoDB $addToSet гарантирует уникальность).
-	update := bson.M{
-		"$addToSet": bson.M{"cleaner_id": cleanerID},
-		"$set":      bson.M{"status": models.StatusAssigned, "updated_at": time.Now()},
+		return nil, err
 	}
-	_, err = r.collection.UpdateByID(ctx, orderID, update)
-	return err
-}
-
-// RemoveCleanerFromOrder убирает одного клинера из массива cleaner_id.
-// Если после этого массив станет пустым, переводит status обратно в pending.
-func (r *orderRepository) RemoveCleanerFromOrder(ctx context.Context, id primitive.ObjectID, cleanerID string) error {
-	_, err := r.collection.UpdateOne(ctx,
-		bson.M{"_id": id},
-		bson.M{"$pull": bson.M{"cleaner_id": cleanerID}},
-	)
-	return err
+	var orders []models.Order
+	err = cursor.All(ctx, &orders)
+	return orders, err
 }
 
-// -------------------------------------------------------
-
-// UnassignCleaner раньше «удалял поле cleaner_id», теперь заменим его
-// на вызов RemoveCleanerFromOrder или, если у вас изначально ожидался
-// single-assignment, можно оставить для совместимости, но лучше
-// использовать новый метод RemoveCleanerFromOrder в сервисе.
-func (r *orderRepository) UnassignCleaner(ctx context.Context, id primitive.ObjectID) error {
-
-	update := bson.M{
-		"$unset": bson.M{"cleaner_id": ""},
-		"$set":   bson.M{"status": models.StatusPending, "updated_at": time.Now()},
+// FindAssignedInRange возвращает все активные заказы с назначенными клинерами,
+// пересекающиеся с [from, to).
+func (r *orderRepository) FindAssignedInRange(ctx context.Context, from, to time.Time) ([]models.Order, error) {
+	filter := bson.M{
+		"cleaner_id.0": bson.M{"$exists": true},
+		"status": bson.M{
+			"$in": []models.OrderStatus{models.StatusAssigned, models.StatusInProgress},
+		},
+		"date": bson.M{"$lt": to},
+		"$or":  r.overlapsFrom(from),
 	}
-	_, err := r.collection.UpdateByID(ctx, id, update)
-	return err
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var orders []models.Order
+	err = cursor.All(ctx, &orders)
+	return orders, err
 }
 
-func (r *orderRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, status models.OrderStatus) error {
-	_, err := r.collection.UpdateOne(ctx,
-		bson.M{"_id": id},
+// UpdateStatus меняет только статус, с той же проверкой version, что и Update.
+func (r *orderRepository) UpdateStatus(ctx context.Context, order *models.Order, status models.OrderStatus) error {
+	now := time.Now()
+	res, err := r.collection.UpdateOne(ctx,
+		versionFilter(order.ID, order.Version),
 		bson.M{
 			"$set": bson.M{
 				"status":     status,
-				"updated_at": time.Now(),
+				"updated_at": now,
+				"version":    order.Version + 1,
 			},
 		},
 	)
-	return err
-}
-
-func (r *orderRepository) SaveOrderReview(ctx context.Context, orderID primitive.ObjectID, rating int, comment string) error {
-
-	filter := bson.M{
-		"_id":    orderID,
-		"rating": bson.M{"$exists": false},
+	if err != nil {
+		return err
 	}
-
-	update := bson.M{
-		"$set": bson.M{
-			"rating":         rating,
-			"review_comment": comment,
-			"updated_at":     time.Now().UTC(),
-		},
+	if res.MatchedCount == 0 {
+		return models.ErrVersionConflict
 	}
+	order.Version++
+	order.UpdatedAt = now
+	return nil
+}
 
-	res, err := r.collection.UpdateOne(ctx, filter, update)
+// MarkRemindersSent добавляет метки отправленных напоминаний с той же проверкой version:
+// второй экземпляр планировщика с устаревшей копией получит конфликт и ничего не отправит.
+func (r *orderRepository) MarkRemindersSent(ctx context.Context, order *models.Order, keys []string) error {
+	now := time.Now()
+	res, err := r.collection.UpdateOne(ctx,
+		versionFilter(order.ID, order.Version),
+		bson.M{
+			"$addToSet": bson.M{"reminders_sent": bson.M{"$each": keys}},
+			"$set": bson.M{
+				"updated_at": now,
+				"version":    order.Version + 1,
+			},
+		},
+	)
 	if err != nil {
-
 		return err
 	}
-	if res.ModifiedCount == 0 {
-
-		return errors.New("order already reviewed")
+	if res.MatchedCount == 0 {
+		return models.ErrVersionConflict
 	}
-
+	order.RemindersSent = append(order.RemindersSent, keys...)
+	order.Version++
+	order.UpdatedAt = now
 	return nil
 }
 
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// matchesClause проверяет заказ по условию из overlapsFrom так же, как это сделал бы Mongo:
+// поддерживаются только $gt и $exists по date/end_date.
+func matchesClause(t *testing.T, clause bson.M, date time.Time, endDate *time.Time) bool {
+	t.Helper()
+	fields := map[string]*time.Time{"date": &date, "end_date": endDate}
+	for field, cond := range clause {
+		value := fields[field]
+		for op, arg := range cond.(bson.M) {
+			switch op {
+			case "$exists":
+				if (value != nil) != arg.(bool) {
+					return false
+				}
+			case "$gt":
+				if value == nil || !value.After(arg.(time.Time)) {
+					return false
+				}
+			default:
+				t.Fatalf("unexpected operator %s", op)
+			}
+		}
+	}
+	return true
+}
+
+func TestOverlapsFrom(t *testing.T) {
+	r := &orderRepository{legacyDuration: 2 * time.Hour}
+	from := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	end := func(d time.Time) *time.Time { return &d }
+
+	cases := []struct {
+		name    string
+		date    time.Time
+		endDate *time.Time
+		want    bool
+	}{
+		{"ends inside", from.Add(-time.Hour), end(from.Add(30 * time.Minute)), true},
+		{"ends at from", from.Add(-time.Hour), end(from), false},
+		{"covers the range", from.Add(-time.Hour), end(to.Add(time.Hour)), true},
+		{"starts at to", to, end(to.Add(time.Hour)), false},
+		// Без end_date заказ длится legacyDuration, как в orderInterval.
+		{"legacy started before from, still running", from.Add(-90 * time.Minute), nil, true},
+		{"legacy finished exactly at from", from.Add(-2 * time.Hour), nil, false},
+		{"legacy starts inside", from.Add(30 * time.Minute), nil, true},
+		{"legacy starts at to", to, nil, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := false
+			if tc.date.Before(to) {
+				for _, clause := range r.overlapsFrom(from) {
+					if matchesClause(t, clause, tc.date, tc.endDate) {
+						got = true
+					}
+				}
+			}
+			if got != tc.want {
+				t.Errorf("overlaps = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// deliveredRetention — сколько хранятся доставленные события (TTL-индекс).
+const deliveredRetention = 7 * 24 * time.Hour
+
+type outboxRepository struct {
+	collection *mongo.Collection
+}
+
+// NewOutboxRepository создаёт репозиторий исходящих событий (outbox).
+func NewOutboxRepository(db *mongo.Database) *outboxRepository {
+	return &outboxRepository{collection: db.Collection("outbox")}
+}
+
+// EnsureIndexes: уникальный ключ идемпотентности, выборка relay по статусу и времени,
+// TTL для доставленных событий.
+func (r *outboxRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "idempotency_key", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "available_at", Value: 1}}},
+		{Keys: bson.D{{Key: "delivered_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(deliveredRetention.Seconds()))},
+	})
+	return err
+}
+
+// Insert пишет события; при вызове внутри транзакции — в её рамках.
+func (r *outboxRepository) Insert(ctx context.Context, events ...*models.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(events))
+	for i, e := range events {
+		docs[i] = e
+	}
+	_, err := r.collection.InsertMany(ctx, docs)
+	return err
+}
+
+// ClaimDue захватывает до limit готовых к доставке событий на время lease, чтобы
+// несколько экземпляров relay не отправляли одно событие одновременно.
+func (r *outboxRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutboxEvent, error) {
+	var claimed []models.OutboxEvent
+	lockedUntil := now.Add(lease)
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "available_at", Value: 1}}).
+		SetReturnDocument(options.After)
+	for len(claimed) < limit {
+		var e models.OutboxEvent
+		err := r.collection.FindOneAndUpdate(ctx, bson.M{
+			"status":       models.OutboxPending,
+			"available_at": bson.M{"$lte": now},
+			"$or": []bson.M{
+				{"locked_until": bson.M{"$exists": false}},
+				{"locked_until": bson.M{"$lte": now}},
+			},
+		}, bson.M{"$set": bson.M{"locked_until": lockedUntil}}, opts).Decode(&e)
+		if err == mongo.ErrNoDocuments {
+			break
+		}
+		if err != nil {
+			return claimed, err
+		}
+		claimed = append(claimed, e)
+	}
+	return claimed, nil
+}
+
+func (r *outboxRepository) MarkDelivered(ctx context.Context, e *models.OutboxEvent, at time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": e.ID}, bson.M{
+		"$set":   bson.M{"status": models.OutboxDelivered, "delivered_at": at, "attempts": e.Attempts + 1},
+		"$unset": bson.M{"locked_until": "", "last_error": ""},
+	})
+	return err
+}
+
+// SaveAttempt сохраняет результат неудачной попытки (см. OutboxEvent.MarkFailed).
+func (r *outboxRepository) SaveAttempt(ctx context.Context, e *models.OutboxEvent) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": e.ID}, bson.M{
+		"$set": bson.M{
+			"status":       e.Status,
+			"attempts":     e.Attempts,
+			"available_at": e.AvailableAt,
+			"last_error":   e.LastError,
+		},
+		"$unset": bson.M{"locked_until": ""},
+	})
+	return err
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type promoRepository struct {
+	codes       *mongo.Collection
+	redemptions *mongo.Collection
+	// firstOrders — по документу на клиента (_id = client_id), уже применившего код «на первый заказ».
+	firstOrders *mongo.Collection
+}
+
+// NewPromoRepository создаёт репозиторий промокодов и их использований.
+func NewPromoRepository(db *mongo.Database) *promoRepository {
+	return &promoRepository{
+		codes:       db.Collection("promo_codes"),
+		redemptions: db.Collection("promo_redemptions"),
+		firstOrders: db.Collection("promo_first_orders"),
+	}
+}
+
+// EnsureIndexes создаёт уникальный индекс по коду: два параллельных создания одного кода
+// не пройдут проверку в сервисе одновременно.
+func (r *promoRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.codes.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func (r *promoRepository) Create(ctx context.Context, code *models.PromoCode) error {
+	code.ID = primitive.NewObjectID()
+	code.CreatedAt = time.Now()
+	code.UpdatedAt = code.CreatedAt
+	code.Redemptions = 0
+	code.Usage = nil
+	_, err := r.codes.InsertOne(ctx, code)
+	if mongo.IsDuplicateKeyError(err) {
+		return models.ErrPromoDuplicate
+	}
+	return err
+}
+
+// Update меняет настройки кода; счётчики использований не трогает.
+func (r *promoRepository) Update(ctx context.Context, code *models.PromoCode) error {
+	code.UpdatedAt = time.Now()
+	res, err := r.codes.UpdateByID(ctx, code.ID, bson.M{"$set": bson.M{
+		"code":             code.Code,
+		"description":      code.Description,
+		"type":             code.Type,
+		"value":            code.Value,
+		"max_discount":     code.MaxDiscount,
+		"valid_from":       code.ValidFrom,
+		"valid_to":         code.ValidTo,
+		"global_limit":     code.GlobalLimit,
+		"per_user_limit":   code.PerUserLimit,
+		"first_order_only": code.FirstOrderOnly,
+		"service_ids":      code.ServiceIDs,
+		"active":           code.Active,
+		"updated_at":       code.UpdatedAt,
+	}})
+	if mongo.IsDuplicateKeyError(err) {
+		return models.ErrPromoDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return models.ErrPromoNotFound
+	}
+	return nil
+}
+
+func (r *promoRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	res, err := r.codes.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return models.ErrPromoNotFound
+	}
+	return nil
+}
+
+func (r *promoRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*models.PromoCode, error) {
+	var code models.PromoCode
+	if err := r.codes.FindOne(ctx, bson.M{"_id": id}).Decode(&code); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, models.ErrPromoNotFound
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (r *promoRepository) GetByCode(ctx context.Context, code string) (*models.PromoCode, error) {
+	var promo models.PromoCode
+	if err := r.codes.FindOne(ctx, bson.M{"code": code}).Decode(&promo); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, models.ErrPromoNotFound
+		}
+		return nil, err
+	}
+	return &promo, nil
+}
+
+func (r *promoRepository) List(ctx context.Context) ([]models.PromoCode, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.codes.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	codes := []models.PromoCode{}
+	err = cursor.All(ctx, &codes)
+	return codes, err
+}
+
+// Reserve атомарно увеличивает общий и персональный счётчики, только если ни один
+// лимит не исчерпан. Проверка и инкремент — одно обновление документа, поэтому
+// конкурентные запросы не могут превысить лимит.
+func (r *promoRepository) Reserve(ctx context.Context, id primitive.ObjectID, userID string) error {
+	usageField := "usage." + userID
+	filter := bson.M{
+		"_id":    id,
+		"active": true,
+		"$expr": bson.M{"$and": bson.A{
+			bson.M{"$or": bson.A{
+				bson.M{"$eq": bson.A{"$global_limit", 0}},
+				bson.M{"$lt": bson.A{"$redemption_count", "$global_limit"}},
+			}},
+			bson.M{"$or": bson.A{
+				bson.M{"$eq": bson.A{"$per_user_limit", 0}},
+				bson.M{"$lt": bson.A{bson.M{"$ifNull": bson.A{"$" + usageField, 0}}, "$per_user_limit"}},
+			}},
+		}},
+	}
+	res, err := r.codes.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"redemption_count": 1, usageField: 1}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return models.ErrPromoExhausted
+	}
+	return nil
+}
+
+// Release возвращает резерв, если заказ так и не был создан.
+func (r *promoRepository) Release(ctx context.Context, id primitive.ObjectID, userID string) error {
+	_, err := r.codes.UpdateByID(ctx, id, bson.M{"$inc": bson.M{"redemption_count": -1, "usage." + userID: -1}})
+	return err
+}
+
+// ClaimFirstOrder закрепляет за клиентом использование кода «на первый заказ».
+// Вставка по _id = client_id атомарна: из параллельных заказов клиента пройдёт один.
+func (r *promoRepository) ClaimFirstOrder(ctx context.Context, clientID string, codeID primitive.ObjectID) error {
+	_, err := r.firstOrders.InsertOne(ctx, bson.M{"_id": clientID, "code_id": codeID, "created_at": time.Now()})
+	if mongo.IsDuplicateKeyError(err) {
+		return models.ErrFirstOrderPromoUsed
+	}
+	return err
+}
+
+// ReleaseFirstOrder снимает закрепление, если заказ так и не был создан.
+func (r *promoRepository) ReleaseFirstOrder(ctx context.Context, clientID string) error {
+	_, err := r.firstOrders.DeleteOne(ctx, bson.M{"_id": clientID})
+	return err
+}
+
+func (r *promoRepository) AddRedemption(ctx context.Context, redemption *models.PromoRedemption) error {
+	redemption.ID = primitive.NewObjectID()
+	redemption.CreatedAt = time.Now()
+	_, err := r.redemptions.InsertOne(ctx, redemption)
+	return err
+}
+
+// Stats агрегирует использования по кодам; codeID == nil — по всем кодам.
+func (r *promoRepository) Stats(ctx context.Context, codeID *primitive.ObjectID) ([]models.PromoStats, error) {
+	match := bson.M{}
+	if codeID != nil {
+		match["code_id"] = *codeID
+	}
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$group": bson.M{
+			"_id":              "$code_id",
+			"code":             bson.M{"$last": "$code"},
+			"redemptions":      bson.M{"$sum": 1},
+			"users":            bson.M{"$addToSet": "$user_id"},
+			"total_discount":   bson.M{"$sum": "$discount"},
+			"last_redeemed_at": bson.M{"$max": "$created_at"},
+		}},
+		{"$addFields": bson.M{"unique_users": bson.M{"$size": "$users"}}},
+		{"$project": bson.M{"users": 0}},
+		{"$sort": bson.M{"redemptions": -1}},
+	}
+	cursor, err := r.redemptions.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	stats := []models.PromoStats{}
+	err = cursor.All(ctx, &stats)
+	return stats, err
+}
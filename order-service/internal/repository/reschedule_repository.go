@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type rescheduleRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRescheduleRepository создаёт репозиторий запросов на перенос заказов.
+func NewRescheduleRepository(db *mongo.Database) *rescheduleRepository {
+	return &rescheduleRepository{collection: db.Collection("reschedule_requests")}
+}
+
+func (r *rescheduleRepository) Create(ctx context.Context, req *models.RescheduleRequest) error {
+	req.ID = primitive.NewObjectID()
+	req.CreatedAt = time.Now()
+	_, err := r.collection.InsertOne(ctx, req)
+	return err
+}
+
+func (r *rescheduleRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*models.RescheduleRequest, error) {
+	var req models.RescheduleRequest
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// AddResponse добавляет ответ клинера, только если запрос ещё ожидает ответов и этот
+// клинер не отвечал. false — запрос уже разрешён или ответ клинера уже записан.
+func (r *rescheduleRepository) AddResponse(ctx context.Context, id primitive.ObjectID, resp models.RescheduleResponse) (bool, error) {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.ReschedulePending, "responses.cleaner_id": bson.M{"$ne": resp.CleanerID}},
+		bson.M{"$push": bson.M{"responses": resp}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+// Resolve записывает итог запроса, если он ещё ожидает ответов. false — запрос
+// уже разрешил параллельный ответ другого клинера.
+func (r *rescheduleRepository) Resolve(ctx context.Context, req *models.RescheduleRequest) (bool, error) {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": req.ID, "status": models.ReschedulePending},
+		bson.M{"$set": bson.M{
+			"status":      req.Status,
+			"chosen_slot": req.ChosenSlot,
+			"resolved_at": req.ResolvedAt,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+// GetLatestByOrderID возвращает последний запрос на перенос по заказу.
+func (r *rescheduleRepository) GetLatestByOrderID(ctx context.Context, orderID primitive.ObjectID) (*models.RescheduleRequest, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	var req models.RescheduleRequest
+	if err := r.collection.FindOne(ctx, bson.M{"order_id": orderID}, opts).Decode(&req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
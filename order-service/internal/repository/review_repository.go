@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type reviewRepository struct {
+	collection *mongo.Collection
+}
+
+// NewReviewRepository создаёт репозиторий отзывов о клинерах.
+func NewReviewRepository(db *mongo.Database) *reviewRepository {
+	return &reviewRepository{collection: db.Collection("reviews")}
+}
+
+// EnsureIndexes: один отзыв на пару заказ + клинер, лента отзывов клинера по убыванию _id.
+func (r *reviewRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "order_id", Value: 1}, {Key: "cleaner_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "cleaner_id", Value: 1}, {Key: "hidden", Value: 1}, {Key: "_id", Value: -1}}},
+	})
+	return err
+}
+
+// CreateMany сохраняет отзывы; повторный отзыв на того же клинера по заказу — ErrAlreadyReviewed.
+func (r *reviewRepository) CreateMany(ctx context.Context, reviews []*models.Review) error {
+	docs := make([]interface{}, len(reviews))
+	for i, rv := range reviews {
+		if rv.ID.IsZero() {
+			rv.ID = primitive.NewObjectID()
+		}
+		docs[i] = rv
+	}
+	_, err := r.collection.InsertMany(ctx, docs)
+	if mongo.IsDuplicateKeyError(err) {
+		return models.ErrAlreadyReviewed
+	}
+	return err
+}
+
+func (r *reviewRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*models.Review, error) {
+	var rv models.Review
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&rv)
+	if err == mongo.ErrNoDocuments {
+		return nil, models.ErrReviewNotFound
+	}
+	return &rv, err
+}
+
+func (r *reviewRepository) ListByOrder(ctx context.Context, orderID primitive.ObjectID) ([]models.Review, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"order_id": orderID}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	reviews := []models.Review{}
+	err = cursor.All(ctx, &reviews)
+	return reviews, err
+}
+
+// ListByCleaner отдаёт до limit отзывов клинера, новые первыми, начиная после отзыва before.
+func (r *reviewRepository) ListByCleaner(ctx context.Context, cleanerID string, includeHidden bool, before primitive.ObjectID, limit int64) ([]models.Review, error) {
+	filter := bson.M{"cleaner_id": cleanerID}
+	if !includeHidden {
+		filter["hidden"] = false
+	}
+	if !before.IsZero() {
+		filter["_id"] = bson.M{"$lt": before}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit)
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	reviews := []models.Review{}
+	err = cursor.All(ctx, &reviews)
+	return reviews, err
+}
+
+// SetReply сохраняет ответ клинера на отзыв.
+func (r *reviewRepository) SetReply(ctx context.Context, id primitive.ObjectID, reply *models.ReviewReply) error {
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"reply": reply, "updated_at": reply.UpdatedAt},
+	})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return models.ErrReviewNotFound
+	}
+	return nil
+}
+
+// SetModeration сохраняет решение модератора и дублирует флаги в корень документа для фильтров.
+func (r *reviewRepository) SetModeration(ctx context.Context, id primitive.ObjectID, m *models.ReviewModeration) error {
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"hidden":     m.Hidden,
+			"flagged":    m.Flagged,
+			"moderation": m,
+			"updated_at": m.ModeratedAt,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return models.ErrReviewNotFound
+	}
+	return nil
+}
+
+// CleanerRating пересчитывает рейтинг клинера по всем видимым отзывам.
+func (r *reviewRepository) CleanerRating(ctx context.Context, cleanerID string) (*models.CleanerRating, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"cleaner_id": cleanerID, "hidden": false}},
+		{"$group": bson.M{
+			"_id":         "$cleaner_id",
+			"count":       bson.M{"$sum": 1},
+			"average":     bson.M{"$avg": "$overall"},
+			"quality":     bson.M{"$avg": "$ratings.quality"},
+			"punctuality": bson.M{"$avg": "$ratings.punctuality"},
+			"politeness":  bson.M{"$avg": "$ratings.politeness"},
+		}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rating := &models.CleanerRating{CleanerID: cleanerID}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(rating); err != nil {
+			return nil, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	rating.Round()
+	return rating, nil
+}
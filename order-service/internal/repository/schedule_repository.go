@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type scheduleRepository struct {
+	collection *mongo.Collection
+}
+
+// NewScheduleRepository создаёт репозиторий графиков работы клинеров.
+func NewScheduleRepository(db *mongo.Database) *scheduleRepository {
+	return &scheduleRepository{collection: db.Collection("cleaner_schedules")}
+}
+
+// Upsert сохраняет график клинера (один документ на cleaner_id).
+func (r *scheduleRepository) Upsert(ctx context.Context, schedule *models.CleanerSchedule) error {
+	schedule.UpdatedAt = time.Now()
+	update := bson.M{"$set": bson.M{
+		"cleaner_id":   schedule.CleanerID,
+		"weekly_hours": schedule.WeeklyHours,
+		"days_off":     schedule.DaysOff,
+		"unavailable":  schedule.Unavailable,
+		"updated_at":   schedule.UpdatedAt,
+	}}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"cleaner_id": schedule.CleanerID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *scheduleRepository) GetByCleanerID(ctx context.Context, cleanerID string) (*models.CleanerSchedule, error) {
+	var schedule models.CleanerSchedule
+	err := r.collection.FindOne(ctx, bson.M{"cleaner_id": cleanerID}).Decode(&schedule)
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// Find возвращает графики указанных клинеров, а при пустом списке — все.
+func (r *scheduleRepository) Find(ctx context.Context, cleanerIDs []string) ([]models.CleanerSchedule, error) {
+	filter := bson.M{}
+	if len(cleanerIDs) > 0 {
+		filter["cleaner_id"] = bson.M{"$in": cleanerIDs}
+	}
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var schedules []models.CleanerSchedule
+	err = cursor.All(ctx, &schedules)
+	return schedules, err
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type statusHistoryRepository struct {
+	collection *mongo.Collection
+}
+
+// NewStatusHistoryRepository создаёт репозиторий истории статусов заказов.
+func NewStatusHistoryRepository(db *mongo.Database) *statusHistoryRepository {
+	return &statusHistoryRepository{collection: db.Collection("order_status_history")}
+}
+
+// EnsureIndexes создаёт индекс для выборки истории заказа и поиска переходов
+// в статус (в том числе из $lookup аналитики).
+func (r *statusHistoryRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "order_id", Value: 1}, {Key: "to", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	return err
+}
+
+func (r *statusHistoryRepository) Create(ctx context.Context, entry *models.StatusHistoryEntry) error {
+	entry.ID = primitive.NewObjectID()
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+// GetByOrderID возвращает историю заказа в хронологическом порядке.
+func (r *statusHistoryRepository) GetByOrderID(ctx context.Context, orderID primitive.ObjectID) ([]models.StatusHistoryEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"order_id": orderID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	entries := []models.StatusHistoryEntry{}
+	err = cursor.All(ctx, &entries)
+	return entries, err
+}
+
+// LastTransitionTo возвращает последнюю запись, в которой заказ перешёл в статус to.
+func (r *statusHistoryRepository) LastTransitionTo(ctx context.Context, orderID primitive.ObjectID, to models.OrderStatus) (*models.StatusHistoryEntry, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	var entry models.StatusHistoryEntry
+	err := r.collection.FindOne(ctx, bson.M{"order_id": orderID, "to": to}, opts).Decode(&entry)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
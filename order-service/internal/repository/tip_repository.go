@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type tipRepository struct {
+	collection *mongo.Collection
+}
+
+// NewTipRepository создаёт репозиторий чаевых клиентов.
+func NewTipRepository(db *mongo.Database) *tipRepository {
+	return &tipRepository{collection: db.Collection("tips")}
+}
+
+// EnsureIndexes: чаевые по заказу.
+func (r *tipRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "order_id", Value: 1}, {Key: "created_at", Value: 1}},
+	})
+	return err
+}
+
+func (r *tipRepository) Create(ctx context.Context, tip *models.Tip) error {
+	if tip.ID.IsZero() {
+		tip.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, tip)
+	return err
+}
+
+func (r *tipRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*models.Tip, error) {
+	var tip models.Tip
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&tip)
+	if err == mongo.ErrNoDocuments {
+		return nil, models.ErrTipNotFound
+	}
+	return &tip, err
+}
+
+func (r *tipRepository) ListByOrder(ctx context.Context, orderID string) ([]models.Tip, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"order_id": orderID}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	tips := []models.Tip{}
+	err = cursor.All(ctx, &tips)
+	return tips, err
+}
+
+// MarkPaid переводит ожидающие чаевые в paid; false — они уже не в статусе pending.
+func (r *tipRepository) MarkPaid(ctx context.Context, id primitive.ObjectID, paidAt time.Time) (bool, error) {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.TipPending},
+		bson.M{"$set": bson.M{"status": models.TipPaid, "paid_at": paidAt}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+// MarkFailed отмечает неуспешный платёж по ожидающим чаевым.
+func (r *tipRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, reason string) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.TipPending},
+		bson.M{"$set": bson.M{"status": models.TipFailed, "failure_reason": reason}},
+	)
+	return err
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// txRunner выполняет функцию в транзакции MongoDB. Транзакции доступны только на
+// replica set / sharded cluster; на одиночном mongod функция выполняется без неё.
+type txRunner struct {
+	db        *mongo.Database
+	mu        sync.Mutex
+	detected  bool
+	supported bool
+}
+
+// NewTxRunner создаёт исполнитель транзакций поверх клиента базы db.
+func NewTxRunner(db *mongo.Database) *txRunner {
+	return &txRunner{db: db}
+}
+
+// Detect определяет тип развёртывания MongoDB. Результат запоминается только при
+// успехе: после временной ошибки следующий вызов WithTx спросит сервер снова.
+func (r *txRunner) Detect(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.detected {
+		return nil
+	}
+	var hello bson.M
+	if err := r.db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return fmt.Errorf("detect MongoDB deployment type: %w", err)
+	}
+	_, replicaSet := hello["setName"]
+	r.supported = replicaSet || hello["msg"] == "isdbgrid"
+	r.detected = true
+	if !r.supported {
+		log.Println("[TX] Standalone MongoDB: multi-document writes run without transactions")
+	}
+	return nil
+}
+
+// WithTx вызывает fn с контекстом сессии: все операции репозиториев с этим ctx
+// попадают в одну транзакцию и откатываются, если fn вернула ошибку. Вложенный
+// вызов внутри уже открытой транзакции просто выполняется в ней.
+func (r *txRunner) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Без известного типа развёртывания не пишем молча без транзакции — возвращаем ошибку.
+	if err := r.Detect(ctx); err != nil {
+		return err
+	}
+	if !r.supported || mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+	session, err := r.db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
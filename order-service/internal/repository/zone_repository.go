@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type zoneRepository struct {
+	collection *mongo.Collection
+}
+
+// NewZoneRepository создаёт репозиторий зон обслуживания.
+func NewZoneRepository(db *mongo.Database) *zoneRepository {
+	return &zoneRepository{collection: db.Collection("zones")}
+}
+
+// EnsureIndexes создаёт 2dsphere-индекс по полигону зоны — без него $geoIntersects
+// работает полным перебором.
+func (r *zoneRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "area", Value: "2dsphere"}},
+	})
+	return err
+}
+
+func (r *zoneRepository) Create(ctx context.Context, zone *models.Zone) error {
+	zone.ID = primitive.NewObjectID()
+	zone.CreatedAt = time.Now()
+	zone.UpdatedAt = zone.CreatedAt
+	_, err := r.collection.InsertOne(ctx, zone)
+	return err
+}
+
+func (r *zoneRepository) Update(ctx context.Context, zone *models.Zone) error {
+	zone.UpdatedAt = time.Now()
+	res, err := r.collection.ReplaceOne(ctx, bson.M{"_id": zone.ID}, zone)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return models.ErrZoneNotFound
+	}
+	return nil
+}
+
+func (r *zoneRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return models.ErrZoneNotFound
+	}
+	return nil
+}
+
+func (r *zoneRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*models.Zone, error) {
+	var zone models.Zone
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&zone); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, models.ErrZoneNotFound
+		}
+		return nil, err
+	}
+	return &zone, nil
+}
+
+func (r *zoneRepository) List(ctx context.Context) ([]models.Zone, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	zones := []models.Zone{}
+	if err := cursor.All(ctx, &zones); err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// CountActive — сколько зон включено; если ни одной, приём заказов по зонам не ограничивается.
+func (r *zoneRepository) CountActive(ctx context.Context) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"active": true})
+}
+
+// FindActiveByPoint возвращает активные зоны, содержащие точку (при пересечении — по имени).
+func (r *zoneRepository) FindActiveByPoint(ctx context.Context, p models.GeoPoint) ([]models.Zone, error) {
+	filter := bson.M{
+		"active": true,
+		"area": bson.M{"$geoIntersects": bson.M{
+			"$geometry": bson.M{"type": "Point", "coordinates": []float64{p.Lng, p.Lat}},
+		}},
+	}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	zones := []models.Zone{}
+	if err := cursor.All(ctx, &zones); err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AddressRepository хранит сохранённые адреса клиентов (addresses).
+type AddressRepository interface {
+	Create(ctx context.Context, addr *models.Address) error
+	Update(ctx context.Context, addr *models.Address) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.Address, error)
+	ListByClient(ctx context.Context, clientID string) ([]models.Address, error)
+}
+
+func (s *orderService) ListAddresses(ctx context.Context, clientID string) ([]models.Address, error) {
+	return s.addresses.ListByClient(ctx, clientID)
+}
+
+// GetAddress — клиент видит свои адреса, персонал — любые.
+func (s *orderService) GetAddress(ctx context.Context, id primitive.ObjectID, userID, role string) (*models.Address, error) {
+	addr, err := s.addresses.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if role != "manager" && role != "admin" && addr.ClientID != userID {
+		return nil, models.ErrForbidden
+	}
+	return addr, nil
+}
+
+func (s *orderService) CreateAddress(ctx context.Context, clientID string, addr *models.Address) error {
+	if err := addr.Validate(); err != nil {
+		return err
+	}
+	addr.ClientID = clientID
+	addr.DeletedAt = nil
+	return s.addresses.Create(ctx, addr)
+}
+
+// UpdateAddress меняет сохранённый адрес. Уже созданные заказы хранят свою копию и не меняются.
+func (s *orderService) UpdateAddress(ctx context.Context, id primitive.ObjectID, clientID string, addr *models.Address) error {
+	existing, err := s.ownAddress(ctx, id, clientID)
+	if err != nil {
+		return err
+	}
+	if err := addr.Validate(); err != nil {
+		return err
+	}
+	addr.ID = existing.ID
+	addr.ClientID = existing.ClientID
+	addr.CreatedAt = existing.CreatedAt
+	addr.DeletedAt = nil
+	return s.addresses.Update(ctx, addr)
+}
+
+func (s *orderService) DeleteAddress(ctx context.Context, id primitive.ObjectID, clientID string) error {
+	if _, err := s.ownAddress(ctx, id, clientID); err != nil {
+		return err
+	}
+	return s.addresses.Delete(ctx, id)
+}
+
+func (s *orderService) ownAddress(ctx context.Context, id primitive.ObjectID, clientID string) (*models.Address, error) {
+	addr, err := s.addresses.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if addr.ClientID != clientID || addr.DeletedAt != nil {
+		return nil, models.ErrAddressNotFound
+	}
+	return addr, nil
+}
+
+// resolveAddress подставляет в заказ копию сохранённого адреса по AddressID:
+// строку для отображения, структурированные поля и координаты.
+// Заказы без AddressID (старые клиенты) остаются со свободной строкой.
+func (s *orderService) resolveAddress(ctx context.Context, order *models.Order) error {
+	if order.AddressID == "" {
+		order.AddressDetails = nil
+		return nil
+	}
+	id, err := primitive.ObjectIDFromHex(order.AddressID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid address_id", models.ErrValidation)
+	}
+	addr, err := s.addresses.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if addr.ClientID != order.ClientID {
+		return models.ErrForbidden
+	}
+	// Удалённый адрес остаётся только для истории — в новые заказы он не подставляется.
+	if addr.DeletedAt != nil {
+		return models.ErrAddressNotFound
+	}
+	snap := addr.Snapshot()
+	order.AddressDetails = snap
+	order.Address = snap.Line()
+	if snap.Location != nil {
+		loc := *snap.Location
+		order.Location = &loc
+	}
+	return nil
+}
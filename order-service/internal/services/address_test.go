@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestResolveAddressRejectsDeleted(t *testing.T) {
+	deletedAt := time.Now()
+	addr := &models.Address{
+		ID:              primitive.NewObjectID(),
+		ClientID:        "client",
+		AddressSnapshot: models.AddressSnapshot{City: "Москва", Street: "Тверская", Building: "1"},
+		DeletedAt:       &deletedAt,
+	}
+	svc := newTestService(newStubOrderRepo())
+	svc.addresses = stubAddressRepo{byID: map[primitive.ObjectID]*models.Address{addr.ID: addr}}
+
+	order := &models.Order{ClientID: "client", AddressID: addr.ID.Hex()}
+	if err := svc.resolveAddress(context.Background(), order); !errors.Is(err, models.ErrAddressNotFound) {
+		t.Fatalf("err = %v, want ErrAddressNotFound", err)
+	}
+}
+
+// Свободная строка вместо сохранённого адреса сбрасывает старые координаты и зону.
+func TestUpdateOrderFreeTextAddressClearsLocation(t *testing.T) {
+	zone := models.Zone{ID: primitive.NewObjectID(), Name: "center", Active: true, PriceMultiplier: 1}
+	order := &models.Order{
+		ID:             primitive.NewObjectID(),
+		ClientID:       "client",
+		Status:         models.StatusPaid,
+		Date:           time.Now().Add(72 * time.Hour),
+		Address:        "Москва, Тверская 1",
+		AddressID:      primitive.NewObjectID().Hex(),
+		AddressDetails: &models.AddressSnapshot{City: "Москва", Street: "Тверская", Building: "1"},
+		Location:       &models.GeoPoint{Lat: 55.75, Lng: 37.6},
+		ZoneID:         zone.ID.Hex(),
+		Version:        1,
+	}
+	repo := newStubOrderRepo(order)
+	svc := newTestService(repo)
+	svc.zones = stubZoneRepo{}
+
+	if err := svc.UpdateOrder(context.Background(), order.ID, &models.Order{Address: "Казань, Баумана 5"}); err != nil {
+		t.Fatalf("UpdateOrder: %v", err)
+	}
+	stored, _ := repo.GetByID(context.Background(), order.ID)
+	if stored.Location != nil || stored.ZoneID != "" || stored.AddressID != "" || stored.AddressDetails != nil {
+		t.Errorf("stale address data kept: location=%v zone=%q address_id=%q", stored.Location, stored.ZoneID, stored.AddressID)
+	}
+	if stored.Address != "Казань, Баумана 5" {
+		t.Errorf("address = %q", stored.Address)
+	}
+}
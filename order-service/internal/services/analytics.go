@@ -0,0 +1,296 @@
+package services
+
+import (
+	"context"
+	"sort"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// revenueMatch — оплаченные заказы и отменённые с удержанным штрафом, опционально с датой в [from, to).
+func revenueMatch(q *models.AnalyticsQuery) bson.M {
+	match := bson.M{"$or": bson.A{
+		bson.M{"status": bson.M{"$in": models.RevenueStatuses}},
+		bson.M{"status": models.StatusCancelled, "cancellation.fee": bson.M{"$gt": 0}},
+	}}
+	if q != nil {
+		match["date"] = bson.M{"$gte": q.From, "$lt": q.To}
+	}
+	return match
+}
+
+// netRevenueStage добавляет поля выручки заказа: net — total_price за вычетом возврата
+// по спору, а у отменённого — удержанный штраф; fee — этот штраф; paid — 1 для оплаченного
+// заказа и 0 для отменённого, чтобы штрафы не считались заказами в среднем чеке.
+var netRevenueStage = bson.M{"$addFields": bson.M{
+	"fee": bson.M{"$cond": bson.A{
+		bson.M{"$eq": bson.A{"$status", models.StatusCancelled}},
+		bson.M{"$ifNull": bson.A{"$cancellation.fee", 0}},
+		0,
+	}},
+	"paid": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", models.StatusCancelled}}, 0, 1}},
+	"net": bson.M{"$cond": bson.A{
+		bson.M{"$eq": bson.A{"$status", models.StatusCancelled}},
+		bson.M{"$ifNull": bson.A{"$cancellation.fee", 0}},
+		bson.M{"$subtract": bson.A{
+			bson.M{"$ifNull": bson.A{"$total_price", 0}},
+			bson.M{"$ifNull": bson.A{"$dispute.refund_amount", 0}},
+		}},
+	}},
+}}
+
+// subscriptionStages помечают заказы из подписки: они создаются в статусе prepaid,
+// который после назначения остаётся только в истории статусов.
+var subscriptionStages = []bson.M{
+	{"$lookup": bson.M{
+		"from": "order_status_history",
+		"let":  bson.M{"oid": "$_id"},
+		"pipeline": bson.A{
+			bson.M{"$match": bson.M{"$expr": bson.M{"$and": bson.A{
+				bson.M{"$eq": bson.A{"$order_id", "$$oid"}},
+				bson.M{"$eq": bson.A{"$to", models.StatusPrePaid}},
+			}}}},
+			bson.M{"$limit": 1},
+		},
+		"as": "prepaid_history",
+	}},
+	{"$addFields": bson.M{"from_subscription": bson.M{"$or": bson.A{
+		bson.M{"$eq": bson.A{"$status", models.StatusPrePaid}},
+		bson.M{"$gt": bson.A{bson.M{"$size": "$prepaid_history"}, 0}},
+	}}}},
+}
+
+var timeFormats = map[models.AnalyticsGroupBy]string{
+	models.GroupByDay:   "%Y-%m-%d",
+	models.GroupByWeek:  "%G-W%V",
+	models.GroupByMonth: "%Y-%m",
+}
+
+// bucketStages группирует оплаченные заказы по разрезу q.GroupBy.
+func bucketStages(q *models.AnalyticsQuery) []bson.M {
+	switch q.GroupBy {
+	case models.GroupByService:
+		// Выручка заказа делится между услугами пропорционально позициям расчёта;
+		// у старых заказов без расчёта — поровну.
+		return []bson.M{
+			{"$addFields": bson.M{"lines": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$pricing.items", bson.A{}}}}, 0}},
+				bson.M{"$map": bson.M{"input": "$pricing.items", "as": "i", "in": bson.M{"id": "$$i.service_id", "name": "$$i.name", "weight": "$$i.amount"}}},
+				bson.M{"$map": bson.M{"input": bson.M{"$ifNull": bson.A{"$service_ids", bson.A{}}}, "as": "id", "in": bson.M{"id": "$$id", "name": "", "weight": 1}}},
+			}}}},
+			{"$addFields": bson.M{"lines_total": bson.M{"$sum": "$lines.weight"}}},
+			{"$unwind": "$lines"},
+			{"$addFields": bson.M{"part": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{"$lines_total", 0}},
+				bson.M{"$divide": bson.A{"$lines.weight", "$lines_total"}},
+				0,
+			}}}},
+			{"$group": bson.M{
+				"_id":               "$lines.id",
+				"label":             bson.M{"$max": "$lines.name"},
+				"revenue":           bson.M{"$sum": bson.M{"$multiply": bson.A{"$net", "$part"}}},
+				"cancellation_fees": bson.M{"$sum": bson.M{"$multiply": bson.A{"$fee", "$part"}}},
+				"orders":            bson.M{"$sum": "$paid"},
+			}},
+		}
+	case models.GroupByCleaner:
+		// Выручка заказа делится между назначенными клинерами поровну.
+		return []bson.M{
+			{"$addFields": bson.M{"cleaners": bson.M{"$ifNull": bson.A{"$cleaner_id", bson.A{}}}}},
+			{"$addFields": bson.M{"part": bson.M{"$divide": bson.A{1, bson.M{"$max": bson.A{1, bson.M{"$size": "$cleaners"}}}}}}},
+			{"$unwind": bson.M{"path": "$cleaners", "preserveNullAndEmptyArrays": true}},
+			{"$group": bson.M{
+				"_id":               bson.M{"$ifNull": bson.A{"$cleaners", "unassigned"}},
+				"revenue":           bson.M{"$sum": bson.M{"$multiply": bson.A{"$net", "$part"}}},
+				"cancellation_fees": bson.M{"$sum": bson.M{"$multiply": bson.A{"$fee", "$part"}}},
+				"orders":            bson.M{"$sum": "$paid"},
+			}},
+		}
+	case models.GroupByZone:
+		return []bson.M{
+			{"$group": bson.M{
+				"_id":               bson.M{"$ifNull": bson.A{"$zone_id", ""}},
+				"revenue":           bson.M{"$sum": "$net"},
+				"cancellation_fees": bson.M{"$sum": "$fee"},
+				"orders":            bson.M{"$sum": "$paid"},
+			}},
+		}
+	}
+	return []bson.M{
+		{"$group": bson.M{
+			"_id":               bson.M{"$dateToString": bson.M{"format": timeFormats[q.GroupBy], "date": "$date", "timezone": q.Timezone}},
+			"revenue":           bson.M{"$sum": "$net"},
+			"cancellation_fees": bson.M{"$sum": "$fee"},
+			"orders":            bson.M{"$sum": "$paid"},
+		}},
+	}
+}
+
+// RevenueReport считает выручку, число заказов и средний чек за период в разрезе q.GroupBy.
+func (s *orderService) RevenueReport(ctx context.Context, q *models.AnalyticsQuery) (*models.RevenueReport, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	pipeline := []bson.M{
+		{"$match": revenueMatch(q)},
+		netRevenueStage,
+		{"$facet": bson.M{
+			"total": bson.A{bson.M{"$group": bson.M{
+				"_id":               "total",
+				"revenue":           bson.M{"$sum": "$net"},
+				"cancellation_fees": bson.M{"$sum": "$fee"},
+				"orders":            bson.M{"$sum": "$paid"},
+			}}},
+			"buckets": bucketStages(q),
+		}},
+	}
+	cursor, err := s.repo.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var out []struct {
+		Total   []models.RevenueBucket `bson:"total"`
+		Buckets []models.RevenueBucket `bson:"buckets"`
+	}
+	if err := cursor.All(ctx, &out); err != nil {
+		return nil, err
+	}
+
+	report := &models.RevenueReport{
+		From: q.From, To: q.To, GroupBy: q.GroupBy, Timezone: q.Timezone,
+		Total:   models.RevenueBucket{Key: "total"},
+		Buckets: []models.RevenueBucket{},
+	}
+	if len(out) > 0 {
+		if len(out[0].Total) > 0 {
+			report.Total = out[0].Total[0]
+		}
+		report.Buckets = append(report.Buckets, out[0].Buckets...)
+	}
+	report.Total.Finalize()
+	for i := range report.Buckets {
+		report.Buckets[i].Finalize()
+	}
+	if q.GroupBy == models.GroupByZone {
+		s.labelZones(ctx, report.Buckets)
+	}
+
+	if q.GroupBy.IsTimeSeries() {
+		sort.Slice(report.Buckets, func(i, j int) bool { return report.Buckets[i].Key < report.Buckets[j].Key })
+	} else {
+		sort.Slice(report.Buckets, func(i, j int) bool {
+			if report.Buckets[i].Revenue != report.Buckets[j].Revenue {
+				return report.Buckets[i].Revenue > report.Buckets[j].Revenue
+			}
+			return report.Buckets[i].Key < report.Buckets[j].Key
+		})
+	}
+	return report, nil
+}
+
+// labelZones подставляет названия зон; ошибка справочника не ломает отчёт.
+func (s *orderService) labelZones(ctx context.Context, buckets []models.RevenueBucket) {
+	zones, err := s.zones.List(ctx)
+	if err != nil {
+		return
+	}
+	names := make(map[string]string, len(zones))
+	for _, z := range zones {
+		names[z.ID.Hex()] = z.Name
+	}
+	for i := range buckets {
+		buckets[i].Label = names[buckets[i].Key]
+	}
+}
+
+// KPIReport считает сводные показатели за период: выручку и средний чек, долю
+// подписок, долю отмен и долю повторных клиентов.
+func (s *orderService) KPIReport(ctx context.Context, q *models.AnalyticsQuery) (*models.KPIReport, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	report := &models.KPIReport{From: q.From, To: q.To}
+
+	// 1. Выручка и доля подписок.
+	revenue := append([]bson.M{{"$match": revenueMatch(q)}, netRevenueStage}, subscriptionStages...)
+	revenue = append(revenue, bson.M{"$group": bson.M{
+		"_id":                  nil,
+		"revenue":              bson.M{"$sum": "$net"},
+		"cancellation_fees":    bson.M{"$sum": "$fee"},
+		"orders":               bson.M{"$sum": "$paid"},
+		"subscription_revenue": bson.M{"$sum": bson.M{"$cond": bson.A{"$from_subscription", "$net", 0}}},
+	}})
+	var totals []struct {
+		Revenue             float64 `bson:"revenue"`
+		CancellationFees    float64 `bson:"cancellation_fees"`
+		Orders              int64   `bson:"orders"`
+		SubscriptionRevenue float64 `bson:"subscription_revenue"`
+	}
+	if err := s.aggregateAll(ctx, revenue, &totals); err != nil {
+		return nil, err
+	}
+	if len(totals) > 0 {
+		report.Revenue, report.Orders, report.SubscriptionRevenue = totals[0].Revenue, totals[0].Orders, totals[0].SubscriptionRevenue
+		report.CancellationFees = totals[0].CancellationFees
+	}
+
+	// 2. Отмены среди всех заказов периода.
+	var cancels []struct {
+		Total     int64 `bson:"total"`
+		Cancelled int64 `bson:"cancelled"`
+	}
+	err := s.aggregateAll(ctx, []bson.M{
+		{"$match": bson.M{"date": bson.M{"$gte": q.From, "$lt": q.To}}},
+		{"$group": bson.M{
+			"_id":       nil,
+			"total":     bson.M{"$sum": 1},
+			"cancelled": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", models.StatusCancelled}}, 1, 0}}},
+		}},
+	}, &cancels)
+	if err != nil {
+		return nil, err
+	}
+	if len(cancels) > 0 {
+		report.TotalOrders, report.CancelledOrders = cancels[0].Total, cancels[0].Cancelled
+	}
+
+	// 3. Повторные клиенты: среди клиентов с оплаченными заказами в периоде — те,
+	// у кого к концу периода набралось два и больше оплаченных заказа.
+	var clients []struct {
+		Clients int64 `bson:"clients"`
+		Repeat  int64 `bson:"repeat"`
+	}
+	err = s.aggregateAll(ctx, []bson.M{
+		{"$match": bson.M{"status": bson.M{"$in": models.RevenueStatuses}, "date": bson.M{"$lt": q.To}}},
+		{"$group": bson.M{
+			"_id":       "$client_id",
+			"orders":    bson.M{"$sum": 1},
+			"in_period": bson.M{"$max": bson.M{"$gte": bson.A{"$date", q.From}}},
+		}},
+		{"$match": bson.M{"in_period": true}},
+		{"$group": bson.M{
+			"_id":     nil,
+			"clients": bson.M{"$sum": 1},
+			"repeat":  bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gte": bson.A{"$orders", 2}}, 1, 0}}},
+		}},
+	}, &clients)
+	if err != nil {
+		return nil, err
+	}
+	if len(clients) > 0 {
+		report.Clients, report.RepeatClients = clients[0].Clients, clients[0].Repeat
+	}
+
+	report.Finalize()
+	return report, nil
+}
+
+func (s *orderService) aggregateAll(ctx context.Context, pipeline []bson.M, out interface{}) error {
+	cursor, err := s.repo.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	return cursor.All(ctx, out)
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"cleaning-app/order-service/internal/config"
+	"cleaning-app/order-service/internal/models"
+	"cleaning-app/order-service/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Веса факторов ранжирования. Доступность — жёсткий фильтр, а не вес;
+// закреплённость за зоной заказа — приоритет перед оценкой.
+const (
+	weightRating   = 0.4
+	weightWorkload = 0.35
+	weightLevel    = 0.25
+
+	// Рейтинг для клинеров без отзывов, чтобы новички не оказывались в самом конце.
+	neutralRating  = 3.0
+	maxLevel       = 5
+	workloadWindow = 7 * 24 * time.Hour
+)
+
+// PreviewAutoAssign ранжирует клинеров для заказа, ничего не меняя.
+func (s *orderService) PreviewAutoAssign(ctx context.Context, id primitive.ObjectID) (*models.AssignmentPlan, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.rankCandidates(ctx, order)
+}
+
+// AutoAssign назначает клинеров на заказ. Если cleanerIDs переданы — это подтверждение
+// менеджером выбранных из превью кандидатов, иначе берутся лучшие по рейтингу.
+func (s *orderService) AutoAssign(ctx context.Context, id primitive.ObjectID, cleanerIDs []string) (*models.AssignmentPlan, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.rankCandidates(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	if len(cleanerIDs) > 0 {
+		plan.Selected = cleanerIDs
+	}
+	if len(plan.Selected) == 0 {
+		return plan, models.ErrNotEnoughCleaners
+	}
+	err = retryOnConflict(func() error { return s.assignCleaners(ctx, id, plan.Selected, true) })
+	if err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// rankCandidates считает оценку каждого клинера и выбирает нужное количество.
+func (s *orderService) rankCandidates(ctx context.Context, order *models.Order) (*models.AssignmentPlan, error) {
+	if order.Status != models.StatusAssigned {
+		if err := models.ValidateTransition(order.Status, models.StatusAssigned); err != nil {
+			return nil, err
+		}
+	}
+	required := order.CleanersRequired
+	if required < 1 {
+		required = 1
+	}
+	need := required - len(order.CleanerID)
+
+	cleaners, err := s.auth.GetCleaners(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cleaners: %w", err)
+	}
+	ids := make([]string, 0, len(cleaners))
+	for _, cl := range cleaners {
+		if !containsString(order.CleanerID, cl.ID) {
+			ids = append(ids, cl.ID)
+		}
+	}
+
+	plan := &models.AssignmentPlan{
+		OrderID:          order.ID.Hex(),
+		CleanersRequired: required,
+		Selected:         []string{},
+		Candidates:       []models.CandidateScore{},
+	}
+	if len(ids) == 0 || need <= 0 {
+		return plan, nil
+	}
+
+	// Уровень геймификации — не критичный фактор: при недоступности сервиса считаем всех на 1-м.
+	levels, err := utils.FetchGamificationLevels(ctx, s.cfg, ids)
+	if err != nil {
+		log.Printf("[AUTO-ASSIGN] Gamification levels unavailable: %v", err)
+		levels = map[string]int{}
+	}
+
+	zone := s.orderZone(ctx, order)
+
+	now := time.Now()
+	active, err := s.repo.FindCleanersOrdersInRange(ctx, ids, now, now.Add(workloadWindow))
+	if err != nil {
+		return nil, err
+	}
+	workload := make(map[string]int)
+	for _, o := range active {
+		for _, cid := range o.CleanerID {
+			workload[cid]++
+		}
+	}
+	unavailable, err := s.unavailableCleaners(ctx, order, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cl := range cleaners {
+		if !containsString(ids, cl.ID) {
+			continue
+		}
+		score := models.CandidateScore{CleanerID: cl.ID, AverageRating: cl.AverageRating, Available: true}
+		score.InZone = zone != nil && zone.HasCleaner(cl.ID)
+		if err, busy := unavailable[cl.ID]; busy {
+			score.Available, score.Reason = false, err.Error()
+		}
+
+		rating := cl.AverageRating
+		if cl.RatingCount == 0 {
+			rating = neutralRating
+		}
+		score.RatingScore = round3(rating / 5)
+		score.ActiveOrders = workload[cl.ID]
+		score.WorkloadScore = round3(1 / float64(1+score.ActiveOrders))
+		score.Level = levels[cl.ID]
+		if score.Level < 1 {
+			score.Level = 1
+		}
+		score.LevelScore = round3(math.Min(float64(score.Level), maxLevel) / maxLevel)
+		score.Total = round3(weightRating*score.RatingScore + weightWorkload*score.WorkloadScore + weightLevel*score.LevelScore)
+		plan.Candidates = append(plan.Candidates, score)
+	}
+
+	sort.SliceStable(plan.Candidates, func(i, j int) bool {
+		a, b := plan.Candidates[i], plan.Candidates[j]
+		if a.Available != b.Available {
+			return a.Available
+		}
+		// Клинеры зоны заказа идут первыми, оценка сравнивается внутри группы.
+		if a.InZone != b.InZone {
+			return a.InZone
+		}
+		return a.Total > b.Total
+	})
+	for _, c := range plan.Candidates {
+		if len(plan.Selected) == need || !c.Available {
+			break
+		}
+		plan.Selected = append(plan.Selected, c.CleanerID)
+	}
+	if len(plan.Selected) < need {
+		// Частичное назначение не делаем: лучше оставить заказ менеджеру целиком.
+		plan.Selected = []string{}
+	}
+	return plan, nil
+}
+
+// unavailableCleaners проверяет график и занятость всех кандидатов двумя запросами
+// вместо пары запросов на каждого: причина недоступности по id клинера.
+func (s *orderService) unavailableCleaners(ctx context.Context, order *models.Order, ids []string) (map[string]error, error) {
+	start, end := s.orderInterval(order)
+	reasons := make(map[string]error)
+
+	schedules, err := s.schedules.Find(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range schedules {
+		if err := schedules[i].Covers(start, end, s.location()); err != nil {
+			reasons[schedules[i].CleanerID] = err
+		}
+	}
+
+	buffer := time.Duration(s.cfg.TravelBufferMinutes) * time.Minute
+	busy, err := s.repo.FindCleanersOrdersInRange(ctx, ids, start.Add(-buffer), end.Add(buffer))
+	if err != nil {
+		return nil, err
+	}
+	for i := range busy {
+		if busy[i].ID == order.ID {
+			continue
+		}
+		for _, cid := range busy[i].CleanerID {
+			if _, seen := reasons[cid]; !seen && containsString(ids, cid) {
+				reasons[cid] = s.cleanerBusyError(cid, &busy[i])
+			}
+		}
+	}
+	return reasons, nil
+}
+
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+// AutoAssignJob периодически назначает клинеров на оплаченные заказы без исполнителей.
+// Проход выполняет только экземпляр, взявший блокировку в Redis.
+type AutoAssignJob struct {
+	service *orderService
+	cfg     *config.Config
+	lock    *jobLock
+}
+
+func NewAutoAssignJob(service *orderService, cfg *config.Config) *AutoAssignJob {
+	interval := time.Duration(cfg.AutoAssignIntervalMinutes) * time.Minute
+	return &AutoAssignJob{service: service, cfg: cfg, lock: newJobLock(service.redis, "auto-assign", interval)}
+}
+
+func (j *AutoAssignJob) Start(ctx context.Context) {
+	if j.cfg.AutoAssignIntervalMinutes <= 0 {
+		log.Println("[AUTO-ASSIGN] Background job disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(time.Duration(j.cfg.AutoAssignIntervalMinutes) * time.Minute)
+		for {
+			select {
+			case <-ticker.C:
+				j.run(ctx)
+			case <-ctx.Done():
+				log.Println("[AUTO-ASSIGN] Stopping auto-assign job")
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+func (j *AutoAssignJob) run(ctx context.Context) {
+	acquired, err := j.lock.tryAcquire(ctx)
+	if err != nil {
+		log.Println("[AUTO-ASSIGN] Failed to acquire lock:", err)
+		return
+	}
+	if !acquired {
+		return
+	}
+	defer func() {
+		if err := j.lock.release(context.WithoutCancel(ctx)); err != nil {
+			log.Println("[AUTO-ASSIGN] Failed to release lock:", err)
+		}
+	}()
+
+	orders, err := j.service.repo.Filter(ctx, bson.M{
+		"status": bson.M{"$in": []models.OrderStatus{models.StatusPaid, models.StatusPrePaid}},
+		"date":   bson.M{"$gt": time.Now()},
+		"$or": []bson.M{
+			{"cleaner_id": bson.M{"$exists": false}},
+			{"cleaner_id": bson.M{"$size": 0}},
+		},
+	})
+	if err != nil {
+		log.Println("[AUTO-ASSIGN] Failed to fetch unassigned orders:", err)
+		return
+	}
+
+	for _, order := range orders {
+		plan, err := j.service.AutoAssign(ctx, order.ID, nil)
+		if err != nil {
+			log.Printf("[AUTO-ASSIGN] Order %s left for manual assignment: %v", order.ID.Hex(), err)
+			continue
+		}
+		log.Printf("[AUTO-ASSIGN] Order %s assigned to %v", order.ID.Hex(), plan.Selected)
+	}
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+	"cleaning-app/order-service/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type stubAuth []utils.CleanerProfile
+
+func (a stubAuth) GetCleaners(context.Context) ([]utils.CleanerProfile, error) { return a, nil }
+
+// gamificationServer отвечает уровнями клинеров, как user-management-service.
+func gamificationServer(t *testing.T, levels map[string]int) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var out []map[string]interface{}
+		for id, lvl := range levels {
+			out = append(out, map[string]interface{}{"user_id": id, "current_level": lvl})
+		}
+		json.NewEncoder(w).Encode(out)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestRankCandidates(t *testing.T) {
+	start := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Hour)
+	order := &models.Order{
+		ID:               primitive.NewObjectID(),
+		Status:           models.StatusPaid,
+		Date:             start,
+		EndDate:          start.Add(2 * time.Hour),
+		CleanersRequired: 2,
+	}
+	busy := models.Order{ID: primitive.NewObjectID(), CleanerID: []string{"busy"}, Status: models.StatusAssigned, Date: start, EndDate: start.Add(time.Hour)}
+
+	repo := newStubOrderRepo(order)
+	repo.inRange = []models.Order{busy}
+	s := newTestService(repo)
+	s.cfg.UserManagementURL = gamificationServer(t, map[string]int{"newbie": 5})
+	s.schedules = stubScheduleRepo{byCleaner: map[string]*models.CleanerSchedule{
+		"off": {CleanerID: "off"}, // график без рабочих часов
+	}}
+	s.auth = stubAuth{
+		{ID: "busy", AverageRating: 5, RatingCount: 10},
+		{ID: "rated", AverageRating: 4, RatingCount: 3},
+		{ID: "newbie"}, // без отзывов — нейтральный рейтинг, зато высокий уровень
+		{ID: "off", AverageRating: 5, RatingCount: 10},
+	}
+
+	plan, err := s.rankCandidates(context.Background(), order)
+	if err != nil {
+		t.Fatalf("rankCandidates: %v", err)
+	}
+	got := make([]string, len(plan.Candidates))
+	for i, c := range plan.Candidates {
+		got[i] = c.CleanerID
+	}
+	// newbie: 0.4*0.6 + 0.35*1 + 0.25*1 = 0.84; rated: 0.4*0.8 + 0.35*1 + 0.25*0.2 = 0.72.
+	if len(got) != 4 || got[0] != "newbie" || got[1] != "rated" {
+		t.Fatalf("ranking = %v, want newbie, rated first", got)
+	}
+	if plan.Candidates[0].Total != 0.84 || plan.Candidates[1].Total != 0.72 {
+		t.Errorf("totals = %v/%v, want 0.84/0.72", plan.Candidates[0].Total, plan.Candidates[1].Total)
+	}
+	for _, c := range plan.Candidates[2:] {
+		if c.Available || c.Reason == "" {
+			t.Errorf("%s must be unavailable with a reason, got %+v", c.CleanerID, c)
+		}
+	}
+	if len(plan.Selected) != 2 || plan.Selected[0] != "newbie" || plan.Selected[1] != "rated" {
+		t.Errorf("selected = %v, want [newbie rated]", plan.Selected)
+	}
+
+	// Одного доступного клинера на двухместный заказ мало — частично не назначаем.
+	s.auth = stubAuth{{ID: "rated", AverageRating: 4, RatingCount: 3}, {ID: "busy"}}
+	if plan, err := s.rankCandidates(context.Background(), order); err != nil || len(plan.Selected) != 0 {
+		t.Errorf("partial plan selected %v (err %v), want none", plan.Selected, err)
+	}
+}
+
+func TestAutoAssignDoesNotOverfill(t *testing.T) {
+	start := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Hour)
+	order := &models.Order{
+		ID:               primitive.NewObjectID(),
+		Status:           models.StatusPaid,
+		Date:             start,
+		EndDate:          start.Add(2 * time.Hour),
+		CleanersRequired: 1,
+	}
+	repo := newStubOrderRepo(order)
+	// Пока мы ранжировали, другой экземпляр назначил клинера: наша запись получит
+	// конфликт версий, а повтор должен увидеть, что заказ уже укомплектован.
+	repo.beforeUpdate = func(stored *models.Order) {
+		stored.CleanerID = []string{"other"}
+		stored.Status = models.StatusAssigned
+		stored.Version++
+	}
+	s := newTestService(repo)
+	s.cfg.UserManagementURL = gamificationServer(t, nil)
+	s.schedules = stubScheduleRepo{}
+	s.auth = stubAuth{{ID: "a", AverageRating: 5, RatingCount: 1}}
+
+	if _, err := s.AutoAssign(context.Background(), order.ID, nil); !errors.Is(err, models.ErrValidation) {
+		t.Fatalf("AutoAssign: %v, want ErrValidation", err)
+	}
+	stored, _ := repo.GetByID(context.Background(), order.ID)
+	if len(stored.CleanerID) != 1 || stored.CleanerID[0] != "other" {
+		t.Errorf("cleaners = %v, want [other]", stored.CleanerID)
+	}
+
+	// Менеджер не может подтвердить больше клинеров, чем не хватает заказу.
+	if _, err := s.AutoAssign(context.Background(), order.ID, []string{"a"}); !errors.Is(err, models.ErrValidation) {
+		t.Errorf("AutoAssign over capacity: %v, want ErrValidation", err)
+	}
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxAvailabilityRange ограничивает окно поиска свободных слотов.
+const maxAvailabilityRange = 31 * 24 * time.Hour
+
+// ScheduleRepository хранит графики работы клинеров (cleaner_schedules).
+type ScheduleRepository interface {
+	Upsert(ctx context.Context, schedule *models.CleanerSchedule) error
+	GetByCleanerID(ctx context.Context, cleanerID string) (*models.CleanerSchedule, error)
+	Find(ctx context.Context, cleanerIDs []string) ([]models.CleanerSchedule, error)
+}
+
+// GetSchedule возвращает график клинера; если он ещё не объявлен — пустой.
+func (s *orderService) GetSchedule(ctx context.Context, cleanerID string) (*models.CleanerSchedule, error) {
+	schedule, err := s.schedules.GetByCleanerID(ctx, cleanerID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return &models.CleanerSchedule{CleanerID: cleanerID, WeeklyHours: []models.WorkingHours{}}, nil
+	}
+	return schedule, err
+}
+
+// SaveSchedule валидирует и сохраняет график клинера.
+func (s *orderService) SaveSchedule(ctx context.Context, cleanerID string, schedule *models.CleanerSchedule) error {
+	schedule.CleanerID = cleanerID
+	if err := schedule.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", models.ErrValidation, err.Error())
+	}
+	return s.schedules.Upsert(ctx, schedule)
+}
+
+// GetAvailability ищет свободные окна длиной не меньше duration в [from, to) для клинеров
+// с объявленным графиком (или только для cleanerIDs, если список задан).
+// Уже назначенные заказы вычитаются вместе с буфером на дорогу.
+func (s *orderService) GetAvailability(ctx context.Context, from, to time.Time, duration time.Duration, cleanerIDs []string) ([]models.CleanerAvailability, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: 'to' must be after 'from'", models.ErrValidation)
+	}
+	if to.Sub(from) > maxAvailabilityRange {
+		return nil, fmt.Errorf("%w: range must not exceed 31 days", models.ErrValidation)
+	}
+	if duration <= 0 {
+		duration = time.Duration(s.cfg.DefaultOrderDurationMinutes) * time.Minute
+	}
+
+	schedules, err := s.schedules.Find(ctx, cleanerIDs)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]models.CleanerAvailability, 0, len(schedules))
+	if len(schedules) == 0 {
+		return result, nil
+	}
+
+	ids := make([]string, 0, len(schedules))
+	for _, sc := range schedules {
+		ids = append(ids, sc.CleanerID)
+	}
+	buffer := time.Duration(s.cfg.TravelBufferMinutes) * time.Minute
+	orders, err := s.repo.FindCleanersOrdersInRange(ctx, ids, from.Add(-buffer), to.Add(buffer))
+	if err != nil {
+		return nil, err
+	}
+	busy := make(map[string][]models.TimeRange)
+	for i := range orders {
+		start, end := s.orderInterval(&orders[i])
+		r := models.TimeRange{From: start.Add(-buffer), To: end.Add(buffer)}
+		for _, cid := range orders[i].CleanerID {
+			busy[cid] = append(busy[cid], r)
+		}
+	}
+
+	for i := range schedules {
+		slots := schedules[i].FreeSlots(busy[schedules[i].CleanerID], from, to, duration, s.location())
+		if slots == nil {
+			slots = []models.TimeRange{}
+		}
+		result = append(result, models.CleanerAvailability{CleanerID: schedules[i].CleanerID, Slots: slots})
+	}
+	return result, nil
+}
+
+// checkWorkingHours отклоняет назначение, если заказ не укладывается в объявленный график.
+// Клинеров без графика не ограничиваем — они ещё не перешли на календарь.
+func (s *orderService) checkWorkingHours(ctx context.Context, order *models.Order, cleanerID string) error {
+	schedule, err := s.schedules.GetByCleanerID(ctx, cleanerID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	start, end := s.orderInterval(order)
+	return schedule.Covers(start, end, s.location())
+}
+
+// location — часовой пояс, в котором заданы рабочие часы клинеров (SERVICE_TIMEZONE).
+func (s *orderService) location() *time.Location {
+	if s.cfg.Location != nil {
+		return s.cfg.Location
+	}
+	return time.UTC
+}
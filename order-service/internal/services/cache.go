@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Семейства кэша заказов.
+const (
+	cacheFamilyClient = "orders_by_client"
+	cacheFamilyFilter = "orders_filter"
+)
+
+// genKeyTTL — сколько живёт счётчик поколения без изменений. Должен быть больше TTL данных:
+// после сброса счётчика в 0 старых записей поколения 0 уже нет.
+const genKeyTTL = 24 * time.Hour
+
+type cacheCounters struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// orderCache — версионируемый кэш: ключ данных содержит номер поколения семейства
+// (и клиента), запись в заказы увеличивает поколение одним INCR вместо поиска ключей.
+// Старые записи никто не удаляет — они просто перестают читаться и истекают по TTL.
+type orderCache struct {
+	rdb      *redis.Client
+	ttl      time.Duration
+	counters map[string]*cacheCounters
+}
+
+func newOrderCache(rdb *redis.Client, ttl time.Duration) *orderCache {
+	return &orderCache{
+		rdb: rdb,
+		ttl: ttl,
+		counters: map[string]*cacheCounters{
+			cacheFamilyClient: {},
+			cacheFamilyFilter: {},
+		},
+	}
+}
+
+func genKey(scope string) string {
+	return "cache:gen:" + scope
+}
+
+func clientScope(clientID string) string {
+	return cacheFamilyClient + ":" + clientID
+}
+
+// generation — текущее поколение области; при ошибке Redis — 0 (кэш просто промахнётся).
+func (c *orderCache) generation(ctx context.Context, scope string) int64 {
+	gen, err := c.rdb.Get(ctx, genKey(scope)).Int64()
+	if err != nil && err != redis.Nil {
+		log.Printf("[CACHE] Failed to read generation %s: %v", scope, err)
+	}
+	return gen
+}
+
+// key строит ключ данных: <family>:<scope-поколение>:<suffix>.
+func (c *orderCache) key(ctx context.Context, family, scope, suffix string) string {
+	return fmt.Sprintf("%s:%d:%s", family, c.generation(ctx, scope), suffix)
+}
+
+// get читает значение и считает попадание/промах семейства.
+func (c *orderCache) get(ctx context.Context, family, key string, dst interface{}) bool {
+	counters := c.counters[family]
+	data, err := c.rdb.Get(ctx, key).Bytes()
+	if err == nil && json.Unmarshal(data, dst) == nil {
+		counters.hits.Add(1)
+		return true
+	}
+	counters.misses.Add(1)
+	return false
+}
+
+func (c *orderCache) set(ctx context.Context, key string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	if err := c.rdb.Set(ctx, key, data, c.ttl).Err(); err != nil {
+		log.Printf("[CACHE] Failed to set %s: %v", key, err)
+	}
+}
+
+// bump инвалидирует области, увеличивая их поколения.
+func (c *orderCache) bump(ctx context.Context, scopes ...string) {
+	pipe := c.rdb.Pipeline()
+	for _, scope := range scopes {
+		pipe.Incr(ctx, genKey(scope))
+		pipe.Expire(ctx, genKey(scope), genKeyTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("[CACHE] Failed to bump generations %v: %v", scopes, err)
+	}
+}
+
+func (c *orderCache) stats() []models.CacheStats {
+	out := make([]models.CacheStats, 0, len(c.counters))
+	for _, family := range []string{cacheFamilyClient, cacheFamilyFilter} {
+		counters := c.counters[family]
+		st := models.CacheStats{Family: family, Hits: counters.hits.Load(), Misses: counters.misses.Load()}
+		if total := st.Hits + st.Misses; total > 0 {
+			st.HitRate = math.Round(float64(st.Hits)/float64(total)*1000) / 1000
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+// CacheStats возвращает счётчики попаданий в кэш заказов этого экземпляра сервиса.
+func (s *orderService) CacheStats() []models.CacheStats {
+	return s.cache.stats()
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+)
+
+func TestOrderCacheGenerations(t *testing.T) {
+	_, rdb := newFakeRedis(t)
+	cache := newOrderCache(rdb, time.Minute)
+	ctx := context.Background()
+	alice, bob := clientScope("alice"), clientScope("bob")
+
+	aliceKey := cache.key(ctx, cacheFamilyClient, alice, "alice")
+	bobKey := cache.key(ctx, cacheFamilyClient, bob, "bob")
+	if aliceKey != cacheFamilyClient+":0:alice" {
+		t.Fatalf("key = %s, want generation 0", aliceKey)
+	}
+	cache.set(ctx, aliceKey, []models.Order{{ClientID: "alice"}})
+	cache.set(ctx, bobKey, []models.Order{{ClientID: "bob"}})
+
+	var got []models.Order
+	if !cache.get(ctx, cacheFamilyClient, aliceKey, &got) || len(got) != 1 {
+		t.Fatalf("expected a hit for %s, got %v", aliceKey, got)
+	}
+
+	// Запись в заказы alice меняет только её поколение: старый ключ больше не читается.
+	cache.bump(ctx, alice)
+	if k := cache.key(ctx, cacheFamilyClient, alice, "alice"); k == aliceKey {
+		t.Fatalf("key did not change after bump: %s", k)
+	} else if cache.get(ctx, cacheFamilyClient, k, &got) {
+		t.Fatalf("stale entry read after bump via %s", k)
+	}
+	if k := cache.key(ctx, cacheFamilyClient, bob, "bob"); k != bobKey {
+		t.Fatalf("bob's key changed after alice's bump: %s", k)
+	}
+
+	stats := cache.stats()
+	if stats[0].Family != cacheFamilyClient || stats[0].Hits != 1 || stats[0].Misses != 1 || stats[0].HitRate != 0.5 {
+		t.Fatalf("stats = %+v", stats[0])
+	}
+}
+
+// Без Redis кэш не мешает работе: поколение 0 и промах.
+func TestOrderCacheWithoutRedis(t *testing.T) {
+	svc := newTestService(newStubOrderRepo())
+	ctx := context.Background()
+	key := svc.cache.key(ctx, cacheFamilyFilter, cacheFamilyFilter, "q")
+	if key != cacheFamilyFilter+":0:q" {
+		t.Fatalf("key = %s", key)
+	}
+	var got []models.Order
+	if svc.cache.get(ctx, cacheFamilyFilter, key, &got) {
+		t.Fatal("unexpected hit without Redis")
+	}
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+	"cleaning-app/order-service/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (s *orderService) cancellationPolicy() models.CancellationPolicy {
+	return models.CancellationPolicy{
+		FreeHours:       s.cfg.CancelFreeHours,
+		FeePercent:      float64(s.cfg.CancelFeePercent),
+		AllowInProgress: s.cfg.CancelAllowInProgress,
+	}
+}
+
+// paidAmount — сколько клиент заплатил за заказ напрямую. Заказы из подписки (prepaid)
+// оплачиваются через subscription-service, поэтому здесь по ним не возвращаем.
+func (s *orderService) paidAmount(ctx context.Context, order *models.Order) float64 {
+	switch order.Status {
+	case models.StatusPending, models.StatusPrePaid:
+		return 0
+	case models.StatusAssigned, models.StatusInProgress, models.StatusCompleted, models.StatusDisputed:
+		if s.statusBeforeAssignment(ctx, order) == models.StatusPrePaid {
+			return 0
+		}
+	}
+	return order.TotalPrice
+}
+
+// canCancel — менеджер и админ отменяют заказ в любом статусе, допускающем отмену;
+// владелец — только пока работы не начались (см. CancellationPolicy.ClientMayCancel).
+func canCancel(order *models.Order, userID, role string, policy models.CancellationPolicy) bool {
+	if role == "manager" || role == "admin" {
+		return true
+	}
+	return order.ClientID == userID && policy.ClientMayCancel(order.Status)
+}
+
+// checkCancellable проверяет, что заказ из статуса from можно отменить. Заказ в споре
+// закрывается только через ResolveDispute: иначе тикет и спор остались бы открытыми.
+func checkCancellable(from models.OrderStatus) error {
+	if from == models.StatusDisputed {
+		return models.ErrOrderInDispute
+	}
+	return models.ValidateTransition(from, models.StatusCancelled)
+}
+
+// QuoteCancellation показывает, сколько будет удержано и возвращено при отмене сейчас.
+func (s *orderService) QuoteCancellation(ctx context.Context, id primitive.ObjectID, userID, role string) (*models.CancellationQuote, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canCancel(order, userID, role, s.cancellationPolicy()) {
+		return nil, models.ErrForbidden
+	}
+	if err := checkCancellable(order.Status); err != nil {
+		return nil, err
+	}
+	quote := s.cancellationPolicy().Evaluate(s.paidAmount(ctx, order), order.Date, time.Now(), order.Status == models.StatusInProgress)
+	return &quote, nil
+}
+
+// CancelOrder отменяет заказ по политике отмены: считает удержание, переводит заказ
+// в cancelled (документ не удаляется) и после этого запрашивает возврат в payment-service.
+// Если возврат не прошёл, заказ остаётся отменённым с refund_pending, а повторный вызов
+// повторяет только возврат.
+func (s *orderService) CancelOrder(ctx context.Context, id primitive.ObjectID, userID, role, reason, authHeader string) (*models.Order, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == models.StatusCancelled && order.Cancellation != nil && order.Cancellation.RefundPending {
+		if order.ClientID != userID && role != "manager" && role != "admin" {
+			return nil, models.ErrForbidden
+		}
+		return s.refundCancellation(ctx, order, authHeader)
+	}
+	if !canCancel(order, userID, role, s.cancellationPolicy()) {
+		return nil, models.ErrForbidden
+	}
+	from := order.Status
+	if err := checkCancellable(from); err != nil {
+		return nil, err
+	}
+
+	quote := s.cancellationPolicy().Evaluate(s.paidAmount(ctx, order), order.Date, time.Now(), from == models.StatusInProgress)
+	info := &models.CancellationInfo{
+		CancelledBy:   userID,
+		Role:          role,
+		Reason:        reason,
+		Rule:          quote.Rule,
+		Fee:           quote.Fee,
+		RefundAmount:  quote.RefundAmount,
+		RefundPending: quote.RefundAmount > 0,
+		CancelledAt:   time.Now(),
+	}
+
+	// Сначала сохраняем отмену, возврат — только после неё: иначе при конфликте версий
+	// или ошибке записи деньги ушли бы клиенту, а заказ остался активным.
+	apply := func(o *models.Order) error {
+		from = o.Status
+		if !canCancel(o, userID, role, s.cancellationPolicy()) {
+			return models.ErrForbidden
+		}
+		if err := checkCancellable(from); err != nil {
+			return err
+		}
+		o.Status = models.StatusCancelled
+		o.Cancellation = info
+		return nil
+	}
+	if err := apply(order); err != nil {
+		return nil, err
+	}
+	order, err = s.saveWithReload(ctx, order, apply, func(ctx context.Context, order *models.Order) error {
+		return s.inTx(ctx, func(ctx context.Context) error {
+			if err := s.repo.Update(ctx, order); err != nil {
+				return err
+			}
+			s.recordTransition(ctx, order.ID, from, models.StatusCancelled, reason)
+			events := append(cleanerNotices(order, order.CleanerID, "order_cancelled", nil), clientNotice(order, "order_cancelled", map[string]string{
+				"refund_amount": fmt.Sprintf("%.2f", info.RefundAmount),
+				"fee":           fmt.Sprintf("%.2f", info.Fee),
+			}))
+			return s.emit(ctx, events...)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.clearCache(ctx, order.ClientID)
+	if info.RefundPending {
+		return s.refundCancellation(ctx, order, authHeader)
+	}
+	return order, nil
+}
+
+// refundCancellation запрашивает возврат по уже сохранённой отмене и отмечает его в заказе.
+// Ключ идемпотентности один на заказ: повтор после сбоя не вернёт деньги второй раз.
+func (s *orderService) refundCancellation(ctx context.Context, order *models.Order, authHeader string) (*models.Order, error) {
+	info := order.Cancellation
+	refund, err := utils.RequestRefund(ctx, s.cfg.PaymentServiceURL, utils.RefundRequest{
+		EntityType:     "order",
+		EntityID:       order.ID.Hex(),
+		UserID:         order.ClientID,
+		Amount:         info.RefundAmount,
+		Reason:         fmt.Sprintf("order cancelled (%s)", info.Rule),
+		IdempotencyKey: "order_cancel:" + order.ID.Hex(),
+	}, authHeader)
+	if err != nil {
+		log.Printf("[CANCEL] Refund for order %s failed: %v", order.ID.Hex(), err)
+		return nil, fmt.Errorf("%w: %v", models.ErrRefundPending, err)
+	}
+
+	apply := func(o *models.Order) error {
+		if o.Cancellation == nil || !o.Cancellation.RefundPending {
+			return nil
+		}
+		done := *o.Cancellation
+		done.RefundPending = false
+		done.RefundID = refund.RefundID
+		o.Cancellation = &done
+		return nil
+	}
+	if err := apply(order); err != nil {
+		return nil, err
+	}
+	order, err = s.saveWithReload(ctx, order, apply, s.repo.Update)
+	if err != nil {
+		return nil, err
+	}
+	s.clearCache(ctx, order.ClientID)
+	return order, nil
+}
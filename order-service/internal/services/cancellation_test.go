@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCancelDisputedOrderRejected(t *testing.T) {
+	order := &models.Order{
+		ID:        primitive.NewObjectID(),
+		ClientID:  "client",
+		CleanerID: []string{"cleaner"},
+		Status:    models.StatusDisputed,
+		Date:      time.Now().Add(-48 * time.Hour),
+		Dispute:   &models.Dispute{Status: models.DisputeOpen},
+	}
+	repo := newStubOrderRepo(order)
+	s := newTestService(repo)
+	ctx := context.Background()
+
+	// Клиент не может отменить заказ после завершения, менеджер — пока спор не решён.
+	for role, want := range map[string]error{"client": models.ErrForbidden, "manager": models.ErrOrderInDispute} {
+		if _, err := s.QuoteCancellation(ctx, order.ID, "client", role); !errors.Is(err, want) {
+			t.Errorf("QuoteCancellation as %s: %v, want %v", role, err, want)
+		}
+		if _, err := s.CancelOrder(ctx, order.ID, "client", role, "changed my mind", ""); !errors.Is(err, want) {
+			t.Errorf("CancelOrder as %s: %v, want %v", role, err, want)
+		}
+	}
+	if repo.updates != 0 || repo.orders[order.ID].Status != models.StatusDisputed {
+		t.Errorf("disputed order was modified: %+v", repo.orders[order.ID])
+	}
+}
+
+func TestCancelOrderRefundsAfterSave(t *testing.T) {
+	order := &models.Order{
+		ID:         primitive.NewObjectID(),
+		ClientID:   "client",
+		Status:     models.StatusPaid,
+		Date:       time.Now().Add(72 * time.Hour),
+		TotalPrice: 100,
+	}
+	repo := newStubOrderRepo(order)
+	s := newTestService(repo)
+	payments, url := newFakePayments(t, 1)
+	s.cfg.PaymentServiceURL = url
+	ctx := context.Background()
+
+	// payment-service недоступен: отмена уже сохранена, возврат ждёт повтора.
+	if _, err := s.CancelOrder(ctx, order.ID, "client", "client", "", "Bearer t"); !errors.Is(err, models.ErrRefundPending) {
+		t.Fatalf("CancelOrder = %v, want ErrRefundPending", err)
+	}
+	stored := repo.orders[order.ID]
+	if stored.Status != models.StatusCancelled || stored.Cancellation == nil || !stored.Cancellation.RefundPending {
+		t.Fatalf("order after failed refund: %+v", stored)
+	}
+
+	// Повтор доделывает только возврат.
+	got, err := s.CancelOrder(ctx, order.ID, "client", "client", "", "Bearer t")
+	if err != nil {
+		t.Fatalf("retry CancelOrder: %v", err)
+	}
+	if got.Cancellation.RefundPending || got.Cancellation.RefundID == "" {
+		t.Errorf("refund not recorded: %+v", got.Cancellation)
+	}
+	if len(payments.refunds) != 1 || payments.refunds[0].Amount != 100 || payments.refunds[0].IdempotencyKey != "order_cancel:"+order.ID.Hex() {
+		t.Errorf("refunds = %+v", payments.refunds)
+	}
+
+	// Возврат завершён — повторная отмена снова упирается в статус.
+	if _, err := s.CancelOrder(ctx, order.ID, "client", "client", "", "Bearer t"); err == nil {
+		t.Error("cancelling a cancelled order succeeded")
+	}
+}
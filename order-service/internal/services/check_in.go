@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// checkDistance сверяет координаты клинера с адресом заказа. Для заказов без координат
+// проверка невозможна — возвращается nil, и отметка помечается как непроверенная
+// (WorkLog.LocationUnverified), чтобы менеджер видел такие заказы.
+func (s *orderService) checkDistance(order *models.Order, loc models.GeoPoint) (*float64, error) {
+	if order.Location == nil || !order.Location.Valid() {
+		log.Printf("[CHECK-IN] Order %s has no coordinates, distance not verified", order.ID.Hex())
+		return nil, nil
+	}
+	distance := math.Round(models.DistanceMeters(*order.Location, loc))
+	radius := float64(s.cfg.CheckInRadiusMeters)
+	if radius > 0 && distance > radius {
+		return nil, &models.TooFarError{DistanceMeters: distance, RadiusMeters: radius}
+	}
+	return &distance, nil
+}
+
+// CheckIn отмечает начало работы клинера на месте. Первая отметка переводит заказ
+// в in_progress и уведомляет клиента о начале уборки.
+func (s *orderService) CheckIn(ctx context.Context, orderID primitive.ObjectID, cleanerID string, loc models.GeoPoint) (*models.Order, error) {
+	var out *models.Order
+	err := retryOnConflict(func() error {
+		var err error
+		out, err = s.checkIn(ctx, orderID, cleanerID, loc)
+		return err
+	})
+	return out, err
+}
+
+func (s *orderService) checkIn(ctx context.Context, orderID primitive.ObjectID, cleanerID string, loc models.GeoPoint) (*models.Order, error) {
+	order, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !containsString(order.CleanerID, cleanerID) {
+		return nil, models.ErrForbidden
+	}
+	from := order.Status
+	if from != models.StatusInProgress {
+		if err := models.ValidateTransition(from, models.StatusInProgress); err != nil {
+			return nil, err
+		}
+	}
+	if order.WorkLogFor(cleanerID) != nil {
+		return nil, models.ErrAlreadyCheckedIn
+	}
+	distance, err := s.checkDistance(order, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	order.WorkLogs = append(order.WorkLogs, models.WorkLog{
+		CleanerID:          cleanerID,
+		CheckInAt:          now,
+		CheckInLocation:    loc,
+		CheckInDistanceM:   distance,
+		LocationUnverified: distance == nil,
+	})
+	if order.StartedAt == nil {
+		order.StartedAt = &now
+	}
+	order.Status = models.StatusInProgress
+	started := from != models.StatusInProgress
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, order); err != nil {
+			return err
+		}
+		if !started {
+			return nil
+		}
+		s.recordTransition(ctx, order.ID, from, models.StatusInProgress, "cleaner checked in")
+		return s.emit(ctx, clientNotice(order, "cleaning_started", nil))
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.clearCache(ctx, order.ClientID)
+	return order, nil
+}
+
+// CheckOut отмечает окончание работы клинера и пересчитывает фактическую длительность заказа.
+// Статус не меняется — заказ завершается отдельно, с фотоотчётом.
+func (s *orderService) CheckOut(ctx context.Context, orderID primitive.ObjectID, cleanerID string, loc models.GeoPoint) (*models.Order, error) {
+	var out *models.Order
+	err := retryOnConflict(func() error {
+		var err error
+		out, err = s.checkOut(ctx, orderID, cleanerID, loc)
+		return err
+	})
+	return out, err
+}
+
+func (s *orderService) checkOut(ctx context.Context, orderID primitive.ObjectID, cleanerID string, loc models.GeoPoint) (*models.Order, error) {
+	order, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !containsString(order.CleanerID, cleanerID) {
+		return nil, models.ErrForbidden
+	}
+	entry := order.WorkLogFor(cleanerID)
+	if entry == nil || entry.CheckOutAt != nil {
+		return nil, models.ErrNotCheckedIn
+	}
+	distance, err := s.checkDistance(order, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	entry.CheckOutAt = &now
+	entry.CheckOutLocation = &loc
+	entry.CheckOutDistanceM = distance
+	entry.LocationUnverified = entry.LocationUnverified || distance == nil
+	entry.WorkedMinutes = int(math.Round(now.Sub(entry.CheckInAt).Minutes()))
+	order.WorkedMinutes = int(math.Round(order.ActualDuration().Minutes()))
+
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		return s.repo.Update(ctx, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.clearCache(ctx, order.ClientID)
+	return order, nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCheckInOut(t *testing.T) {
+	site := models.GeoPoint{Lat: 43.2389, Lng: 76.8897}
+	newOrder := func(loc *models.GeoPoint) *models.Order {
+		return &models.Order{
+			ID:        primitive.NewObjectID(),
+			ClientID:  "client",
+			CleanerID: []string{"c1"},
+			Status:    models.StatusAssigned,
+			Date:      time.Now(),
+			Location:  loc,
+			Version:   1,
+		}
+	}
+	ctx := context.Background()
+
+	t.Run("too far from the address", func(t *testing.T) {
+		order := newOrder(&site)
+		svc := newTestService(newStubOrderRepo(order))
+		svc.cfg.CheckInRadiusMeters = 300
+		// ~1.1 км севернее адреса.
+		_, err := svc.CheckIn(ctx, order.ID, "c1", models.GeoPoint{Lat: site.Lat + 0.01, Lng: site.Lng})
+		var tooFar *models.TooFarError
+		if !errors.As(err, &tooFar) {
+			t.Fatalf("err = %v, want TooFarError", err)
+		}
+	})
+
+	t.Run("verified check-in and check-out", func(t *testing.T) {
+		order := newOrder(&site)
+		repo := newStubOrderRepo(order)
+		svc := newTestService(repo)
+		svc.cfg.CheckInRadiusMeters = 300
+		got, err := svc.CheckIn(ctx, order.ID, "c1", site)
+		if err != nil {
+			t.Fatalf("CheckIn: %v", err)
+		}
+		if got.Status != models.StatusInProgress || got.StartedAt == nil {
+			t.Fatalf("status = %s, started = %v", got.Status, got.StartedAt)
+		}
+		l := got.WorkLogFor("c1")
+		if l == nil || l.CheckInDistanceM == nil || l.LocationUnverified {
+			t.Fatalf("work log = %+v, want verified distance", l)
+		}
+
+		// Сдвигаем отметку о начале на 45 минут назад, чтобы проверить подсчёт длительности.
+		stored := repo.orders[order.ID]
+		stored.WorkLogs[0].CheckInAt = time.Now().Add(-45 * time.Minute)
+		got, err = svc.CheckOut(ctx, order.ID, "c1", site)
+		if err != nil {
+			t.Fatalf("CheckOut: %v", err)
+		}
+		l = got.WorkLogFor("c1")
+		if l.CheckOutAt == nil || l.WorkedMinutes != 45 || got.WorkedMinutes != 45 {
+			t.Fatalf("worked = %d (order %d), want 45", l.WorkedMinutes, got.WorkedMinutes)
+		}
+		if _, err := svc.CheckOut(ctx, order.ID, "c1", site); !errors.Is(err, models.ErrNotCheckedIn) {
+			t.Fatalf("second CheckOut err = %v, want ErrNotCheckedIn", err)
+		}
+	})
+
+	t.Run("order without coordinates is marked unverified", func(t *testing.T) {
+		order := newOrder(nil)
+		svc := newTestService(newStubOrderRepo(order))
+		svc.cfg.CheckInRadiusMeters = 300
+		got, err := svc.CheckIn(ctx, order.ID, "c1", site)
+		if err != nil {
+			t.Fatalf("CheckIn: %v", err)
+		}
+		if l := got.WorkLogFor("c1"); l.CheckInDistanceM != nil || !l.LocationUnverified {
+			t.Fatalf("work log = %+v, want unverified", l)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetChecklist возвращает чек-лист заказа с отметками клинеров.
+func (s *orderService) GetChecklist(ctx context.Context, id primitive.ObjectID, userID, role string) ([]models.ChecklistItem, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOrderAccess(order, userID, role); err != nil {
+		return nil, err
+	}
+	if order.Checklist == nil {
+		return []models.ChecklistItem{}, nil
+	}
+	return order.Checklist, nil
+}
+
+// UpdateChecklistItem отмечает пункт чек-листа: done, skipped (с причиной) или снова pending.
+func (s *orderService) UpdateChecklistItem(ctx context.Context, id primitive.ObjectID, itemID, cleanerID string, status models.ChecklistStatus, reason string) (*models.ChecklistItem, error) {
+	var out *models.ChecklistItem
+	err := retryOnConflict(func() error {
+		var err error
+		out, err = s.updateChecklistItem(ctx, id, itemID, cleanerID, status, reason)
+		return err
+	})
+	return out, err
+}
+
+func (s *orderService) updateChecklistItem(ctx context.Context, id primitive.ObjectID, itemID, cleanerID string, status models.ChecklistStatus, reason string) (*models.ChecklistItem, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !containsString(order.CleanerID, cleanerID) {
+		return nil, models.ErrForbidden
+	}
+	if order.Status != models.StatusAssigned && order.Status != models.StatusInProgress {
+		return nil, fmt.Errorf("%w: checklist can only be updated while the order is assigned or in progress", models.ErrValidation)
+	}
+	item, err := order.MarkChecklistItem(itemID, cleanerID, status, reason, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, order); err != nil {
+		return nil, err
+	}
+	s.clearCache(ctx, order.ClientID)
+	return item, nil
+}
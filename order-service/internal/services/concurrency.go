@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"cleaning-app/order-service/internal/models"
+)
+
+// maxConflictRetries — сколько раз операция перечитывает заказ и повторяется при конфликте версий.
+const maxConflictRetries = 3
+
+// retryOnConflict повторяет op, пока запись заказа упирается в конфликт версий.
+// op должна сама перечитывать заказ — иначе повтор снова упрётся в старую версию.
+func retryOnConflict(op func() error) error {
+	var err error
+	for attempt := 0; attempt < maxConflictRetries; attempt++ {
+		if err = op(); !errors.Is(err, models.ErrVersionConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+// saveWithReload — для операций, у которых до записи уже был внешний побочный эффект
+// (возврат денег, тикет в поддержке) и повторять их целиком нельзя. save пишет order;
+// при конфликте заказ перечитывается, apply заново применяет к нему изменения
+// (и проверяет, что они ещё допустимы), после чего запись повторяется.
+func (s *orderService) saveWithReload(ctx context.Context, order *models.Order, apply func(order *models.Order) error, save func(ctx context.Context, order *models.Order) error) (*models.Order, error) {
+	for attempt := 1; ; attempt++ {
+		err := save(ctx, order)
+		if !errors.Is(err, models.ErrVersionConflict) || attempt >= maxConflictRetries {
+			return order, err
+		}
+		fresh, err := s.repo.GetByID(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := apply(fresh); err != nil {
+			return nil, err
+		}
+		order = fresh
+	}
+}
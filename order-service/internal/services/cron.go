@@ -2,39 +2,58 @@ package services
 
 import (
 	"context"
+	"errors"
 	"log"
 	"time"
 
 	"cleaning-app/order-service/internal/config"
 	"cleaning-app/order-service/internal/models"
-	"cleaning-app/order-service/internal/utils"
+	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
-// CronJobService отвечает за периодические задачи: напоминания и запросы отзывов
+// CronJobService отвечает за периодические задачи: напоминания о предстоящих заказах.
+// Запросы отзывов ставятся в outbox с задержкой при завершении заказа.
+//
+// Напоминания не привязаны к окну вокруг тика: каждый проход берёт все назначенные
+// заказы, до начала которых осталось не больше наибольшего смещения, и отправляет
+// те напоминания, что ещё не отмечены в Order.RemindersSent. Поэтому после простоя
+// пропущенные напоминания догоняются, а отметка и событие outbox пишутся в одной
+// транзакции — повторной отправки нет.
 type CronJobService struct {
 	OrderRepo OrderRepository
+	Outbox    OutboxRepository
+	Tx        TxRunner
 	Cfg       *config.Config
+	lock      *jobLock
 }
 
-func NewCronJobService(repo OrderRepository, cfg *config.Config) *CronJobService {
+func NewCronJobService(repo OrderRepository, outbox OutboxRepository, tx TxRunner, rdb *redis.Client, cfg *config.Config) *CronJobService {
+	interval := time.Duration(cfg.ReminderPollSeconds) * time.Second
 	return &CronJobService{
 		OrderRepo: repo,
+		Outbox:    outbox,
+		Tx:        tx,
 		Cfg:       cfg,
+		lock:      newJobLock(rdb, "reminders", 2*interval),
 	}
 }
 
 func (s *CronJobService) Start(ctx context.Context) {
+	if len(s.Cfg.ReminderOffsets) == 0 {
+		log.Println("[CRON] Reminder offsets are empty, reminders are disabled")
+		return
+	}
 	go s.startReminderJob(ctx)
-	go s.startReviewRequestJob(ctx)
 }
 
 func (s *CronJobService) startReminderJob(ctx context.Context) {
-	ticker := time.NewTicker(1 * time.Hour)
+	ticker := time.NewTicker(time.Duration(s.Cfg.ReminderPollSeconds) * time.Second)
+	s.runReminders(ctx)
 	for {
 		select {
 		case <-ticker.C:
-			s.sendReminderNotifications(ctx)
+			s.runReminders(ctx)
 		case <-ctx.Done():
 			log.Println("[CRON] Stopping reminder job")
 			ticker.Stop()
@@ -43,77 +62,63 @@ func (s *CronJobService) startReminderJob(ctx context.Context) {
 	}
 }
 
-func (s *CronJobService) startReviewRequestJob(ctx context.Context) {
-	ticker := time.NewTicker(10 * time.Minute)
-	for {
-		select {
-		case <-ticker.C:
-			s.sendReviewRequests(ctx)
-		case <-ctx.Done():
-			log.Println("[CRON] Stopping review request job")
-			ticker.Stop()
-			return
-		}
-	}
-}
-
-func (s *CronJobService) sendReminderNotifications(ctx context.Context) {
-	// Напоминать за 24 часа до даты заказа
-	from := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
-	to := from.Add(time.Hour)
-
-	orders, err := s.OrderRepo.Filter(ctx, bson.M{
-		"date": bson.M{
-			"$gte": from,
-			"$lt":  to,
-		},
-		"status": models.StatusAssigned,
-	})
+// runReminders выполняет проход, если блокировку не держит другой экземпляр.
+func (s *CronJobService) runReminders(ctx context.Context) {
+	acquired, err := s.lock.tryAcquire(ctx)
 	if err != nil {
-		log.Println("[CRON] Failed to fetch upcoming orders:", err)
+		log.Println("[CRON] Failed to acquire reminder lock:", err)
 		return
 	}
-
-	for _, order := range orders {
-		clientID := order.ClientID
-		reminderTime := order.Date.Format(time.RFC3339)
-		go func(clientID, orderID, when string) {
-			_ = utils.SendNotificationEvent(context.Background(), utils.NotificationEvent{
-				UserID:    clientID,
-				Role:      "client",
-				Type:      "reminder",
-				ExtraData: map[string]string{"order_id": orderID, "time": when},
-			})
-		}(clientID, order.ID.Hex(), reminderTime)
+	if !acquired {
+		return
 	}
+	defer func() {
+		if err := s.lock.release(context.WithoutCancel(ctx)); err != nil {
+			log.Println("[CRON] Failed to release reminder lock:", err)
+		}
+	}()
+	s.sendReminderNotifications(ctx, time.Now().UTC())
 }
 
-func (s *CronJobService) sendReviewRequests(ctx context.Context) {
-	// Запрашивать отзыв через час после завершения заказа
-	from := time.Now().Add(-1 * time.Hour).Truncate(time.Hour)
-	to := from.Add(10 * time.Minute)
+func (s *CronJobService) sendReminderNotifications(ctx context.Context, now time.Time) {
+	offsets := s.Cfg.ReminderOffsets
+	horizon := now.Add(offsets[len(offsets)-1])
 
 	orders, err := s.OrderRepo.Filter(ctx, bson.M{
-		"status": models.StatusCompleted,
-		"updated_at": bson.M{
-			"$gte": from,
-			"$lt":  to,
+		"date": bson.M{
+			"$gt":  now,
+			"$lte": horizon,
 		},
+		"status": models.StatusAssigned,
 	})
 	if err != nil {
-		log.Println("[CRON] Failed to fetch completed orders:", err)
+		log.Println("[CRON] Failed to fetch upcoming orders:", err)
 		return
 	}
 
-	for _, order := range orders {
-		clientID := order.ClientID
-		go func(clientID, orderID string) {
-			_ = utils.SendNotificationEvent(context.Background(), utils.NotificationEvent{
-				UserID:    clientID,
-				Role:      "client",
-				Type:      "review_request",
-				ExtraData: map[string]string{"order_id": orderID},
-			})
-		}(clientID, order.ID.Hex())
+	for i := range orders {
+		order := &orders[i]
+		offset, mark, send := order.DueReminder(offsets, now)
+		if len(mark) == 0 {
+			continue
+		}
+		err := s.Tx.WithTx(ctx, func(ctx context.Context) error {
+			if err := s.OrderRepo.MarkRemindersSent(ctx, order, mark); err != nil {
+				return err
+			}
+			if !send {
+				return nil
+			}
+			return s.Outbox.Insert(ctx, clientNotice(order, "reminder", map[string]string{
+				"time":   order.Date.Format(time.RFC3339),
+				"before": models.ReminderKey(offset),
+			}))
+		})
+		switch {
+		case errors.Is(err, models.ErrVersionConflict):
+			// Заказ изменился во время прохода — решение примем на следующем тике по свежей копии.
+		case err != nil:
+			log.Printf("[CRON] Failed to queue reminder for order %s: %v", order.ID.Hex(), err)
+		}
 	}
 }
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+	"cleaning-app/order-service/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// completedAt — когда заказ был завершён. Для заказов без истории берём время последнего изменения.
+func (s *orderService) completedAt(ctx context.Context, order *models.Order) time.Time {
+	entry, err := s.history.LastTransitionTo(ctx, order.ID, models.StatusCompleted)
+	if err != nil {
+		return order.UpdatedAt
+	}
+	return entry.CreatedAt
+}
+
+// OpenDispute открывает спор по завершённому заказу: создаёт тикет в support-service,
+// переводит заказ в disputed (выплата клинерам замораживается) и уведомляет клинеров.
+func (s *orderService) OpenDispute(ctx context.Context, id primitive.ObjectID, clientID string, dispute *models.Dispute, authHeader string) (*models.Order, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.ClientID != clientID {
+		return nil, models.ErrForbidden
+	}
+	if order.Dispute != nil {
+		return nil, models.ErrDisputeExists
+	}
+	from := order.Status
+	if err := models.ValidateTransition(from, models.StatusDisputed); err != nil {
+		return nil, err
+	}
+	if err := dispute.Validate(); err != nil {
+		return nil, err
+	}
+	window := time.Duration(s.cfg.DisputeWindowHours) * time.Hour
+	if time.Since(s.completedAt(ctx, order)) > window {
+		return nil, models.ErrDisputeWindowClosed
+	}
+	if err := s.checkDisputePhotos(ctx, order, dispute.PhotoURLs, authHeader); err != nil {
+		return nil, err
+	}
+
+	// Сначала тикет: без него менеджеру негде вести разбор, поэтому при ошибке спор не открываем.
+	text := dispute.Description
+	if len(dispute.PhotoURLs) > 0 {
+		text += "\n\n" + strings.Join(dispute.PhotoURLs, "\n")
+	}
+	ticketID, err := utils.CreateSupportTicket(ctx, s.cfg.SupportServiceURL, utils.SupportTicketRequest{
+		Subject:  fmt.Sprintf("Спор по заказу %s", order.ID.Hex()),
+		Text:     text,
+		OrderID:  order.ID.Hex(),
+		Category: string(dispute.Category),
+	}, authHeader)
+	if err != nil {
+		return nil, fmt.Errorf("support ticket failed: %w", err)
+	}
+
+	dispute.ClientID = clientID
+	dispute.TicketID = ticketID
+	dispute.Status = models.DisputeOpen
+	dispute.Outcome, dispute.RefundAmount, dispute.RefundID, dispute.RecleanOrderID = "", 0, "", ""
+	dispute.ResolutionNote, dispute.ResolvedBy, dispute.ResolvedAt = "", "", nil
+	dispute.CreatedAt = time.Now().UTC()
+
+	// Тикет уже создан: при конфликте версий открываем спор на свежей копии заказа.
+	apply := func(o *models.Order) error {
+		if o.Dispute != nil {
+			return models.ErrDisputeExists
+		}
+		from = o.Status
+		if err := models.ValidateTransition(from, models.StatusDisputed); err != nil {
+			return err
+		}
+		o.Status = models.StatusDisputed
+		o.Dispute = dispute
+		return nil
+	}
+	if err := apply(order); err != nil {
+		return nil, err
+	}
+	order, err = s.saveWithReload(ctx, order, apply, func(ctx context.Context, order *models.Order) error {
+		return s.inTx(ctx, func(ctx context.Context) error {
+			if err := s.repo.Update(ctx, order); err != nil {
+				return err
+			}
+			s.recordTransition(ctx, order.ID, from, models.StatusDisputed, string(dispute.Category))
+			extra := map[string]string{"category": string(dispute.Category)}
+			return s.emit(ctx, cleanerNotices(order, order.CleanerID, "dispute_opened", extra)...)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.clearCache(ctx, order.ClientID)
+	return order, nil
+}
+
+// checkDisputePhotos принимает только фото, загруженные клиентом к спору по этому заказу
+// через media-service (POST /media/dispute/:orderId): произвольные ссылки попали бы в тикет.
+func (s *orderService) checkDisputePhotos(ctx context.Context, order *models.Order, photoURLs []string, authHeader string) error {
+	if len(photoURLs) == 0 {
+		return nil
+	}
+	uploaded, err := utils.FetchDisputeEvidenceURLs(ctx, s.cfg.MediaServiceURL, order.ID.Hex(), authHeader)
+	if err != nil {
+		return fmt.Errorf("dispute evidence check failed: %w", err)
+	}
+	for _, u := range photoURLs {
+		if !containsString(uploaded, u) {
+			return fmt.Errorf("%w: photo %s was not uploaded as dispute evidence for this order", models.ErrValidation, u)
+		}
+	}
+	return nil
+}
+
+// ResolveDispute применяет решение менеджера: повторная уборка, частичный или полный возврат
+// либо отказ. Тикет в support-service закрывается, клиент и клинеры получают уведомление.
+// Решение с возвратом сначала закрепляется за спором (статус resolving) и только потом
+// уходит в payment-service: параллельное решение другого менеджера получит ErrNoOpenDispute
+// до возврата. Если возврат не прошёл, повторный вызов доводит закреплённое решение до конца.
+func (s *orderService) ResolveDispute(ctx context.Context, id primitive.ObjectID, managerID string, res *models.Resolution, authHeader string) (*models.Order, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case order.Dispute == nil:
+		return nil, models.ErrNoOpenDispute
+	case order.Dispute.Status == models.DisputeResolving:
+		res = &models.Resolution{Outcome: order.Dispute.Outcome, RefundAmount: order.Dispute.RefundAmount, Note: order.Dispute.ResolutionNote}
+	case order.Dispute.Status != models.DisputeOpen:
+		return nil, models.ErrNoOpenDispute
+	}
+	from := order.Status
+	to := res.ResultStatus()
+	if err := models.ValidateTransition(from, to); err != nil {
+		return nil, err
+	}
+	if order.Dispute.Status == models.DisputeOpen {
+		paid := s.paidAmount(ctx, order)
+		if err := res.Validate(paid); err != nil {
+			return nil, err
+		}
+		refund := res.RefundAmount
+		if res.Outcome == models.OutcomeFullRefund {
+			refund = paid
+		}
+		if refund > 0 && (res.Outcome == models.OutcomePartialRefund || res.Outcome == models.OutcomeFullRefund) {
+			if order, err = s.claimDispute(ctx, order, managerID, res, refund); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Решение готовим на копии: apply проверяет, что спор всё ещё в исходном статусе.
+	dispute := *order.Dispute
+	claimed := dispute.Status
+	if claimed == models.DisputeResolving {
+		resp, err := utils.RequestRefund(ctx, s.cfg.PaymentServiceURL, utils.RefundRequest{
+			EntityType:     "order",
+			EntityID:       order.ID.Hex(),
+			UserID:         order.ClientID,
+			Amount:         dispute.RefundAmount,
+			Reason:         fmt.Sprintf("dispute resolved (%s)", dispute.Outcome),
+			IdempotencyKey: "order_dispute:" + order.ID.Hex(),
+		}, authHeader)
+		if err != nil {
+			return nil, fmt.Errorf("refund failed, repeat the resolution to retry it: %w", err)
+		}
+		dispute.RefundID = resp.RefundID
+	} else {
+		dispute.Outcome = res.Outcome
+		dispute.ResolutionNote = res.Note
+		dispute.ResolvedBy = managerID
+	}
+
+	now := time.Now().UTC()
+	dispute.Status = models.DisputeResolved
+	dispute.ResolvedAt = &now
+	// Возврат уже сделан: при конфликте версий применяем решение к свежей копии заказа.
+	apply := func(o *models.Order) error {
+		if o.Dispute == nil || o.Dispute.Status != claimed {
+			return models.ErrNoOpenDispute
+		}
+		from = o.Status
+		if err := models.ValidateTransition(from, to); err != nil {
+			return err
+		}
+		resolved := dispute
+		o.Status = to
+		o.Dispute = &resolved
+		return nil
+	}
+	if err := apply(order); err != nil {
+		return nil, err
+	}
+	order, err = s.saveWithReload(ctx, order, apply, func(ctx context.Context, order *models.Order) error {
+		return s.inTx(ctx, func(ctx context.Context) error {
+			return s.saveResolution(ctx, order, from, res)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.clearCache(ctx, order.ClientID)
+
+	if dispute.TicketID != "" {
+		if err := utils.CloseSupportTicket(ctx, s.cfg.SupportServiceURL, dispute.TicketID, authHeader); err != nil {
+			log.Printf("[DISPUTE] Failed to close ticket %s for order %s: %v", dispute.TicketID, order.ID.Hex(), err)
+		}
+	}
+	return order, nil
+}
+
+// claimDispute закрепляет решение с возвратом за открытым спором до запроса возврата.
+// Версия заказа гарантирует, что из параллельных решений закрепится только одно.
+func (s *orderService) claimDispute(ctx context.Context, order *models.Order, managerID string, res *models.Resolution, refund float64) (*models.Order, error) {
+	apply := func(o *models.Order) error {
+		if o.Dispute == nil || o.Dispute.Status != models.DisputeOpen {
+			return models.ErrNoOpenDispute
+		}
+		claimed := *o.Dispute
+		claimed.Status = models.DisputeResolving
+		claimed.Outcome = res.Outcome
+		claimed.RefundAmount = refund
+		claimed.ResolutionNote = res.Note
+		claimed.ResolvedBy = managerID
+		o.Dispute = &claimed
+		return nil
+	}
+	if err := apply(order); err != nil {
+		return nil, err
+	}
+	return s.saveWithReload(ctx, order, apply, s.repo.Update)
+}
+
+// saveResolution сохраняет решение по спору: заказ на повторную уборку (если нужен),
+// новый статус, историю и уведомления участникам.
+func (s *orderService) saveResolution(ctx context.Context, order *models.Order, from models.OrderStatus, res *models.Resolution) error {
+	dispute := order.Dispute
+	var reclean *models.Order
+	if res.Outcome == models.OutcomeReclean {
+		reclean = order.RecleanOrder(res.RecleanDate)
+		s.applySchedule(reclean)
+		if err := s.repo.Create(ctx, reclean); err != nil {
+			return err
+		}
+		dispute.RecleanOrderID = reclean.ID.Hex()
+	}
+	if err := s.repo.Update(ctx, order); err != nil {
+		// Без транзакции (одиночный mongod) убираем уже созданную повторную уборку сами,
+		// иначе повтор после конфликта версий создаст вторую.
+		if reclean != nil {
+			if delErr := s.repo.Delete(ctx, reclean); delErr != nil {
+				log.Printf("[DISPUTE] Failed to remove reclean order %s: %v", reclean.ID.Hex(), delErr)
+			}
+			dispute.RecleanOrderID = ""
+		}
+		return err
+	}
+	if reclean != nil {
+		s.recordTransition(ctx, reclean.ID, "", reclean.Status, "reclean for disputed order "+order.ID.Hex())
+	}
+	s.recordTransition(ctx, order.ID, from, order.Status, "dispute resolved: "+string(res.Outcome))
+
+	// Возврат уменьшает выручку заказа: начисления клинерам сторнируются и при
+	// частичном возврате начисляются заново от оставшейся суммы.
+	switch res.Outcome {
+	case models.OutcomePartialRefund, models.OutcomeFullRefund:
+		if err := s.reverseEarnings(ctx, order, "dispute resolved: "+string(res.Outcome)); err != nil {
+			return err
+		}
+		if res.Outcome == models.OutcomePartialRefund {
+			if err := s.postEarnings(ctx, order); err != nil {
+				return err
+			}
+		}
+	}
+
+	extra := map[string]string{
+		"outcome":       string(res.Outcome),
+		"refund_amount": fmt.Sprintf("%.2f", dispute.RefundAmount),
+	}
+	if dispute.RecleanOrderID != "" {
+		extra["reclean_order_id"] = dispute.RecleanOrderID
+	}
+	events := append(cleanerNotices(order, order.CleanerID, "dispute_resolved", extra), clientNotice(order, "dispute_resolved", extra))
+	return s.emit(ctx, events...)
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCheckDisputePhotos(t *testing.T) {
+	order := &models.Order{ID: primitive.NewObjectID()}
+	uploaded := "http://minio:9000/media-cleaning/dispute/1_stain.jpg"
+	media := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/media/disputes/"+order.ID.Hex() {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]string{{"URL": uploaded}})
+	}))
+	defer media.Close()
+
+	svc := newTestService(newStubOrderRepo())
+	svc.cfg.MediaServiceURL = media.URL
+	ctx := context.Background()
+
+	if err := svc.checkDisputePhotos(ctx, order, []string{uploaded}, "Bearer t"); err != nil {
+		t.Fatalf("uploaded photo rejected: %v", err)
+	}
+	err := svc.checkDisputePhotos(ctx, order, []string{uploaded, "https://example.com/x.jpg"}, "Bearer t")
+	if !errors.Is(err, models.ErrValidation) {
+		t.Fatalf("foreign URL: err = %v, want ErrValidation", err)
+	}
+}
+
+// stubEarnings — начислений по заказам нет, сторнировать нечего; новые не сохраняются.
+type stubEarnings struct{ EarningsRepository }
+
+func (stubEarnings) Insert(context.Context, ...*models.LedgerEntry) error { return nil }
+
+func (stubEarnings) ActiveEarnings(context.Context, string) ([]models.LedgerEntry, error) {
+	return nil, nil
+}
+
+func disputedOrder() *models.Order {
+	return &models.Order{
+		ID:         primitive.NewObjectID(),
+		ClientID:   "client",
+		CleanerID:  []string{"cleaner"},
+		Status:     models.StatusDisputed,
+		Date:       time.Now().Add(-24 * time.Hour),
+		TotalPrice: 100,
+		Dispute:    &models.Dispute{ClientID: "client", Status: models.DisputeOpen},
+	}
+}
+
+func TestResolveDisputeRetriesClaimedRefund(t *testing.T) {
+	order := disputedOrder()
+	repo := newStubOrderRepo(order)
+	s := newTestService(repo)
+	s.earnings = stubEarnings{}
+	payments, url := newFakePayments(t, 1)
+	s.cfg.PaymentServiceURL = url
+	ctx := context.Background()
+
+	// Возврат не прошёл: решение закреплено за спором, заказ ещё в споре.
+	if _, err := s.ResolveDispute(ctx, order.ID, "m1", &models.Resolution{Outcome: models.OutcomeFullRefund}, "Bearer t"); err == nil {
+		t.Fatal("ResolveDispute succeeded although the refund failed")
+	}
+	stored := repo.orders[order.ID]
+	if stored.Status != models.StatusDisputed || stored.Dispute.Status != models.DisputeResolving || stored.Dispute.RefundAmount != 100 {
+		t.Fatalf("order after failed refund: status %s, dispute %+v", stored.Status, stored.Dispute)
+	}
+
+	// Повтор (даже с другим решением) доводит до конца закреплённое.
+	got, err := s.ResolveDispute(ctx, order.ID, "m2", &models.Resolution{Outcome: models.OutcomeRejected}, "Bearer t")
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if got.Status != models.StatusCancelled || got.Dispute.Status != models.DisputeResolved ||
+		got.Dispute.Outcome != models.OutcomeFullRefund || got.Dispute.ResolvedBy != "m1" || got.Dispute.RefundID == "" {
+		t.Errorf("resolved order: status %s, dispute %+v", got.Status, got.Dispute)
+	}
+	if len(payments.refunds) != 1 || payments.refunds[0].IdempotencyKey != "order_dispute:"+order.ID.Hex() {
+		t.Errorf("refunds = %+v", payments.refunds)
+	}
+	if _, err := s.ResolveDispute(ctx, order.ID, "m1", &models.Resolution{Outcome: models.OutcomeFullRefund}, "Bearer t"); !errors.Is(err, models.ErrNoOpenDispute) {
+		t.Errorf("resolving a resolved dispute: %v, want ErrNoOpenDispute", err)
+	}
+}
+
+func TestResolveDisputeConcurrentlyRefundsOnce(t *testing.T) {
+	order := disputedOrder()
+	repo := newStubOrderRepo(order)
+	s := newTestService(repo)
+	s.earnings = stubEarnings{}
+	payments, url := newFakePayments(t, 0)
+	s.cfg.PaymentServiceURL = url
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res := &models.Resolution{Outcome: models.OutcomePartialRefund, RefundAmount: 40}
+			_, errs[i] = s.ResolveDispute(context.Background(), order.ID, "manager", res, "Bearer t")
+		}(i)
+	}
+	wg.Wait()
+
+	lost := 0
+	for _, err := range errs {
+		if errors.Is(err, models.ErrNoOpenDispute) {
+			lost++
+		} else if err != nil {
+			t.Errorf("ResolveDispute: %v", err)
+		}
+	}
+	if lost != 1 || len(payments.refunds) != 1 {
+		t.Errorf("lost = %d, refunds = %+v; want exactly one resolution and one refund", lost, payments.refunds)
+	}
+}
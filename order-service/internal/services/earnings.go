@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EarningsRepository хранит книгу заработка клинеров (earnings_ledger)
+// и ведомости на выплату (payout_statements).
+type EarningsRepository interface {
+	Insert(ctx context.Context, entries ...*models.LedgerEntry) error
+	ActiveEarnings(ctx context.Context, orderID string) ([]models.LedgerEntry, error)
+	MarkReversed(ctx context.Context, id primitive.ObjectID) (bool, error)
+	ListUnstated(ctx context.Context, cleanerID string, before time.Time) ([]models.LedgerEntry, error)
+	ListByStatement(ctx context.Context, statementID primitive.ObjectID) ([]models.LedgerEntry, error)
+	CreateStatement(ctx context.Context, st *models.PayoutStatement, entryIDs []primitive.ObjectID) error
+	GetStatement(ctx context.Context, id primitive.ObjectID) (*models.PayoutStatement, error)
+	ListStatements(ctx context.Context, cleanerID string, status models.StatementStatus, limit int64) ([]models.PayoutStatement, error)
+	StatementTotals(ctx context.Context, cleanerID string) (map[models.StatementStatus]float64, error)
+	MarkStatementPaid(ctx context.Context, st *models.PayoutStatement) error
+}
+
+func (s *orderService) earningsPolicy() models.EarningsPolicy {
+	return models.EarningsPolicy{
+		CommissionPercent: float64(s.cfg.EarningsCommissionPercent),
+		ServiceFee:        float64(s.cfg.EarningsServiceFee),
+		ServiceFees:       s.cfg.EarningsServiceFees,
+	}
+}
+
+// postEarnings начисляет клинерам заработок за заказ. Вызывается в транзакции
+// вместе со сменой статуса; повторное начисление по тому же заказу пропускается.
+func (s *orderService) postEarnings(ctx context.Context, order *models.Order) error {
+	entries := s.earningsPolicy().OrderEarnings(order, time.Now().UTC())
+	err := s.earnings.Insert(ctx, entries...)
+	if errors.Is(err, models.ErrEarningsPosted) {
+		return nil
+	}
+	return err
+}
+
+// reverseEarnings сторнирует действующие начисления по заказу.
+func (s *orderService) reverseEarnings(ctx context.Context, order *models.Order, note string) error {
+	entries, err := s.earnings.ActiveEarnings(ctx, order.ID.Hex())
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for i := range entries {
+		ok, err := s.earnings.MarkReversed(ctx, entries[i].ID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := s.earnings.Insert(ctx, entries[i].Reverse(note, now)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetCleanerEarnings — баланс клинера, записи вне ведомостей и последние ведомости.
+func (s *orderService) GetCleanerEarnings(ctx context.Context, cleanerID string) (*models.EarningsSummary, error) {
+	entries, err := s.earnings.ListUnstated(ctx, cleanerID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.blockedOrders(ctx, entries)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.earnings.StatementTotals(ctx, cleanerID)
+	if err != nil {
+		return nil, err
+	}
+	statements, err := s.earnings.ListStatements(ctx, cleanerID, "", 20)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.EarningsSummary{
+		CleanerID:  cleanerID,
+		Unpaid:     totals[models.StatementPending],
+		PaidOut:    totals[models.StatementPaid],
+		Entries:    entries,
+		Statements: statements,
+	}
+	summary.Finalize(blocked)
+	return summary, nil
+}
+
+// blockedOrders — заказы из записей, выплата по которым заморожена спором.
+func (s *orderService) blockedOrders(ctx context.Context, entries []models.LedgerEntry) (map[string]bool, error) {
+	seen := map[string]bool{}
+	var ids []primitive.ObjectID
+	for _, e := range entries {
+		if e.OrderID == "" || seen[e.OrderID] {
+			continue
+		}
+		seen[e.OrderID] = true
+		if oid, err := primitive.ObjectIDFromHex(e.OrderID); err == nil {
+			ids = append(ids, oid)
+		}
+	}
+	blocked := map[string]bool{}
+	if len(ids) == 0 {
+		return blocked, nil
+	}
+	orders, err := s.repo.Filter(ctx, bson.M{"_id": bson.M{"$in": ids}, "status": models.StatusDisputed})
+	if err != nil {
+		return nil, err
+	}
+	for i := range orders {
+		if orders[i].PayoutBlocked() {
+			blocked[orders[i].ID.Hex()] = true
+		}
+	}
+	return blocked, nil
+}
+
+// GetStatement отдаёт ведомость с записями; клинер видит только свои.
+func (s *orderService) GetStatement(ctx context.Context, id primitive.ObjectID, userID, role string) (*models.StatementDetails, error) {
+	st, err := s.earnings.GetStatement(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isStaff(role) && st.CleanerID != userID {
+		return nil, models.ErrStatementNotFound
+	}
+	items, err := s.earnings.ListByStatement(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &models.StatementDetails{PayoutStatement: *st, Items: items}, nil
+}
+
+func (s *orderService) ListStatements(ctx context.Context, cleanerID string, status models.StatementStatus) ([]models.PayoutStatement, error) {
+	return s.earnings.ListStatements(ctx, cleanerID, status, 200)
+}
+
+// GenerateStatements закрывает период: по каждому клинеру собирает в ведомость
+// записи, созданные до periodEnd. Записи по заказам в споре остаются до решения,
+// а клинеры с неположительным итогом ведомость не получают.
+func (s *orderService) GenerateStatements(ctx context.Context, periodEnd time.Time) ([]models.PayoutStatement, error) {
+	now := time.Now().UTC()
+	if periodEnd.IsZero() || periodEnd.After(now) {
+		return nil, fmt.Errorf("%w: period_end must not be in the future", models.ErrValidation)
+	}
+	lock := newJobLock(s.redis, "payout-statements", time.Minute)
+	acquired, err := lock.tryAcquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, models.ErrStatementsConflict
+	}
+	defer func() {
+		if err := lock.release(context.WithoutCancel(ctx)); err != nil {
+			log.Println("[EARNINGS] Failed to release statements lock:", err)
+		}
+	}()
+
+	entries, err := s.earnings.ListUnstated(ctx, "", periodEnd)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.blockedOrders(ctx, entries)
+	if err != nil {
+		return nil, err
+	}
+	byCleaner := map[string][]models.LedgerEntry{}
+	var cleanerIDs []string
+	for _, e := range entries {
+		if blocked[e.OrderID] {
+			continue
+		}
+		if _, ok := byCleaner[e.CleanerID]; !ok {
+			cleanerIDs = append(cleanerIDs, e.CleanerID)
+		}
+		byCleaner[e.CleanerID] = append(byCleaner[e.CleanerID], e)
+	}
+
+	statements := []models.PayoutStatement{}
+	for _, cleanerID := range cleanerIDs {
+		items := byCleaner[cleanerID]
+		st := models.NewStatement(cleanerID, items, periodEnd, now)
+		if st == nil {
+			continue
+		}
+		ids := make([]primitive.ObjectID, len(items))
+		for i := range items {
+			ids[i] = items[i].ID
+		}
+		err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+			return s.earnings.CreateStatement(ctx, st, ids)
+		})
+		if err != nil {
+			return statements, err
+		}
+		statements = append(statements, *st)
+	}
+	return statements, nil
+}
+
+// MarkStatementPaid отмечает выплату по ведомости и списывает сумму со счёта клинера.
+func (s *orderService) MarkStatementPaid(ctx context.Context, id primitive.ObjectID, adminID, paymentRef string) (*models.PayoutStatement, error) {
+	st, err := s.earnings.GetStatement(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if st.Status == models.StatementPaid {
+		return nil, models.ErrStatementPaid
+	}
+	now := time.Now().UTC()
+	st.Status = models.StatementPaid
+	st.PaidAt = &now
+	st.PaidBy = adminID
+	st.PaymentRef = paymentRef
+	err = s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.earnings.MarkStatementPaid(ctx, st); err != nil {
+			return err
+		}
+		return s.earnings.Insert(ctx, st.Payout(now))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
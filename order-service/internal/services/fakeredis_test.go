@@ -0,0 +1,130 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeRedis — минимальный сервер RESP2 в памяти для тестов кэша: PING, GET, SET, INCR, EXPIRE, DEL.
+// Сроки жизни не отслеживаются. Остальные команды (в том числе HELLO) отвечают ошибкой —
+// go-redis тогда остаётся на RESP2.
+type fakeRedis struct {
+	mu   sync.Mutex
+	data map[string]string
+}
+
+// newFakeRedis запускает сервер и возвращает подключённый к нему клиент.
+func newFakeRedis(t *testing.T) (*fakeRedis, *redis.Client) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	f := &fakeRedis{data: map[string]string{}}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	rdb := redis.NewClient(&redis.Options{Addr: ln.Addr().String(), DisableIndentity: true, DialTimeout: time.Second})
+	t.Cleanup(func() {
+		rdb.Close()
+		ln.Close()
+	})
+	return f, rdb
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(conn, f.exec(args)); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func bulk(s string) string { return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s) }
+
+func (f *fakeRedis) exec(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		v, ok := f.data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return bulk(v)
+	case "SET":
+		f.data[args[1]] = args[2]
+		return "+OK\r\n"
+	case "INCR":
+		n, _ := strconv.ParseInt(f.data[args[1]], 10, 64)
+		n++
+		f.data[args[1]] = strconv.FormatInt(n, 10)
+		return fmt.Sprintf(":%d\r\n", n)
+	case "EXPIRE":
+		if _, ok := f.data[args[1]]; !ok {
+			return ":0\r\n"
+		}
+		return ":1\r\n"
+	case "DEL":
+		deleted := 0
+		for _, k := range args[1:] {
+			if _, ok := f.data[k]; ok {
+				delete(f.data, k)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	default:
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	}
+}
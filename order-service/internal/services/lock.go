@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// releaseScript снимает блокировку, только если она всё ещё принадлежит нам:
+// по истечении ttl её мог взять другой экземпляр.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// jobLock — блокировка в Redis, чтобы периодическую задачу в каждый момент
+// выполнял только один экземпляр order-service.
+type jobLock struct {
+	rdb   *redis.Client
+	key   string
+	ttl   time.Duration
+	token string
+}
+
+func newJobLock(rdb *redis.Client, name string, ttl time.Duration) *jobLock {
+	return &jobLock{rdb: rdb, key: "lock:job:" + name, ttl: ttl, token: primitive.NewObjectID().Hex()}
+}
+
+// tryAcquire берёт блокировку на ttl; false — задачу сейчас выполняет другой экземпляр.
+func (l *jobLock) tryAcquire(ctx context.Context) (bool, error) {
+	return l.rdb.SetNX(ctx, l.key, l.token, l.ttl).Result()
+}
+
+func (l *jobLock) release(ctx context.Context) error {
+	return releaseScript.Run(ctx, l.rdb, []string{l.key}, l.token).Err()
+}
@@ -5,133 +5,320 @@ import (
 	"cleaning-app/order-service/internal/models"
 	"cleaning-app/order-service/internal/utils"
 	"context"
-	"crypto/sha1"
-	"encoding/hex"
-	"encoding/json"
 	_ "errors"
 	"fmt"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"log"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
-// OrderRepository изменился: теперь содержит AddCleanerToOrder, RemoveCleanerFromOrder и IsCleanerBusy.
+// OrderRepository — хранилище заказов. Все записи проверяют version заказа и при
+// расхождении возвращают models.ErrVersionConflict.
 type OrderRepository interface {
 	Create(ctx context.Context, order *models.Order) error
 	Update(ctx context.Context, order *models.Order) error
-	Delete(ctx context.Context, id primitive.ObjectID) error
+	Delete(ctx context.Context, order *models.Order) error
 	GetByID(ctx context.Context, id primitive.ObjectID) (*models.Order, error)
 	GetByClientID(ctx context.Context, clientID string) ([]models.Order, error)
 	GetAll(ctx context.Context) ([]models.Order, error)
 	Filter(ctx context.Context, filter bson.M) ([]models.Order, error)
-	UnassignCleaner(ctx context.Context, id primitive.ObjectID) error
-	AddCleanerToOrder(ctx context.Context, orderID primitive.ObjectID, cleanerID string) error
-	RemoveCleanerFromOrder(ctx context.Context, orderID primitive.ObjectID, cleanerID string) error
-	IsCleanerBusy(ctx context.Context, cleanerID string, date time.Time) (bool, error)
+	Search(ctx context.Context, filter bson.M, sort bson.D, limit int64) ([]models.Order, error)
+	UpdateStatus(ctx context.Context, order *models.Order, status models.OrderStatus) error
+	MarkRemindersSent(ctx context.Context, order *models.Order, keys []string) error
+	FindCleanerConflict(ctx context.Context, cleanerID string, from, to time.Time, excludeID primitive.ObjectID) (*models.Order, error)
+	FindCleanersOrdersInRange(ctx context.Context, cleanerIDs []string, from, to time.Time) ([]models.Order, error)
+	FindAssignedInRange(ctx context.Context, from, to time.Time) ([]models.Order, error)
 	CountOrders(ctx context.Context, filter interface{}) (int64, error)
 	Aggregate(ctx context.Context, pipeline []bson.M) (*mongo.Cursor, error)
 
 	FindByCleaner(ctx context.Context, cleanerID primitive.ObjectID) ([]models.Order, error)
 	CountCompletedByCleaner(ctx context.Context, cleanerID primitive.ObjectID) (int64, error)
-	SaveOrderReview(ctx context.Context, orderID primitive.ObjectID, rating int, comment string) error
+}
+
+// StatusHistoryRepository хранит журнал смены статусов (order_status_history).
+type StatusHistoryRepository interface {
+	Create(ctx context.Context, entry *models.StatusHistoryEntry) error
+	GetByOrderID(ctx context.Context, orderID primitive.ObjectID) ([]models.StatusHistoryEntry, error)
+	LastTransitionTo(ctx context.Context, orderID primitive.ObjectID, to models.OrderStatus) (*models.StatusHistoryEntry, error)
 }
 
 type AuthClient interface {
-	AddBulkRatings(ctx context.Context, cleanerIDs []string, rating int, comment string, authHeader string) error
+	GetCleaners(ctx context.Context) ([]utils.CleanerProfile, error)
 }
 
 type orderService struct {
-	repo  OrderRepository
-	redis *redis.Client
-	cfg   *config.Config
-	auth  AuthClient
+	repo        OrderRepository
+	history     StatusHistoryRepository
+	schedules   ScheduleRepository
+	reschedules RescheduleRepository
+	promos      PromoRepository
+	addresses   AddressRepository
+	zones       ZoneRepository
+	reviews     ReviewRepository
+	earnings    EarningsRepository
+	tips        TipRepository
+	outbox      OutboxRepository
+	tx          TxRunner
+	cache       *orderCache
+	redis       *redis.Client
+	cfg         *config.Config
+	auth        AuthClient
 }
 
 // NewOrderService конструирует сервис заказов.
-func NewOrderService(repo OrderRepository, rdb *redis.Client, cfg *config.Config, auth AuthClient) *orderService {
-	return &orderService{repo: repo, redis: rdb, cfg: cfg, auth: auth}
+func NewOrderService(repo OrderRepository, history StatusHistoryRepository, schedules ScheduleRepository, reschedules RescheduleRepository, promos PromoRepository, addresses AddressRepository, zones ZoneRepository, reviews ReviewRepository, earnings EarningsRepository, tips TipRepository, outbox OutboxRepository, tx TxRunner, rdb *redis.Client, cfg *config.Config, auth AuthClient) *orderService {
+	cache := newOrderCache(rdb, time.Duration(cfg.OrderCacheTTLSeconds)*time.Second)
+	return &orderService{repo: repo, history: history, schedules: schedules, reschedules: reschedules, promos: promos, addresses: addresses, zones: zones, reviews: reviews, earnings: earnings, tips: tips, outbox: outbox, tx: tx, cache: cache, redis: rdb, cfg: cfg, auth: auth}
 }
 
-func (s *orderService) AddReview(ctx context.Context, orderID string, rating int, comment string, authHeader string) error {
-	// 1) парсим ID заказа
-	oid, err := primitive.ObjectIDFromHex(orderID)
-	if err != nil {
+// recordTransition пишет запись в order_status_history. Инициатор берётся из контекста.
+// Ошибка записи истории не откатывает уже сохранённый статус, поэтому только логируем её.
+func (s *orderService) recordTransition(ctx context.Context, orderID primitive.ObjectID, from, to models.OrderStatus, reason string) {
+	actorID, actorRole := utils.ActorFromContext(ctx)
+	entry := &models.StatusHistoryEntry{
+		OrderID:   orderID,
+		From:      from,
+		To:        to,
+		ActorID:   actorID,
+		ActorRole: actorRole,
+		Reason:    reason,
+	}
+	if err := s.history.Create(ctx, entry); err != nil {
+		log.Printf("[HISTORY] Failed to record %s -> %s for order %s: %v", from, to, orderID.Hex(), err)
+	}
+}
+
+// setStatus проверяет переход по state machine, сохраняет новый статус и пишет историю.
+func (s *orderService) setStatus(ctx context.Context, order *models.Order, to models.OrderStatus, reason string) error {
+	from := order.Status
+	if err := models.ValidateTransition(from, to); err != nil {
 		return err
 	}
-	// 2) обновляем заказ
-	if err := s.repo.SaveOrderReview(ctx, oid, rating, comment); err != nil {
+	if err := s.repo.UpdateStatus(ctx, order, to); err != nil {
 		return err
 	}
-	// 3) тянем сам заказ, чтобы получить список клинеров
-	order, err := s.repo.GetByID(ctx, oid)
+	order.Status = to
+	s.recordTransition(ctx, order.ID, from, to, reason)
+	return nil
+}
+
+// GetStatusHistory возвращает историю статусов заказа. Клиент видит только свои заказы,
+// клинер — только те, на которые назначен.
+func (s *orderService) GetStatusHistory(ctx context.Context, id primitive.ObjectID, userID, role string) ([]models.StatusHistoryEntry, error) {
+	order, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if err := checkOrderAccess(order, userID, role); err != nil {
+		return nil, err
 	}
-	// 4) отправляем рейтинг всем клинерам в Auth Service
-	//    (предполагаю, что у вас есть клиент для этого, например в utils или через service_client)
-	return s.auth.AddBulkRatings(ctx, order.CleanerID, rating, comment, authHeader)
+	return s.history.GetByOrderID(ctx, id)
 }
 
-// clearCache invalidates Redis-кэш.
-func (s *orderService) clearCache(ctx context.Context, clientID string) {
-	keys := []string{
-		fmt.Sprintf("orders_by_client:%s", clientID),
-		"all_orders",
+// checkOrderAccess — персонал видит все заказы, клинер — те, на которые назначен,
+// клиент — только свои.
+func checkOrderAccess(order *models.Order, userID, role string) error {
+	switch role {
+	case "manager", "admin":
+	case "cleaner":
+		if !containsString(order.CleanerID, userID) {
+			return models.ErrForbidden
+		}
+	default:
+		if order.ClientID != userID {
+			return models.ErrForbidden
+		}
 	}
-	s.redis.Del(ctx, keys...)
-	if fltKeys, err := s.redis.Keys(ctx, "orders_filter:*").Result(); err == nil {
-		s.redis.Del(ctx, fltKeys...)
+	return nil
+}
+
+func containsString(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
 	}
+	return false
+}
+
+// clearCache инвалидирует кэш списков клиента и всех фильтров сменой поколений.
+func (s *orderService) clearCache(ctx context.Context, clientID string) {
+	s.cache.bump(ctx, clientScope(clientID), cacheFamilyFilter)
 }
 
-// CreateOrder остаётся без изменений, кроме уведомления и кэша.
-func (s *orderService) CreateOrder(ctx context.Context, order *models.Order) error {
+// CreateOrder создаёт заказ с зафиксированной ценой: по котировке (quote_id) или по текущему каталогу.
+func (s *orderService) CreateOrder(ctx context.Context, order *models.Order) (err error) {
+	// Цену и описание услуг из тела запроса не принимаем — считаем сами или берём из котировки.
+	order.Pricing = nil
+	order.ServiceDetails = nil
+	order.WorkLogs, order.StartedAt, order.WorkedMinutes = nil, nil, 0
+	var quote *models.Quote
+	if order.QuoteID != "" {
+		q, err := s.applyQuote(ctx, order)
+		if err != nil {
+			return err
+		}
+		quote = q
+		// Котировка уже забрана из Redis: если заказ не создан, клиент сможет повторить.
+		defer func() {
+			if err != nil {
+				s.restoreQuote(ctx, quote)
+			}
+		}()
+	}
+	if err := s.resolveAddress(ctx, order); err != nil {
+		return err
+	}
+	if _, err := s.resolveZone(ctx, order); err != nil {
+		return err
+	}
+	if quote != nil && quote.ZoneID != order.ZoneID {
+		return fmt.Errorf("%w: quote was issued for a different service zone", models.ErrValidation)
+	}
 	if err := order.Validate(); err != nil {
 		return err
 	}
-	s.enrichWithServiceDetails(ctx, order)
+	var promo *models.PromoCode
+	if quote == nil {
+		if err := s.priceOrder(ctx, order); err != nil {
+			return err
+		}
+		if order.PromoCode != "" {
+			p, err := s.applyPromo(ctx, order.ClientID, order.PromoCode, order.Pricing)
+			if err != nil {
+				return err
+			}
+			promo = p
+			order.PromoCode = p.Code
+			order.TotalPrice = order.Pricing.Total
+		}
+	} else if order.PromoCode != "" {
+		// Скидка уже в котировке; проверяем, что код всё ещё действует.
+		p, err := s.checkPromo(ctx, order.ClientID, order.PromoCode)
+		if err != nil {
+			return err
+		}
+		promo = p
+	}
+	s.applySchedule(order)
+	order.Checklist = models.BuildChecklist(order.ServiceDetails)
 	if order.Status != models.StatusPrePaid {
 		order.Status = models.StatusPending
 	}
 
+	// Резервируем использование кода атомарно до создания заказа и возвращаем при неудаче.
+	if promo != nil {
+		if err := s.reservePromo(ctx, promo, order.ClientID); err != nil {
+			return err
+		}
+	}
 	if err := s.repo.Create(ctx, order); err != nil {
+		if promo != nil {
+			s.releasePromo(ctx, promo, order.ClientID)
+		}
 		return err
 	}
+	if promo != nil {
+		s.recordRedemption(ctx, promo, order)
+	}
+	s.recordTransition(ctx, order.ID, "", order.Status, "order created")
 	s.clearCache(ctx, order.ClientID)
 	return nil
 }
 
-// UpdateOrder без изменений.
+// UpdateOrder обновляет редактируемые поля заказа. Дату заказа с назначенными клинерами
+// так поменять нельзя — только через согласование переноса (RequestReschedule).
+// Если клиент прислал version, которую видел, при расхождении сразу возвращается
+// ErrVersionConflict (409); без version изменения применяются к свежей копии.
 func (s *orderService) UpdateOrder(ctx context.Context, id primitive.ObjectID, updated *models.Order) error {
+	if updated.Version != 0 {
+		return s.updateOrder(ctx, id, updated)
+	}
+	return retryOnConflict(func() error { return s.updateOrder(ctx, id, updated) })
+}
+
+func (s *orderService) updateOrder(ctx context.Context, id primitive.ObjectID, updated *models.Order) error {
 	existing, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	if updated.Version != 0 && updated.Version != existing.Version {
+		return fmt.Errorf("%w: have version %d, current is %d", models.ErrVersionConflict, updated.Version, existing.Version)
+	}
 	updated.ID = id
-	existing.Address = updated.Address
+	if !updated.Date.IsZero() && !updated.Date.Equal(existing.Date) {
+		if len(existing.CleanerID) > 0 {
+			return models.ErrRescheduleRequired
+		}
+		existing.Date = updated.Date
+		existing.RemindersSent = nil
+	}
+	// Смена услуг меняет цену — это только через явный пересчёт (RequoteOrder).
+	if len(updated.ServiceIDs) > 0 && !sameServices(updated.ServiceIDs, existing.ServiceIDs) {
+		return models.ErrRequoteRequired
+	}
+	addressChanged := true
+	switch {
+	case updated.AddressID != "" && updated.AddressID != existing.AddressID:
+		existing.AddressID, existing.Location = updated.AddressID, updated.Location
+		if err := s.resolveAddress(ctx, existing); err != nil {
+			return err
+		}
+	case updated.AddressID == "" && updated.Address != "":
+		// Свободная строка вместо сохранённого адреса — старая копия и координаты больше
+		// не актуальны; зона определится заново по координатам из запроса.
+		existing.Address = updated.Address
+		existing.AddressID, existing.AddressDetails = "", nil
+		existing.Location, existing.ZoneID = updated.Location, ""
+	default:
+		addressChanged = false
+	}
+	if addressChanged {
+		if err := s.rezoneOrder(ctx, existing); err != nil {
+			return err
+		}
+	}
 	existing.ServiceType = updated.ServiceType
-	existing.Date = updated.Date
 	existing.Comment = updated.Comment
-	existing.ServiceIDs = updated.ServiceIDs
 
 	s.enrichWithServiceDetails(ctx, existing)
-	if err := s.repo.Update(ctx, existing); err != nil {
+	s.applySchedule(existing)
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, existing); err != nil {
+			return err
+		}
+		events := append(cleanerNotices(existing, existing.CleanerID, "order_updated", nil), clientNotice(existing, "order_updated", nil))
+		return s.emit(ctx, events...)
+	})
+	if err != nil {
 		return err
 	}
 	s.clearCache(ctx, existing.ClientID)
 	return nil
 }
 
-// DeleteOrder без изменений.
+// DeleteOrder удаляет заказ и уведомляет участников.
 func (s *orderService) DeleteOrder(ctx context.Context, id primitive.ObjectID) error {
+	return retryOnConflict(func() error { return s.deleteOrder(ctx, id) })
+}
+
+func (s *orderService) deleteOrder(ctx context.Context, id primitive.ObjectID) error {
 	order, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
-	if err := s.repo.Delete(ctx, id); err != nil {
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, order); err != nil {
+			return err
+		}
+		events := append(cleanerNotices(order, order.CleanerID, "order_deleted", nil), clientNotice(order, "order_deleted", nil))
+		return s.emit(ctx, events...)
+	})
+	if err != nil {
 		return err
 	}
 	s.clearCache(ctx, order.ClientID)
@@ -139,25 +326,64 @@ func (s *orderService) DeleteOrder(ctx context.Context, id primitive.ObjectID) e
 }
 
 // ---------------- НОВЫЙ МЕТОД: AssignCleaners --------------------
-// AssignCleaners принимает массив cleanerIDs и пытается добавить каждого клинера.
+// AssignCleaners принимает массив cleanerIDs и назначает их всех или никого: все проверки
+// идут до записи, а клинеры и статус сохраняются одной записью с проверкой версии.
 func (s *orderService) AssignCleaners(ctx context.Context, id primitive.ObjectID, cleanerIDs []string) error {
+	return retryOnConflict(func() error { return s.assignCleaners(ctx, id, cleanerIDs, false) })
+}
+
+// assignCleaners добавляет клинеров к заказу. С onlyMissing (автоназначение) заказ
+// перечитывается при каждой попытке и клинеров сверх CleanersRequired не добавляется:
+// параллельный проход или повтор после конфликта версий мог уже доукомплектовать заказ.
+func (s *orderService) assignCleaners(ctx context.Context, id primitive.ObjectID, cleanerIDs []string, onlyMissing bool) error {
 	// Получим сам заказ, чтобы знать дату и clientID для кэша:
 	order, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	// После добавления любого клинера переведём статус в Assigned:
-	for _, cleanerID := range cleanerIDs {
-		// Проверяем занятость и добавляем:
-		if err := s.repo.AddCleanerToOrder(ctx, id, cleanerID); err != nil {
-			// Если один из клинеров занят, возвращаем ошибку и не продолжаем дальше.
+	// Назначать можно только оплаченный заказ (или докинуть клинера в уже назначенный).
+	from := order.Status
+	if from != models.StatusAssigned {
+		if err := models.ValidateTransition(from, models.StatusAssigned); err != nil {
+			return err
+		}
+	}
+	if onlyMissing {
+		required := order.CleanersRequired
+		if required < 1 {
+			required = 1
+		}
+		if missing := required - len(order.CleanerID); len(cleanerIDs) > missing {
+			return fmt.Errorf("%w: order needs %d more cleaner(s), got %d", models.ErrValidation, max(missing, 0), len(cleanerIDs))
+		}
+	}
+
+	for i, cleanerID := range cleanerIDs {
+		if containsString(order.CleanerID, cleanerID) || containsString(cleanerIDs[:i], cleanerID) {
+			return fmt.Errorf("cannot assign cleaner %s: %w: already assigned to this order", cleanerID, models.ErrValidation)
+		}
+		// Проверяем график и занятость по пересечению интервалов:
+		if err := s.checkWorkingHours(ctx, order, cleanerID); err != nil {
+			return fmt.Errorf("cannot assign cleaner %s: %w", cleanerID, err)
+		}
+		if err := s.checkCleanerAvailability(ctx, order, cleanerID); err != nil {
 			return fmt.Errorf("cannot assign cleaner %s: %w", cleanerID, err)
 		}
 	}
 
+	order.CleanerID = append(order.CleanerID, cleanerIDs...)
 	order.Status = models.StatusAssigned
-	if err := s.repo.Update(ctx, order); err != nil {
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, order); err != nil {
+			return err
+		}
+		if from != models.StatusAssigned {
+			s.recordTransition(ctx, order.ID, from, models.StatusAssigned, "cleaners assigned")
+		}
+		return s.emit(ctx, cleanerNotices(order, cleanerIDs, "assigned_order", nil)...)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -166,50 +392,143 @@ func (s *orderService) AssignCleaners(ctx context.Context, id primitive.ObjectID
 	return nil
 }
 
+// applySchedule считает длительность заказа по выбранным услугам, время окончания
+// и сколько клинеров нужно. Если у услуг длительность не задана (или детали не
+// подтянулись) — берём значение из конфига.
+func (s *orderService) applySchedule(order *models.Order) {
+	total := 0
+	required := 1
+	for _, svc := range order.ServiceDetails {
+		total += svc.DurationMinutes
+		if svc.CleanersRequired > required {
+			required = svc.CleanersRequired
+		}
+	}
+	order.CleanersRequired = required
+	if total == 0 {
+		total = s.cfg.DefaultOrderDurationMinutes
+	}
+	order.DurationMinutes = total
+	order.EndDate = order.Date.Add(time.Duration(total) * time.Minute)
+}
+
+// orderInterval возвращает [начало, конец) заказа; для старых заказов без end_date
+// используется длительность по умолчанию.
+func (s *orderService) orderInterval(order *models.Order) (time.Time, time.Time) {
+	if !order.EndDate.IsZero() {
+		return order.Date, order.EndDate
+	}
+	return order.Date, order.Date.Add(time.Duration(s.cfg.DefaultOrderDurationMinutes) * time.Minute)
+}
+
+// checkCleanerAvailability возвращает *models.CleanerBusyError, если интервал заказа
+// (с буфером на дорогу с обеих сторон) пересекается с другим активным заказом клинера.
+func (s *orderService) checkCleanerAvailability(ctx context.Context, order *models.Order, cleanerID string) error {
+	start, end := s.orderInterval(order)
+	buffer := time.Duration(s.cfg.TravelBufferMinutes) * time.Minute
+	conflict, err := s.repo.FindCleanerConflict(ctx, cleanerID, start.Add(-buffer), end.Add(buffer), order.ID)
+	if err != nil {
+		return err
+	}
+	if conflict == nil {
+		return nil
+	}
+	return s.cleanerBusyError(cleanerID, conflict)
+}
+
+func (s *orderService) cleanerBusyError(cleanerID string, conflict *models.Order) *models.CleanerBusyError {
+	conflictStart, conflictEnd := s.orderInterval(conflict)
+	return &models.CleanerBusyError{
+		CleanerID:       cleanerID,
+		ConflictOrderID: conflict.ID.Hex(),
+		ConflictStart:   conflictStart,
+		ConflictEnd:     conflictEnd,
+	}
+}
+
 // ---------------- Переопределён AssignCleaner (оставлен для совместимости) ---------------
 // Теперь AssignCleaner просто оборачивает AssignCleaners с одним элементом массива.
 func (s *orderService) AssignCleaner(ctx context.Context, id primitive.ObjectID, cleanerID string) error {
 	return s.AssignCleaners(ctx, id, []string{cleanerID})
 }
 
-// UnassignCleaner вызывает RemoveCleanerFromOrder для одного клинера.
-// Если передавать empty string — можно вызвать repo.UnassignCleaner, но лучше явно убирать одного.
+// UnassignCleaner снимает одного клинера с заказа. Если от него ждали ответа на перенос
+// и больше ждать не от кого, запрос на перенос разрешается.
 func (s *orderService) UnassignCleaner(ctx context.Context, id primitive.ObjectID, cleanerID string) error {
-	// 1) Берём изначальный заказ (для clearCache)
-	origOrder, err := s.repo.GetByID(ctx, id)
-	if err != nil {
+	if err := retryOnConflict(func() error { return s.unassignCleaner(ctx, id, cleanerID) }); err != nil {
 		return err
 	}
-
-	// 2) Убираем клинера (больше не меняем статус здесь)
-	if err := s.repo.RemoveCleanerFromOrder(ctx, id, cleanerID); err != nil {
-		return err
+	if err := s.settleReschedule(ctx, id); err != nil {
+		log.Printf("[RESCHEDULE] Failed to settle reschedule of order %s after unassigning %s: %v", id.Hex(), cleanerID, err)
 	}
+	return nil
+}
 
-	// 3) Перезапрашиваем заказ после удаления
-	updatedOrder, err := s.repo.GetByID(ctx, id)
+func (s *orderService) unassignCleaner(ctx context.Context, id primitive.ObjectID, cleanerID string) error {
+	// 1) Берём заказ
+	order, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	if !containsString(order.CleanerID, cleanerID) {
+		return nil
+	}
 
-	// 4) Если после удаления не осталось ни одного клинера — переводим в paid
-	if len(updatedOrder.CleanerID) == 0 {
-		updatedOrder.Status = models.StatusPaid
-		updatedOrder.UpdatedAt = time.Now() // не забудьте импортировать time
-
-		if err := s.repo.Update(ctx, updatedOrder); err != nil {
-			return fmt.Errorf("failed to set status to paid: %w", err)
+	// 2) Если уходит последний клинер — заказ возвращается в тот оплаченный статус,
+	//    из которого его назначили (paid или prepaid). Проверяем переход заранее.
+	from := order.Status
+	remaining := make([]string, 0, len(order.CleanerID))
+	for _, cid := range order.CleanerID {
+		if cid != cleanerID {
+			remaining = append(remaining, cid)
 		}
 	}
+	emptied := len(remaining) == 0
+	if emptied {
+		rollbackTo := s.statusBeforeAssignment(ctx, order)
+		if err := models.ValidateTransition(from, rollbackTo); err != nil {
+			return err
+		}
+		order.Status = rollbackTo
+	}
 	// иначе — статус остаётся прежним (assigned)
 
-	// 5) Сбрасываем кеш по клиенту
-	s.clearCache(ctx, origOrder.ClientID)
+	// 3) Убираем клинера и, если нужно, откатываем статус одной записью
+	order.CleanerID = remaining
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, order); err != nil {
+			return err
+		}
+		if emptied {
+			s.recordTransition(ctx, order.ID, from, order.Status, "last cleaner unassigned")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 4) Сбрасываем кеш по клиенту
+	s.clearCache(ctx, order.ClientID)
 	return nil
 }
 
+// statusBeforeAssignment определяет по истории, из какого статуса заказ был назначен.
+// Для заказов без истории (созданных до её появления) считаем, что это paid.
+func (s *orderService) statusBeforeAssignment(ctx context.Context, order *models.Order) models.OrderStatus {
+	entry, err := s.history.LastTransitionTo(ctx, order.ID, models.StatusAssigned)
+	if err != nil || entry.From != models.StatusPrePaid {
+		return models.StatusPaid
+	}
+	return models.StatusPrePaid
+}
+
 // ConfirmCompletion — помечаем заказ как DONE, чистим кэш и начисляем XP:
 func (s *orderService) ConfirmCompletion(ctx context.Context, id primitive.ObjectID, photoURL string) error {
+	return retryOnConflict(func() error { return s.confirmCompletion(ctx, id, photoURL) })
+}
+
+func (s *orderService) confirmCompletion(ctx context.Context, id primitive.ObjectID, photoURL string) error {
 	// 1. Вычитываем заказ
 	order, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -217,25 +536,48 @@ func (s *orderService) ConfirmCompletion(ctx context.Context, id primitive.Objec
 	}
 
 	// 2. Помечаем статус в модели и сохраняем
+	from := order.Status
+	if err := models.ValidateTransition(from, models.StatusCompleted); err != nil {
+		return err
+	}
+	if err := order.ChecklistComplete(); err != nil {
+		return err
+	}
 	order.Status = models.StatusCompleted
 	order.PhotoURL = &photoURL
-	if err := s.repo.Update(ctx, order); err != nil {
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, order); err != nil {
+			return err
+		}
+		s.recordTransition(ctx, order.ID, from, models.StatusCompleted, "completion confirmed")
+		if err := s.postEarnings(ctx, order); err != nil {
+			return err
+		}
+
+		// 3. Уведомления и начисление XP
+		events := s.completionEvents(order)
+		for _, cleanerID := range order.CleanerID {
+			events = append(events, models.NewXPEvent(order.ID.Hex(), cleanerID, 10))
+		}
+		events = append(events, models.NewXPEvent(order.ID.Hex(), order.ClientID, 5))
+		return s.emit(ctx, events...)
+	})
+	if err != nil {
 		return err
 	}
 
-	// 3. Чистим кэш
+	// 4. Чистим кэш
 	s.clearCache(ctx, order.ClientID)
+	return nil
+}
 
-	//4. Начисляем XP
-
-	for _, cleanerID := range order.CleanerID {
-		utils.SendGamificationXP(s.cfg, cleanerID, 10)
+// completionEvents — уведомление о завершении уборки и отложенный запрос отзыва.
+func (s *orderService) completionEvents(order *models.Order) []*models.OutboxEvent {
+	delay := time.Duration(s.cfg.ReviewRequestDelayMinutes) * time.Minute
+	return []*models.OutboxEvent{
+		clientNotice(order, "cleaning_completed", nil),
+		clientNotice(order, "review_request", nil).Delay(delay),
 	}
-
-	clientID := order.ClientID // строка
-	utils.SendGamificationXP(s.cfg, clientID, 5)
-
-	return nil
 }
 
 // GetOrderByID без изменений.
@@ -248,82 +590,35 @@ func (s *orderService) GetOrderByID(ctx context.Context, id primitive.ObjectID)
 	return order, nil
 }
 
-// GetAllOrders без изменений.
-func (s *orderService) GetAllOrders(ctx context.Context) ([]models.Order, error) {
-	orders, err := s.repo.GetAll(ctx)
-	if err != nil {
-		return nil, err
-	}
-	for i := range orders {
-		s.enrichWithServiceDetails(ctx, &orders[i])
-	}
-
-	return orders, nil
-}
-
-// GetOrdersByClient без изменений.
+// GetOrdersByClient возвращает заказы клиента, кэшируя список в поколении клиента.
 func (s *orderService) GetOrdersByClient(ctx context.Context, clientID string) ([]models.Order, error) {
-	cacheKey := fmt.Sprintf("orders_by_client:%s", clientID)
+	cacheKey := s.cache.key(ctx, cacheFamilyClient, clientScope(clientID), clientID)
 	var result []models.Order
-	if data, err := s.redis.Get(ctx, cacheKey).Result(); err == nil {
-		if err := json.Unmarshal([]byte(data), &result); err == nil {
-			for i := range result {
-				s.enrichWithServiceDetails(ctx, &result[i])
-			}
-			return result, nil
-		}
+	if s.cache.get(ctx, cacheFamilyClient, cacheKey, &result) {
+		return result, nil
 	}
 	orders, err := s.repo.GetByClientID(ctx, clientID)
 	if err != nil {
 		return nil, err
 	}
-	for i := range orders {
-		s.enrichWithServiceDetails(ctx, &orders[i])
-	}
-	if data, err := json.Marshal(orders); err == nil {
-		s.redis.Set(ctx, cacheKey, data, 5*time.Minute)
-	}
-	return orders, nil
-}
-
-// FilterOrders без изменений.
-func (s *orderService) FilterOrders(ctx context.Context, filter map[string]interface{}) ([]models.Order, error) {
-	b, _ := json.Marshal(filter)
-	hash := sha1.Sum(b)
-	cacheKey := fmt.Sprintf("orders_filter:%s", hex.EncodeToString(hash[:]))
-	var result []models.Order
-	if data, err := s.redis.Get(ctx, cacheKey).Result(); err == nil {
-		if err := json.Unmarshal([]byte(data), &result); err == nil {
-			for i := range result {
-				s.enrichWithServiceDetails(ctx, &result[i])
-			}
-			return result, nil
-		}
-	}
-	orders, err := s.repo.Filter(ctx, bson.M(filter))
-	if err != nil {
-		return nil, err
-	}
-	for i := range orders {
-		s.enrichWithServiceDetails(ctx, &orders[i])
-	}
-	if data, err := json.Marshal(orders); err == nil {
-		s.redis.Set(ctx, cacheKey, data, 5*time.Minute)
-	}
+	s.enrichOrders(ctx, orders)
+	s.cache.set(ctx, cacheKey, orders)
 	return orders, nil
 }
 
 // GetActiveOrdersCount без изменений.
 func (s *orderService) GetActiveOrdersCount(ctx context.Context) (int64, error) {
-	filter := bson.M{"status": bson.M{"$in": []string{string(models.StatusPending), string(models.StatusAssigned)}}}
+	filter := bson.M{"status": bson.M{"$in": []string{string(models.StatusPending), string(models.StatusAssigned), string(models.StatusInProgress)}}}
 	return s.repo.CountOrders(ctx, filter)
 }
 
-// GetTotalRevenue без изменений.
+// GetTotalRevenue — выручка за всё время по оплаченным заказам (models.RevenueStatuses)
+// за вычетом возвратов по спорам плюс штрафы за отмену; считается так же, как в отчётах /analytics.
 func (s *orderService) GetTotalRevenue(ctx context.Context) (float64, error) {
 	pipeline := []bson.M{
-		{"$match": bson.M{"status": models.StatusCompleted}},
-		{"$group": bson.M{"_id": nil, "total": bson.M{"$sum": "$total_price"}}},
+		{"$match": revenueMatch(nil)},
+		netRevenueStage,
+		{"$group": bson.M{"_id": nil, "total": bson.M{"$sum": "$net"}}},
 	}
 	cursor, err := s.repo.Aggregate(ctx, pipeline)
 	if err != nil {
@@ -341,9 +636,10 @@ func (s *orderService) GetTotalRevenue(ctx context.Context) (float64, error) {
 	return out[0].Total, nil
 }
 
-// enrichWithServiceDetails без изменений.
+// enrichWithServiceDetails подтягивает описание услуг для старых заказов, у которых
+// его нет в документе. Цена не пересчитывается: она зафиксирована в order.Pricing.
 func (s *orderService) enrichWithServiceDetails(ctx context.Context, order *models.Order) {
-	if len(order.ServiceIDs) == 0 {
+	if len(order.ServiceIDs) == 0 || len(order.ServiceDetails) > 0 {
 		return
 	}
 	services, err := utils.FetchServiceDetails(ctx, s.cfg.CleaningDetailsURL, order.ServiceIDs)
@@ -351,14 +647,13 @@ func (s *orderService) enrichWithServiceDetails(ctx context.Context, order *mode
 		return
 	}
 	order.ServiceDetails = services
-	total := 0.0
-	for _, svc := range services {
-		total += svc.Price
-	}
-	order.TotalPrice = total
 }
 
 func (s *orderService) UpdatePaymentStatus(ctx context.Context, orderID string, status string) error {
+	return retryOnConflict(func() error { return s.updatePaymentStatus(ctx, orderID, status) })
+}
+
+func (s *orderService) updatePaymentStatus(ctx context.Context, orderID string, status string) error {
 	id, err := primitive.ObjectIDFromHex(orderID)
 	if err != nil {
 		return fmt.Errorf("invalid order id: %w", err)
@@ -368,17 +663,26 @@ func (s *orderService) UpdatePaymentStatus(ctx context.Context, orderID string,
 		return fmt.Errorf("order not found: %w", err)
 	}
 
-	if order.Status == models.StatusPrePaid {
+	extra := map[string]string{"amount": fmt.Sprintf("%.2f", order.TotalPrice)}
+	// Неуспешный платёж статус не меняет, а повторное уведомление по уже
+	// оплаченному (или дальше продвинувшемуся) заказу — не ошибка.
+	if status != "success" {
+		return s.emit(ctx, clientNotice(order, "payment_failed", extra))
+	}
+	if order.Status != models.StatusPending {
 		return nil
 	}
 
-	order.Status = models.StatusPaid
-	if err := s.repo.Update(ctx, order); err != nil {
-		return fmt.Errorf("failed to update status: %w", err)
-	}
-	if err := s.repo.Update(ctx, order); err != nil {
-		return fmt.Errorf("failed to update status: %w", err)
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		if err := s.setStatus(ctx, order, models.StatusPaid, "payment received"); err != nil {
+			return fmt.Errorf("failed to update status: %w", err)
+		}
+		return s.emit(ctx, clientNotice(order, "payment_successful", extra))
+	})
+	if err != nil {
+		return err
 	}
+	s.clearCache(ctx, order.ClientID)
 	return nil
 }
 
@@ -405,7 +709,11 @@ func (s *orderService) GetOrderForCleaner(
 	return nil, err
 }
 
-func (s *orderService) FinishOrder(
+func (s *orderService) FinishOrder(ctx context.Context, orderID primitive.ObjectID, cleanerID primitive.ObjectID, photoURL string) error {
+	return retryOnConflict(func() error { return s.finishOrder(ctx, orderID, cleanerID, photoURL) })
+}
+
+func (s *orderService) finishOrder(
 	ctx context.Context,
 	orderID primitive.ObjectID,
 	cleanerID primitive.ObjectID,
@@ -424,6 +732,14 @@ func (s *orderService) FinishOrder(
 		}
 	}
 	if !allowed {
+		return models.ErrForbidden
+	}
+
+	from := order.Status
+	if err := models.ValidateTransition(from, models.StatusCompleted); err != nil {
+		return err
+	}
+	if err := order.ChecklistComplete(); err != nil {
 		return err
 	}
 
@@ -432,7 +748,21 @@ func (s *orderService) FinishOrder(
 	order.PhotoURL = &photoURL            // сохраняем ссылку на загруженное фото
 	order.UpdatedAt = time.Now().UTC()    // фиксим время завершения
 	// UpdatedAt будет поправлено в самом Update-методе репозитория:
-	return s.repo.Update(ctx, order)
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, order); err != nil {
+			return err
+		}
+		s.recordTransition(ctx, order.ID, from, models.StatusCompleted, "finished by cleaner")
+		if err := s.postEarnings(ctx, order); err != nil {
+			return err
+		}
+		return s.emit(ctx, s.completionEvents(order)...)
+	})
+	if err != nil {
+		return err
+	}
+	s.clearCache(ctx, order.ClientID)
+	return nil
 }
 
 func (s *orderService) CountJobsDone(ctx context.Context, cleanerID primitive.ObjectID) (int64, error) {
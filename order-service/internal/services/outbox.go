@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"cleaning-app/order-service/internal/config"
+	"cleaning-app/order-service/internal/models"
+	"cleaning-app/order-service/internal/utils"
+)
+
+// OutboxRepository хранит исходящие события (outbox) до их доставки.
+type OutboxRepository interface {
+	Insert(ctx context.Context, events ...*models.OutboxEvent) error
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutboxEvent, error)
+	MarkDelivered(ctx context.Context, e *models.OutboxEvent, at time.Time) error
+	SaveAttempt(ctx context.Context, e *models.OutboxEvent) error
+}
+
+// TxRunner выполняет несколько записей в MongoDB атомарно.
+type TxRunner interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// inTx выполняет изменение заказа и запись его событий в одной транзакции.
+func (s *orderService) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.tx.WithTx(ctx, fn)
+}
+
+// emit кладёт события в outbox; доставкой занимается OutboxRelay.
+func (s *orderService) emit(ctx context.Context, events ...*models.OutboxEvent) error {
+	return s.outbox.Insert(ctx, events...)
+}
+
+func orderExtra(order *models.Order, extra map[string]string) map[string]string {
+	if extra == nil {
+		extra = map[string]string{}
+	}
+	if _, ok := extra["order_id"]; !ok {
+		extra["order_id"] = order.ID.Hex()
+	}
+	return extra
+}
+
+// clientNotice — уведомление клиенту заказа; order_id добавляется в extra автоматически.
+func clientNotice(order *models.Order, eventType string, extra map[string]string) *models.OutboxEvent {
+	return models.NewNotificationEvent(order.ID.Hex(), order.ClientID, "client", eventType, orderExtra(order, extra))
+}
+
+// cleanerNotices — одинаковые уведомления каждому из клинеров cleanerIDs.
+func cleanerNotices(order *models.Order, cleanerIDs []string, eventType string, extra map[string]string) []*models.OutboxEvent {
+	events := make([]*models.OutboxEvent, 0, len(cleanerIDs))
+	for _, cleanerID := range cleanerIDs {
+		events = append(events, models.NewNotificationEvent(order.ID.Hex(), cleanerID, "cleaner", eventType, orderExtra(order, extra)))
+	}
+	return events
+}
+
+// OutboxRelay периодически забирает готовые события из outbox и доставляет их
+// с повторами и экспоненциальной задержкой. Доставка «хотя бы один раз»:
+// событие может прийти повторно, получатели отсекают дубли по idempotency_key.
+type OutboxRelay struct {
+	repo OutboxRepository
+	cfg  *config.Config
+}
+
+// outboxLease — на сколько захватывается событие; за это время доставка должна завершиться,
+// иначе событие заберёт следующий проход (возможно, другой экземпляр сервиса).
+const outboxLease = time.Minute
+
+func NewOutboxRelay(repo OutboxRepository, cfg *config.Config) *OutboxRelay {
+	return &OutboxRelay{repo: repo, cfg: cfg}
+}
+
+func (r *OutboxRelay) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(time.Duration(r.cfg.OutboxPollSeconds) * time.Second)
+		for {
+			select {
+			case <-ticker.C:
+				r.run(ctx)
+			case <-ctx.Done():
+				log.Println("[OUTBOX] Stopping outbox relay")
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+func (r *OutboxRelay) run(ctx context.Context) {
+	for {
+		events, err := r.repo.ClaimDue(ctx, time.Now().UTC(), outboxLease, r.cfg.OutboxBatchSize)
+		if err != nil {
+			log.Println("[OUTBOX] Failed to claim events:", err)
+		}
+		for i := range events {
+			r.process(ctx, &events[i])
+		}
+		if len(events) < r.cfg.OutboxBatchSize || ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (r *OutboxRelay) process(ctx context.Context, e *models.OutboxEvent) {
+	if err := r.deliver(ctx, e); err != nil {
+		e.MarkFailed(err, time.Now().UTC())
+		if e.Status == models.OutboxDead {
+			log.Printf("[OUTBOX] Event %s gave up after %d attempts: %v", e.IdempotencyKey, e.Attempts, err)
+		}
+		if err := r.repo.SaveAttempt(ctx, e); err != nil {
+			log.Printf("[OUTBOX] Failed to save attempt for %s: %v", e.IdempotencyKey, err)
+		}
+		return
+	}
+	if err := r.repo.MarkDelivered(ctx, e, time.Now().UTC()); err != nil {
+		// Событие уйдёт повторно после истечения захвата — получатель отсечёт дубль.
+		log.Printf("[OUTBOX] Failed to mark %s delivered: %v", e.IdempotencyKey, err)
+	}
+}
+
+func (r *OutboxRelay) deliver(ctx context.Context, e *models.OutboxEvent) error {
+	switch {
+	case e.Kind == models.OutboxNotification && e.Notification != nil:
+		return utils.DeliverNotificationEvent(ctx, utils.NotificationEvent{
+			UserID:         e.Notification.UserID,
+			Role:           e.Notification.Role,
+			Type:           e.Notification.Type,
+			ExtraData:      e.Notification.ExtraData,
+			IdempotencyKey: e.IdempotencyKey,
+		})
+	case e.Kind == models.OutboxGamificationXP && e.XP != nil:
+		return utils.AddGamificationXP(ctx, r.cfg, e.XP.UserID, e.XP.XP, e.IdempotencyKey)
+	case e.Kind == models.OutboxCleanerRating && e.Rating != nil:
+		return utils.PushCleanerRating(ctx, r.cfg, e.Rating.CleanerID, e.Rating.Average, e.Rating.Count, e.Rating.AsOf)
+	}
+	return fmt.Errorf("unknown outbox event kind %q", e.Kind)
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+	"cleaning-app/order-service/internal/utils"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (s *orderService) pricingRules() models.PricingRules {
+	return models.PricingRules{
+		WeekendPercent:     float64(s.cfg.WeekendSurchargePercent),
+		ShortNoticeHours:   s.cfg.ShortNoticeHours,
+		ShortNoticePercent: float64(s.cfg.ShortNoticeSurchargePercent),
+		MinOrderValue:      float64(s.cfg.MinOrderValue),
+		TaxPercent:         float64(s.cfg.TaxPercent),
+		Location:           s.location(),
+	}
+}
+
+// priceServices подтягивает услуги из каталога и считает по ним цену на дату
+// с множителем зоны (zone может быть nil).
+func (s *orderService) priceServices(ctx context.Context, serviceIDs []string, size *models.PropertySize, date time.Time, zone *models.Zone) ([]models.Service, *models.PriceBreakdown, error) {
+	var services []models.Service
+	if len(serviceIDs) > 0 {
+		fetched, err := utils.FetchServiceDetails(ctx, s.cfg.CleaningDetailsURL, serviceIDs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to price order: %w", err)
+		}
+		services = fetched
+	}
+	rules := s.pricingRules()
+	if zone != nil {
+		rules.ZoneMultiplier = zone.PriceMultiplier
+	}
+	breakdown, err := rules.Build(services, size, date, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	return services, breakdown, nil
+}
+
+// priceOrder фиксирует расчёт стоимости в заказе. Вызывается только при создании
+// и явном пересчёте — чтение заказа цену не трогает.
+func (s *orderService) priceOrder(ctx context.Context, order *models.Order) error {
+	services, breakdown, err := s.priceServices(ctx, order.ServiceIDs, order.Property, order.Date, s.orderZone(ctx, order))
+	if err != nil {
+		return err
+	}
+	if len(services) > 0 {
+		order.ServiceDetails = services
+	}
+	order.Pricing = breakdown
+	order.TotalPrice = breakdown.Total
+	return nil
+}
+
+// repriceOrder пересчитывает цену неоплаченного заказа с сохранённым промокодом.
+func (s *orderService) repriceOrder(ctx context.Context, order *models.Order) error {
+	if err := s.priceOrder(ctx, order); err != nil {
+		return err
+	}
+	// Код уже использован этим заказом — скидку пересчитываем без повторной проверки лимитов.
+	if order.PromoCode != "" {
+		if promo, err := s.promos.GetByCode(ctx, order.PromoCode); err == nil {
+			if discount, err := promo.Discount(order.Pricing); err == nil {
+				order.Pricing.Discounts = append(order.Pricing.Discounts, discount)
+				s.pricingRules().Finalize(order.Pricing)
+				order.TotalPrice = order.Pricing.Total
+			}
+		}
+	}
+	return nil
+}
+
+func quoteKey(id string) string {
+	return fmt.Sprintf("quote:%s", id)
+}
+
+// QuoteOrder считает цену до бронирования и сохраняет её в Redis на QuoteTTLMinutes.
+func (s *orderService) QuoteOrder(ctx context.Context, clientID string, req *models.QuoteRequest) (*models.Quote, error) {
+	if !req.Date.After(time.Now()) {
+		return nil, fmt.Errorf("%w: date must be in the future", models.ErrValidation)
+	}
+	loc := req.Location
+	if req.AddressID != "" {
+		probe := &models.Order{ClientID: clientID, AddressID: req.AddressID}
+		if err := s.resolveAddress(ctx, probe); err != nil {
+			return nil, err
+		}
+		loc = probe.Location
+	}
+	// Без адреса цена считается без зоны; такой котировкой можно оформить заказ только вне зон.
+	var zone *models.Zone
+	if loc != nil {
+		z, err := s.zoneFor(ctx, loc)
+		if err != nil {
+			return nil, err
+		}
+		if z != nil {
+			if err := z.CheckNotice(req.Date, time.Now()); err != nil {
+				return nil, err
+			}
+		}
+		zone = z
+	}
+	_, breakdown, err := s.priceServices(ctx, req.ServiceIDs, req.Property, req.Date, zone)
+	if err != nil {
+		return nil, err
+	}
+	if req.PromoCode != "" {
+		promo, err := s.applyPromo(ctx, clientID, req.PromoCode, breakdown)
+		if err != nil {
+			return nil, err
+		}
+		req.PromoCode = promo.Code
+	}
+
+	ttl := time.Duration(s.cfg.QuoteTTLMinutes) * time.Minute
+	quote := &models.Quote{
+		ID:         primitive.NewObjectID().Hex(),
+		ClientID:   clientID,
+		ServiceIDs: req.ServiceIDs,
+		Property:   req.Property,
+		Date:       req.Date.UTC(),
+		PromoCode:  req.PromoCode,
+		Pricing:    *breakdown,
+		ExpiresAt:  time.Now().Add(ttl),
+	}
+	if zone != nil {
+		quote.ZoneID = zone.ID.Hex()
+	}
+	data, err := json.Marshal(quote)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.redis.Set(ctx, quoteKey(quote.ID), data, ttl).Err(); err != nil {
+		return nil, fmt.Errorf("failed to store quote: %w", err)
+	}
+	return quote, nil
+}
+
+// applyQuote забирает котировку из Redis (GETDEL — по одной котировке создаётся один заказ,
+// даже при параллельных запросах) и переносит в заказ услуги, дату и зафиксированную цену.
+// Если заказ по ней не создан, вызывающий возвращает её через restoreQuote.
+func (s *orderService) applyQuote(ctx context.Context, order *models.Order) (*models.Quote, error) {
+	data, err := s.redis.GetDel(ctx, quoteKey(order.QuoteID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, models.ErrQuoteExpired
+	}
+	if err != nil {
+		return nil, err
+	}
+	var quote models.Quote
+	if err := json.Unmarshal([]byte(data), &quote); err != nil {
+		return nil, err
+	}
+	if err := checkQuote(&quote, order); err != nil {
+		// Чужой или несовпадающий запрос не должен сжигать котировку.
+		s.restoreQuote(ctx, &quote)
+		return nil, err
+	}
+
+	order.ServiceIDs = quote.ServiceIDs
+	order.Property = quote.Property
+	order.Date = quote.Date
+	order.PromoCode = quote.PromoCode
+	order.Pricing = &quote.Pricing
+	order.TotalPrice = quote.Pricing.Total
+	// Описание услуг нужно для длительности заказа; цену оно не меняет.
+	s.enrichWithServiceDetails(ctx, order)
+	return &quote, nil
+}
+
+// checkQuote проверяет, что заказ оформляется тем же клиентом на те же услуги и дату.
+func checkQuote(quote *models.Quote, order *models.Order) error {
+	if quote.ClientID != order.ClientID {
+		return models.ErrForbidden
+	}
+	if len(order.ServiceIDs) > 0 && !sameServices(order.ServiceIDs, quote.ServiceIDs) {
+		return fmt.Errorf("%w: services do not match the quote", models.ErrValidation)
+	}
+	if !order.Date.IsZero() && !order.Date.Equal(quote.Date) {
+		return fmt.Errorf("%w: date does not match the quote", models.ErrValidation)
+	}
+	return nil
+}
+
+// restoreQuote кладёт котировку обратно на оставшийся срок, если заказ по ней не создан.
+// SetNX не перезапишет котировку, если её уже вернул параллельный запрос.
+func (s *orderService) restoreQuote(ctx context.Context, quote *models.Quote) {
+	ttl := time.Until(quote.ExpiresAt)
+	if ttl <= 0 {
+		return
+	}
+	data, err := json.Marshal(quote)
+	if err == nil {
+		err = s.redis.SetNX(ctx, quoteKey(quote.ID), data, ttl).Err()
+	}
+	if err != nil {
+		log.Printf("[QUOTE] Failed to restore quote %s: %v", quote.ID, err)
+	}
+}
+
+// RequoteOrder пересчитывает цену неоплаченного заказа по текущему каталогу,
+// при необходимости с новым набором услуг. Оплаченные заказы не пересчитываются:
+// сумма платежа должна совпадать с зафиксированной ценой.
+func (s *orderService) RequoteOrder(ctx context.Context, id primitive.ObjectID, userID, role string, serviceIDs []string) (*models.Order, error) {
+	var out *models.Order
+	err := retryOnConflict(func() error {
+		var err error
+		out, err = s.requoteOrder(ctx, id, userID, role, serviceIDs)
+		return err
+	})
+	return out, err
+}
+
+func (s *orderService) requoteOrder(ctx context.Context, id primitive.ObjectID, userID, role string, serviceIDs []string) (*models.Order, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if role != "manager" && role != "admin" && order.ClientID != userID {
+		return nil, models.ErrForbidden
+	}
+	if order.Status != models.StatusPending {
+		return nil, fmt.Errorf("%w: only unpaid orders can be re-quoted", models.ErrValidation)
+	}
+	servicesChanged := len(serviceIDs) > 0 && !sameServices(serviceIDs, order.ServiceIDs)
+	if servicesChanged {
+		order.ServiceIDs = serviceIDs
+	}
+	if err := s.repriceOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	s.applySchedule(order)
+	if servicesChanged {
+		order.Checklist = models.BuildChecklist(order.ServiceDetails)
+	}
+	if err := s.repo.Update(ctx, order); err != nil {
+		return nil, err
+	}
+	s.clearCache(ctx, order.ClientID)
+	return order, nil
+}
+
+// sameServices сравнивает наборы услуг без учёта порядка.
+func sameServices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]int, len(a))
+	for _, id := range a {
+		seen[id]++
+	}
+	for _, id := range b {
+		if seen[id] == 0 {
+			return false
+		}
+		seen[id]--
+	}
+	return true
+}
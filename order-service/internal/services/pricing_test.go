@@ -0,0 +1,34 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+)
+
+func TestCheckQuote(t *testing.T) {
+	date := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	quote := &models.Quote{ClientID: "client", ServiceIDs: []string{"a", "b"}, Date: date}
+
+	cases := []struct {
+		name  string
+		order models.Order
+		want  error
+	}{
+		{"same client, fields from quote", models.Order{ClientID: "client"}, nil},
+		{"services in another order", models.Order{ClientID: "client", ServiceIDs: []string{"b", "a"}, Date: date}, nil},
+		{"other client", models.Order{ClientID: "other"}, models.ErrForbidden},
+		{"other services", models.Order{ClientID: "client", ServiceIDs: []string{"a"}}, models.ErrValidation},
+		{"other date", models.Order{ClientID: "client", Date: date.Add(time.Hour)}, models.ErrValidation},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkQuote(quote, &tc.order)
+			if !errors.Is(err, tc.want) || (tc.want == nil && err != nil) {
+				t.Errorf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
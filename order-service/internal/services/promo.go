@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PromoRepository хранит промокоды (promo_codes) и их использования (promo_redemptions).
+type PromoRepository interface {
+	Create(ctx context.Context, code *models.PromoCode) error
+	Update(ctx context.Context, code *models.PromoCode) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.PromoCode, error)
+	GetByCode(ctx context.Context, code string) (*models.PromoCode, error)
+	List(ctx context.Context) ([]models.PromoCode, error)
+	Reserve(ctx context.Context, id primitive.ObjectID, userID string) error
+	Release(ctx context.Context, id primitive.ObjectID, userID string) error
+	ClaimFirstOrder(ctx context.Context, clientID string, codeID primitive.ObjectID) error
+	ReleaseFirstOrder(ctx context.Context, clientID string) error
+	AddRedemption(ctx context.Context, redemption *models.PromoRedemption) error
+	Stats(ctx context.Context, codeID *primitive.ObjectID) ([]models.PromoStats, error)
+}
+
+func (s *orderService) CreatePromo(ctx context.Context, code *models.PromoCode) error {
+	if err := code.Validate(); err != nil {
+		return err
+	}
+	if _, err := s.promos.GetByCode(ctx, code.Code); err == nil {
+		return models.ErrPromoDuplicate
+	} else if !errors.Is(err, models.ErrPromoNotFound) {
+		return err
+	}
+	return s.promos.Create(ctx, code)
+}
+
+func (s *orderService) UpdatePromo(ctx context.Context, id primitive.ObjectID, code *models.PromoCode) error {
+	code.ID = id
+	if err := code.Validate(); err != nil {
+		return err
+	}
+	if existing, err := s.promos.GetByCode(ctx, code.Code); err == nil && existing.ID != id {
+		return models.ErrPromoDuplicate
+	} else if err != nil && !errors.Is(err, models.ErrPromoNotFound) {
+		return err
+	}
+	return s.promos.Update(ctx, code)
+}
+
+func (s *orderService) DeletePromo(ctx context.Context, id primitive.ObjectID) error {
+	return s.promos.Delete(ctx, id)
+}
+
+func (s *orderService) GetPromo(ctx context.Context, id primitive.ObjectID) (*models.PromoCode, error) {
+	return s.promos.GetByID(ctx, id)
+}
+
+func (s *orderService) ListPromos(ctx context.Context) ([]models.PromoCode, error) {
+	return s.promos.List(ctx)
+}
+
+// PromoStats возвращает статистику использований; id == nil — по всем кодам.
+func (s *orderService) PromoStats(ctx context.Context, id *primitive.ObjectID) ([]models.PromoStats, error) {
+	return s.promos.Stats(ctx, id)
+}
+
+// isFirstOrder — у клиента ещё нет заказов, кроме отменённых.
+func (s *orderService) isFirstOrder(ctx context.Context, clientID string) (bool, error) {
+	count, err := s.repo.CountOrders(ctx, bson.M{
+		"client_id": clientID,
+		"status":    bson.M{"$ne": models.StatusCancelled},
+	})
+	return count == 0, err
+}
+
+// checkPromo находит код и проверяет, может ли клиент его применить прямо сейчас.
+func (s *orderService) checkPromo(ctx context.Context, clientID, code string) (*models.PromoCode, error) {
+	promo, err := s.promos.GetByCode(ctx, models.NormalizePromoCode(code))
+	if errors.Is(err, models.ErrPromoNotFound) {
+		return nil, fmt.Errorf("%w: unknown promo code", models.ErrValidation)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := promo.CheckAvailable(clientID, time.Now()); err != nil {
+		return nil, err
+	}
+	if promo.FirstOrderOnly {
+		first, err := s.isFirstOrder(ctx, clientID)
+		if err != nil {
+			return nil, err
+		}
+		if !first {
+			return nil, fmt.Errorf("%w: promo code is valid for the first order only", models.ErrValidation)
+		}
+	}
+	return promo, nil
+}
+
+// applyPromo добавляет скидку по коду в расчёт и пересчитывает итог.
+func (s *orderService) applyPromo(ctx context.Context, clientID, code string, b *models.PriceBreakdown) (*models.PromoCode, error) {
+	promo, err := s.checkPromo(ctx, clientID, code)
+	if err != nil {
+		return nil, err
+	}
+	discount, err := promo.Discount(b)
+	if err != nil {
+		return nil, err
+	}
+	b.Discounts = append(b.Discounts, discount)
+	s.pricingRules().Finalize(b)
+	return promo, nil
+}
+
+// reservePromo атомарно занимает использование кода до создания заказа. Для кода
+// «на первый заказ» за клиентом ещё закрепляется документ в promo_first_orders:
+// подсчёт заказов в checkPromo не защищает от параллельных заказов одного клиента.
+func (s *orderService) reservePromo(ctx context.Context, promo *models.PromoCode, clientID string) error {
+	if err := s.promos.Reserve(ctx, promo.ID, clientID); err != nil {
+		return err
+	}
+	if !promo.FirstOrderOnly {
+		return nil
+	}
+	if err := s.promos.ClaimFirstOrder(ctx, clientID, promo.ID); err != nil {
+		if relErr := s.promos.Release(ctx, promo.ID, clientID); relErr != nil {
+			log.Printf("[PROMO] Failed to release %s for client %s: %v", promo.Code, clientID, relErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// releasePromo возвращает резерв кода, если заказ так и не был создан.
+func (s *orderService) releasePromo(ctx context.Context, promo *models.PromoCode, clientID string) {
+	if err := s.promos.Release(ctx, promo.ID, clientID); err != nil {
+		log.Printf("[PROMO] Failed to release %s for client %s: %v", promo.Code, clientID, err)
+	}
+	if promo.FirstOrderOnly {
+		if err := s.promos.ReleaseFirstOrder(ctx, clientID); err != nil {
+			log.Printf("[PROMO] Failed to release first-order claim of client %s: %v", clientID, err)
+		}
+	}
+}
+
+// recordRedemption сохраняет факт использования кода после создания заказа.
+// Счётчики уже увеличены в Reserve, поэтому сбой здесь влияет только на статистику.
+func (s *orderService) recordRedemption(ctx context.Context, promo *models.PromoCode, order *models.Order) {
+	discount := 0.0
+	if order.Pricing != nil {
+		for _, d := range order.Pricing.Discounts {
+			if d.Code == promo.Code {
+				discount += d.Amount
+			}
+		}
+	}
+	err := s.promos.AddRedemption(ctx, &models.PromoRedemption{
+		CodeID:   promo.ID,
+		Code:     promo.Code,
+		UserID:   order.ClientID,
+		OrderID:  order.ID,
+		Discount: discount,
+	})
+	if err != nil {
+		log.Printf("[PROMO] Failed to record redemption of %s for order %s: %v", promo.Code, order.ID.Hex(), err)
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// stubPromoRepo считает резервы и закрепления кодов «на первый заказ» в памяти.
+type stubPromoRepo struct {
+	PromoRepository
+	mu       sync.Mutex
+	reserved int
+	claims   map[string]primitive.ObjectID
+}
+
+func (r *stubPromoRepo) Reserve(context.Context, primitive.ObjectID, string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reserved++
+	return nil
+}
+
+func (r *stubPromoRepo) Release(context.Context, primitive.ObjectID, string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reserved--
+	return nil
+}
+
+func (r *stubPromoRepo) ClaimFirstOrder(_ context.Context, clientID string, codeID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.claims[clientID]; ok {
+		return models.ErrFirstOrderPromoUsed
+	}
+	r.claims[clientID] = codeID
+	return nil
+}
+
+func (r *stubPromoRepo) ReleaseFirstOrder(_ context.Context, clientID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.claims, clientID)
+	return nil
+}
+
+// Параллельные заказы одного клиента с кодом «на первый заказ»: скидку получает только один.
+func TestReserveFirstOrderPromoConcurrently(t *testing.T) {
+	promos := &stubPromoRepo{claims: map[string]primitive.ObjectID{}}
+	svc := newTestService(newStubOrderRepo())
+	svc.promos = promos
+	promo := &models.PromoCode{ID: primitive.NewObjectID(), Code: "WELCOME", FirstOrderOnly: true}
+
+	const n = 8
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = svc.reservePromo(context.Background(), promo, "client")
+		}(i)
+	}
+	wg.Wait()
+
+	ok := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			ok++
+		case !errors.Is(err, models.ErrFirstOrderPromoUsed):
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if ok != 1 || promos.reserved != 1 {
+		t.Fatalf("succeeded = %d, reserved = %d, want 1 and 1", ok, promos.reserved)
+	}
+
+	// Заказ не создан — закрепление снимается, и код снова доступен.
+	svc.releasePromo(context.Background(), promo, "client")
+	if err := svc.reservePromo(context.Background(), promo, "client"); err != nil {
+		t.Fatalf("reserve after release: %v", err)
+	}
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxProposedSlots ограничивает число вариантов, которые клиент может предложить за раз.
+const maxProposedSlots = 5
+
+// RescheduleRepository хранит запросы на перенос заказов (reschedule_requests).
+type RescheduleRepository interface {
+	Create(ctx context.Context, req *models.RescheduleRequest) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.RescheduleRequest, error)
+	AddResponse(ctx context.Context, id primitive.ObjectID, resp models.RescheduleResponse) (bool, error)
+	Resolve(ctx context.Context, req *models.RescheduleRequest) (bool, error)
+	GetLatestByOrderID(ctx context.Context, orderID primitive.ObjectID) (*models.RescheduleRequest, error)
+}
+
+// reschedulable — статусы, в которых заказ ещё можно перенести.
+func reschedulable(status models.OrderStatus) bool {
+	switch status {
+	case models.StatusPending, models.StatusPaid, models.StatusPrePaid, models.StatusAssigned:
+		return true
+	}
+	return false
+}
+
+// GetReschedule возвращает последний запрос на перенос заказа.
+func (s *orderService) GetReschedule(ctx context.Context, id primitive.ObjectID, userID, role string) (*models.RescheduleRequest, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOrderAccess(order, userID, role); err != nil {
+		return nil, err
+	}
+	req, err := s.reschedules.GetLatestByOrderID(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, models.ErrNoRescheduleOpen
+	}
+	return req, err
+}
+
+// RequestReschedule создаёт запрос клиента на перенос. Если клинеры ещё не назначены,
+// дата меняется сразу на первый предложенный слот и клиент получает подтверждение;
+// иначе назначенные клинеры должны принять или отклонить перенос.
+func (s *orderService) RequestReschedule(ctx context.Context, id primitive.ObjectID, clientID string, slots []time.Time, reason string) (*models.RescheduleRequest, error) {
+	if len(slots) == 0 || len(slots) > maxProposedSlots {
+		return nil, fmt.Errorf("%w: propose from 1 to %d slots", models.ErrValidation, maxProposedSlots)
+	}
+	now := time.Now()
+	for i, slot := range slots {
+		if !slot.After(now) {
+			return nil, fmt.Errorf("%w: slot %s is in the past", models.ErrValidation, slot.Format(time.RFC3339))
+		}
+		slots[i] = slot.UTC()
+	}
+
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order.ClientID != clientID {
+		return nil, models.ErrForbidden
+	}
+	if !reschedulable(order.Status) {
+		return nil, fmt.Errorf("%w: order in status %s cannot be rescheduled", models.ErrValidation, order.Status)
+	}
+	if last, err := s.reschedules.GetLatestByOrderID(ctx, id); err == nil && last.Status == models.ReschedulePending {
+		if awaitingResponse(order, last) {
+			return nil, models.ErrRescheduleOpen
+		}
+		// Ответа по прошлому запросу ждать не от кого — разрешаем его и переносим уже от новой даты.
+		if err := s.resolveReschedule(ctx, order, last); err != nil {
+			return nil, err
+		}
+		if order, err = s.repo.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		if !reschedulable(order.Status) {
+			return nil, fmt.Errorf("%w: order in status %s cannot be rescheduled", models.ErrValidation, order.Status)
+		}
+	} else if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	req := &models.RescheduleRequest{
+		OrderID:       id,
+		RequestedBy:   clientID,
+		Reason:        reason,
+		OldDate:       order.Date,
+		ProposedSlots: slots,
+		CleanerIDs:    append([]string{}, order.CleanerID...),
+		Responses:     []models.RescheduleResponse{},
+		Status:        models.ReschedulePending,
+	}
+
+	// Никого не назначено — согласовывать не с кем.
+	if len(order.CleanerID) == 0 {
+		if err := s.moveOrder(ctx, order, slots[0]); err != nil {
+			return nil, err
+		}
+		req.Status = models.RescheduleConfirmed
+		req.ChosenSlot = &slots[0]
+		req.ResolvedAt = &now
+		err := s.inTx(ctx, func(ctx context.Context) error {
+			if err := s.reschedules.Create(ctx, req); err != nil {
+				return err
+			}
+			return s.emit(ctx, clientNotice(order, "reschedule_confirmed", map[string]string{"date": slots[0].Format(time.RFC3339)}))
+		})
+		if err != nil {
+			return nil, err
+		}
+		return req, nil
+	}
+
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		if err := s.reschedules.Create(ctx, req); err != nil {
+			return err
+		}
+		extra := map[string]string{"reschedule_id": req.ID.Hex()}
+		events := append(cleanerNotices(order, req.CleanerIDs, "reschedule_requested", extra), clientNotice(order, "reschedule_requested", extra))
+		return s.emit(ctx, events...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// RespondReschedule фиксирует ответ назначенного клинера. Согласие на слот проверяется
+// по графику и другим заказам клинера. Когда ответили все — запрос разрешается.
+func (s *orderService) RespondReschedule(ctx context.Context, id primitive.ObjectID, cleanerID string, accept bool, slot *time.Time, reason string) (*models.RescheduleRequest, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	req, err := s.reschedules.GetLatestByOrderID(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, models.ErrNoRescheduleOpen
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != models.ReschedulePending {
+		return nil, models.ErrNoRescheduleOpen
+	}
+	if !containsString(req.CleanerIDs, cleanerID) || !containsString(order.CleanerID, cleanerID) {
+		return nil, models.ErrForbidden
+	}
+	if req.Responded(cleanerID) {
+		return nil, fmt.Errorf("%w: cleaner has already responded", models.ErrValidation)
+	}
+
+	resp := models.RescheduleResponse{
+		CleanerID:   cleanerID,
+		Accepted:    accept,
+		Reason:      reason,
+		RespondedAt: time.Now(),
+	}
+	if accept {
+		if slot == nil || !req.HasSlot(*slot) {
+			return nil, fmt.Errorf("%w: slot must be one of the proposed slots", models.ErrValidation)
+		}
+		chosen := slot.UTC()
+		moved := *order
+		shiftOrder(&moved, chosen)
+		if err := s.checkWorkingHours(ctx, &moved, cleanerID); err != nil {
+			return nil, err
+		}
+		if err := s.checkCleanerAvailability(ctx, &moved, cleanerID); err != nil {
+			return nil, err
+		}
+		resp.Slot = &chosen
+	}
+
+	extra := map[string]string{
+		"cleaner_id": cleanerID,
+		"accepted":   fmt.Sprintf("%t", accept),
+	}
+	if resp.Slot != nil {
+		extra["slot"] = resp.Slot.Format(time.RFC3339)
+	}
+	// Ответ дописывается условным $push: параллельные ответы клинеров не затирают
+	// друг друга, а ответ на уже разрешённый запрос не записывается.
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		added, err := s.reschedules.AddResponse(ctx, req.ID, resp)
+		if err != nil {
+			return err
+		}
+		if !added {
+			return errResponseRejected
+		}
+		return s.emit(ctx, clientNotice(order, "reschedule_response", extra))
+	})
+	if errors.Is(err, errResponseRejected) {
+		if req, err = s.reschedules.GetByID(ctx, req.ID); err != nil {
+			return nil, err
+		}
+		if req.Status != models.ReschedulePending {
+			return nil, models.ErrNoRescheduleOpen
+		}
+		return nil, fmt.Errorf("%w: cleaner has already responded", models.ErrValidation)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Решение принимаем по свежей копии: в ней есть ответы, записанные параллельно.
+	if req, err = s.reschedules.GetByID(ctx, req.ID); err != nil {
+		return nil, err
+	}
+	// Ждём ответа от всех, кто всё ещё назначен на заказ (менеджер мог кого-то снять).
+	if req.Status != models.ReschedulePending || awaitingResponse(order, req) {
+		return req, nil
+	}
+	if err := s.resolveReschedule(ctx, order, req); err != nil {
+		return nil, err
+	}
+	return s.reschedules.GetByID(ctx, req.ID)
+}
+
+// awaitingResponse — среди клинеров запроса есть ещё назначенный на заказ и не ответивший.
+func awaitingResponse(order *models.Order, req *models.RescheduleRequest) bool {
+	for _, cid := range req.CleanerIDs {
+		if containsString(order.CleanerID, cid) && !req.Responded(cid) {
+			return true
+		}
+	}
+	return false
+}
+
+// settleReschedule разрешает открытый запрос на перенос, если ответа ждать больше не от кого:
+// менеджер снял с заказа последнего клинера, который ещё не ответил. Иначе запрос так и
+// остался бы открытым и блокировал новые переносы.
+func (s *orderService) settleReschedule(ctx context.Context, orderID primitive.ObjectID) error {
+	req, err := s.reschedules.GetLatestByOrderID(ctx, orderID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil || req.Status != models.ReschedulePending {
+		return err
+	}
+	order, err := s.repo.GetByID(ctx, orderID)
+	if err != nil || awaitingResponse(order, req) {
+		return err
+	}
+	return s.resolveReschedule(ctx, order, req)
+}
+
+// errResponseRejected — условная запись ответа не прошла: запрос уже разрешён
+// или клинер ответил параллельным запросом.
+var errResponseRejected = errors.New("reschedule response rejected")
+
+// resolveReschedule выбирает слот, который приняло больше всего клинеров (при равенстве —
+// более ранний из предложенных), переносит заказ и снимает не согласившихся клинеров.
+// Если не согласился никто, заказ переносится на первый слот и возвращается на назначение.
+func (s *orderService) resolveReschedule(ctx context.Context, order *models.Order, req *models.RescheduleRequest) error {
+	votes := map[time.Time]int{}
+	for _, resp := range req.Responses {
+		if resp.Accepted && resp.Slot != nil {
+			votes[*resp.Slot]++
+		}
+	}
+	slots := append([]time.Time{}, req.ProposedSlots...)
+	sort.SliceStable(slots, func(i, j int) bool {
+		if votes[slots[i]] != votes[slots[j]] {
+			return votes[slots[i]] > votes[slots[j]]
+		}
+		return slots[i].Before(slots[j])
+	})
+	chosen := slots[0]
+
+	var keep []string
+	for _, resp := range req.Responses {
+		if resp.Accepted && resp.Slot != nil && resp.Slot.Equal(chosen) {
+			keep = append(keep, resp.CleanerID)
+		}
+	}
+
+	now := time.Now()
+	req.ChosenSlot = &chosen
+	req.ResolvedAt = &now
+	if len(keep) == 0 {
+		req.Status = models.RescheduleFailed
+	} else {
+		req.Status = models.RescheduleConfirmed
+	}
+
+	return s.inTx(ctx, func(ctx context.Context) error {
+		// Последние ответы могут прийти одновременно: запрос разрешает тот, кто первым
+		// перевёл его из pending, остальные ничего не делают.
+		claimed, err := s.reschedules.Resolve(ctx, req)
+		if err != nil || !claimed {
+			return err
+		}
+
+		var events []*models.OutboxEvent
+		for _, cleanerID := range req.CleanerIDs {
+			if containsString(keep, cleanerID) || !containsString(order.CleanerID, cleanerID) {
+				continue
+			}
+			if err := retryOnConflict(func() error { return s.unassignCleaner(ctx, order.ID, cleanerID) }); err != nil {
+				return fmt.Errorf("failed to unassign cleaner %s: %w", cleanerID, err)
+			}
+			events = append(events, cleanerNotices(order, []string{cleanerID}, "reschedule_unassigned", nil)...)
+		}
+
+		fresh, err := s.repo.GetByID(ctx, order.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.moveOrder(ctx, fresh, chosen); err != nil {
+			return err
+		}
+
+		extra := map[string]string{"date": chosen.Format(time.RFC3339)}
+		if req.Status == models.RescheduleFailed {
+			log.Printf("[RESCHEDULE] Order %s moved to %s without cleaners, waiting for re-assignment", order.ID.Hex(), chosen.Format(time.RFC3339))
+			events = append(events, clientNotice(order, "reschedule_failed", extra))
+		} else {
+			events = append(events, clientNotice(order, "reschedule_confirmed", extra))
+			events = append(events, cleanerNotices(order, keep, "reschedule_confirmed", extra)...)
+		}
+		return s.emit(ctx, events...)
+	})
+}
+
+// shiftOrder сдвигает интервал заказа на новую дату. У старых заказов без длительности
+// end_date не выставляем — orderInterval подставит длительность по умолчанию.
+func shiftOrder(order *models.Order, date time.Time) {
+	order.Date = date
+	order.EndDate = time.Time{}
+	order.RemindersSent = nil // напоминания по новой дате отправятся заново
+	if order.DurationMinutes > 0 {
+		order.EndDate = date.Add(time.Duration(order.DurationMinutes) * time.Minute)
+	}
+}
+
+// moveOrder переносит заказ на новую дату и сохраняет его; при конфликте версий
+// переносит свежую копию, если заказ всё ещё можно переносить.
+func (s *orderService) moveOrder(ctx context.Context, order *models.Order, date time.Time) error {
+	shiftOrder(order, date)
+	order, err := s.saveWithReload(ctx, order, func(o *models.Order) error {
+		if !reschedulable(o.Status) {
+			return fmt.Errorf("%w: order is %s and can no longer be rescheduled", models.ErrVersionConflict, o.Status)
+		}
+		shiftOrder(o, date)
+		return nil
+	}, s.repo.Update)
+	if err != nil {
+		return err
+	}
+	s.clearCache(ctx, order.ClientID)
+	return nil
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// stubRescheduleRepo повторяет условные обновления rescheduleRepository.
+type stubRescheduleRepo struct {
+	RescheduleRepository
+	mu       sync.Mutex
+	req      *models.RescheduleRequest
+	resolves int
+}
+
+func (r *stubRescheduleRepo) snapshot() *models.RescheduleRequest {
+	cp := *r.req
+	cp.Responses = append([]models.RescheduleResponse(nil), r.req.Responses...)
+	return &cp
+}
+
+func (r *stubRescheduleRepo) GetLatestByOrderID(context.Context, primitive.ObjectID) (*models.RescheduleRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.snapshot(), nil
+}
+
+func (r *stubRescheduleRepo) GetByID(context.Context, primitive.ObjectID) (*models.RescheduleRequest, error) {
+	return r.GetLatestByOrderID(context.Background(), primitive.NilObjectID)
+}
+
+func (r *stubRescheduleRepo) AddResponse(_ context.Context, _ primitive.ObjectID, resp models.RescheduleResponse) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.req.Status != models.ReschedulePending || r.req.Responded(resp.CleanerID) {
+		return false, nil
+	}
+	r.req.Responses = append(r.req.Responses, resp)
+	return true, nil
+}
+
+func (r *stubRescheduleRepo) Resolve(_ context.Context, req *models.RescheduleRequest) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.req.Status != models.ReschedulePending {
+		return false, nil
+	}
+	r.req.Status, r.req.ChosenSlot, r.req.ResolvedAt = req.Status, req.ChosenSlot, req.ResolvedAt
+	r.resolves++
+	return true, nil
+}
+
+func TestRespondRescheduleConcurrently(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	slot := now.Add(72 * time.Hour)
+	order := &models.Order{
+		ID:        primitive.NewObjectID(),
+		ClientID:  "client",
+		CleanerID: []string{"a", "b", "c"},
+		Status:    models.StatusAssigned,
+		Date:      now.Add(48 * time.Hour),
+	}
+	reschedules := &stubRescheduleRepo{req: &models.RescheduleRequest{
+		ID:            primitive.NewObjectID(),
+		OrderID:       order.ID,
+		ProposedSlots: []time.Time{slot},
+		CleanerIDs:    []string{"a", "b", "c"},
+		Responses:     []models.RescheduleResponse{},
+		Status:        models.ReschedulePending,
+	}}
+	repo := newStubOrderRepo(order)
+	s := newTestService(repo)
+	s.reschedules = reschedules
+	ctx := context.Background()
+
+	// Все три клинера принимают слот одновременно: ни один ответ не теряется,
+	// а запрос разрешается ровно один раз.
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for _, id := range []string{"a", "b", "c"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			_, err := s.RespondReschedule(ctx, order.ID, id, true, &slot, "")
+			errs <- err
+		}(id)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("RespondReschedule: %v", err)
+		}
+	}
+
+	req := reschedules.snapshot()
+	if len(req.Responses) != 3 || req.Status != models.RescheduleConfirmed || reschedules.resolves != 1 {
+		t.Fatalf("got %d responses, status %s, %d resolves; want 3, confirmed, 1", len(req.Responses), req.Status, reschedules.resolves)
+	}
+	if moved, _ := repo.GetByID(ctx, order.ID); !moved.Date.Equal(slot) {
+		t.Errorf("order date = %v, want %v", moved.Date, slot)
+	}
+
+	// Ответ на уже разрешённый запрос не записывается.
+	if _, err := s.RespondReschedule(ctx, order.ID, "c", false, nil, "late"); !errors.Is(err, models.ErrNoRescheduleOpen) {
+		t.Errorf("late response: %v, want ErrNoRescheduleOpen", err)
+	}
+	if _, err := reschedules.AddResponse(ctx, order.ID, models.RescheduleResponse{CleanerID: "d"}); err != nil || len(reschedules.snapshot().Responses) != 3 {
+		t.Errorf("response to a resolved request was recorded")
+	}
+}
+
+func TestRespondRescheduleTwice(t *testing.T) {
+	order := &models.Order{
+		ID:        primitive.NewObjectID(),
+		ClientID:  "client",
+		CleanerID: []string{"a", "b"},
+		Status:    models.StatusAssigned,
+		Date:      time.Now().Add(48 * time.Hour),
+	}
+	reschedules := &stubRescheduleRepo{req: &models.RescheduleRequest{
+		ID:            primitive.NewObjectID(),
+		OrderID:       order.ID,
+		ProposedSlots: []time.Time{time.Now().Add(72 * time.Hour)},
+		CleanerIDs:    []string{"a", "b"},
+		Responses:     []models.RescheduleResponse{},
+		Status:        models.ReschedulePending,
+	}}
+	s := newTestService(newStubOrderRepo(order))
+	s.reschedules = reschedules
+	ctx := context.Background()
+
+	if _, err := s.RespondReschedule(ctx, order.ID, "a", false, nil, "busy"); err != nil {
+		t.Fatalf("first response: %v", err)
+	}
+	if _, err := s.RespondReschedule(ctx, order.ID, "a", false, nil, "busy"); !errors.Is(err, models.ErrValidation) {
+		t.Errorf("second response: %v, want ErrValidation", err)
+	}
+	if req := reschedules.snapshot(); len(req.Responses) != 1 || req.Status != models.ReschedulePending {
+		t.Errorf("got %d responses, status %s; want 1, pending", len(req.Responses), req.Status)
+	}
+}
+
+func TestUnassignLastAwaitedCleanerSettlesReschedule(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	slot := now.Add(72 * time.Hour)
+	order := &models.Order{
+		ID:        primitive.NewObjectID(),
+		ClientID:  "client",
+		CleanerID: []string{"a", "b"},
+		Status:    models.StatusAssigned,
+		Date:      now.Add(48 * time.Hour),
+	}
+	reschedules := &stubRescheduleRepo{req: &models.RescheduleRequest{
+		ID:            primitive.NewObjectID(),
+		OrderID:       order.ID,
+		ProposedSlots: []time.Time{slot},
+		CleanerIDs:    []string{"a", "b"},
+		Responses:     []models.RescheduleResponse{{CleanerID: "a", Accepted: true, Slot: &slot}},
+		Status:        models.ReschedulePending,
+	}}
+	repo := newStubOrderRepo(order)
+	s := newTestService(repo)
+	s.reschedules = reschedules
+	outbox := s.outbox.(*stubOutbox)
+	ctx := context.Background()
+
+	// b так и не ответил и снят менеджером — ждать больше некого, перенос подтверждается.
+	if err := s.UnassignCleaner(ctx, order.ID, "b"); err != nil {
+		t.Fatalf("UnassignCleaner: %v", err)
+	}
+	if req := reschedules.snapshot(); req.Status != models.RescheduleConfirmed {
+		t.Fatalf("reschedule status = %s, want confirmed", req.Status)
+	}
+	if moved, _ := repo.GetByID(ctx, order.ID); !moved.Date.Equal(slot) || len(moved.CleanerID) != 1 {
+		t.Errorf("order: date %v, cleaners %v; want %v, [a]", moved.Date, moved.CleanerID, slot)
+	}
+	confirmed := false
+	for _, e := range outbox.events {
+		if e.Notification != nil && e.Notification.UserID == "client" && e.Notification.Type == "reschedule_confirmed" {
+			confirmed = true
+		}
+	}
+	if !confirmed {
+		t.Error("client was not notified about the confirmed reschedule")
+	}
+}
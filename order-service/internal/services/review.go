@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReviewRepository хранит отзывы клиентов о клинерах (коллекция reviews).
+type ReviewRepository interface {
+	CreateMany(ctx context.Context, reviews []*models.Review) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.Review, error)
+	ListByOrder(ctx context.Context, orderID primitive.ObjectID) ([]models.Review, error)
+	ListByCleaner(ctx context.Context, cleanerID string, includeHidden bool, before primitive.ObjectID, limit int64) ([]models.Review, error)
+	SetReply(ctx context.Context, id primitive.ObjectID, reply *models.ReviewReply) error
+	SetModeration(ctx context.Context, id primitive.ObjectID, m *models.ReviewModeration) error
+	CleanerRating(ctx context.Context, cleanerID string) (*models.CleanerRating, error)
+}
+
+func isStaff(role string) bool {
+	return role == "manager" || role == "admin"
+}
+
+// AddReviews сохраняет отзывы клиента о клинерах завершённого заказа и пересчитывает их рейтинг.
+func (s *orderService) AddReviews(ctx context.Context, orderID primitive.ObjectID, clientID string, inputs []models.ReviewInput) ([]*models.Review, error) {
+	order, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := models.NewReviews(order, clientID, inputs, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		if err := s.reviews.CreateMany(ctx, reviews); err != nil {
+			return err
+		}
+		cleanerIDs := make([]string, 0, len(reviews))
+		events := make([]*models.OutboxEvent, 0, len(reviews))
+		for _, rv := range reviews {
+			cleanerIDs = append(cleanerIDs, rv.CleanerID)
+			events = append(events, models.NewNotificationEvent(order.ID.Hex(), rv.CleanerID, "cleaner", "review_received", map[string]string{
+				"order_id":  order.ID.Hex(),
+				"review_id": rv.ID.Hex(),
+				"overall":   fmt.Sprintf("%.2f", rv.Overall),
+			}))
+		}
+		if err := s.emit(ctx, events...); err != nil {
+			return err
+		}
+		return s.refreshRatings(ctx, order.ID.Hex(), cleanerIDs...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// refreshRatings пересчитывает рейтинг клинеров по сохранённым отзывам и ставит
+// передачу результата в auth-service в outbox.
+func (s *orderService) refreshRatings(ctx context.Context, orderID string, cleanerIDs ...string) error {
+	asOf := time.Now().UTC()
+	events := make([]*models.OutboxEvent, 0, len(cleanerIDs))
+	for _, cleanerID := range cleanerIDs {
+		rating, err := s.reviews.CleanerRating(ctx, cleanerID)
+		if err != nil {
+			return err
+		}
+		events = append(events, models.NewRatingEvent(orderID, rating, asOf))
+	}
+	return s.emit(ctx, events...)
+}
+
+// GetOrderReviews — отзывы по заказу. Скрытые модератором видят только персонал и автор отзыва.
+func (s *orderService) GetOrderReviews(ctx context.Context, orderID primitive.ObjectID, userID, role string) ([]models.Review, error) {
+	order, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOrderAccess(order, userID, role); err != nil {
+		return nil, err
+	}
+	reviews, err := s.reviews.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	visible := reviews[:0]
+	for _, rv := range reviews {
+		if !rv.Hidden || isStaff(role) || rv.ClientID == userID {
+			visible = append(visible, rv)
+		}
+	}
+	return visible, nil
+}
+
+// GetCleanerReviews — рейтинг клинера и страница его отзывов, новые первыми.
+// Скрытые отзывы видит только персонал.
+func (s *orderService) GetCleanerReviews(ctx context.Context, cleanerID, role, cursor string, limit int) (*models.ReviewPage, error) {
+	var before primitive.ObjectID
+	if cursor != "" {
+		id, err := primitive.ObjectIDFromHex(cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid cursor", models.ErrValidation)
+		}
+		before = id
+	}
+	if limit <= 0 {
+		limit = models.DefaultPageSize
+	}
+	if limit > models.MaxPageSize {
+		limit = models.MaxPageSize
+	}
+
+	rating, err := s.reviews.CleanerRating(ctx, cleanerID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.reviews.ListByCleaner(ctx, cleanerID, isStaff(role), before, int64(limit+1))
+	if err != nil {
+		return nil, err
+	}
+	page := &models.ReviewPage{Rating: *rating, Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.HasMore = true
+		page.NextCursor = page.Items[limit-1].ID.Hex()
+	}
+	return page, nil
+}
+
+// ReplyToReview сохраняет (или заменяет) ответ клинера на отзыв о нём.
+func (s *orderService) ReplyToReview(ctx context.Context, reviewID primitive.ObjectID, cleanerID, text string) (*models.Review, error) {
+	text = strings.TrimSpace(text)
+	if text == "" || len(text) > models.MaxReviewTextLength {
+		return nil, fmt.Errorf("%w: reply must be between 1 and %d characters", models.ErrValidation, models.MaxReviewTextLength)
+	}
+	review, err := s.reviews.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.CleanerID != cleanerID {
+		return nil, models.ErrForbidden
+	}
+
+	now := time.Now().UTC()
+	reply := &models.ReviewReply{Text: text, CreatedAt: now, UpdatedAt: now}
+	if review.Reply != nil {
+		reply.CreatedAt = review.Reply.CreatedAt
+	}
+	if err := s.reviews.SetReply(ctx, reviewID, reply); err != nil {
+		return nil, err
+	}
+	review.Reply = reply
+	review.UpdatedAt = now
+	return review, nil
+}
+
+// ModerateReview скрывает/показывает или помечает отзыв. Изменение видимости
+// пересчитывает рейтинг клинера.
+func (s *orderService) ModerateReview(ctx context.Context, reviewID primitive.ObjectID, moderatorID string, in models.ReviewModerationInput) (*models.Review, error) {
+	if in.Hidden == nil && in.Flagged == nil {
+		return nil, fmt.Errorf("%w: nothing to change", models.ErrValidation)
+	}
+	review, err := s.reviews.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &models.ReviewModeration{
+		Hidden:      review.Hidden,
+		Flagged:     review.Flagged,
+		Note:        strings.TrimSpace(in.Note),
+		ModeratedBy: moderatorID,
+		ModeratedAt: time.Now().UTC(),
+	}
+	if in.Hidden != nil {
+		m.Hidden = *in.Hidden
+	}
+	if in.Flagged != nil {
+		m.Flagged = *in.Flagged
+	}
+	visibilityChanged := m.Hidden != review.Hidden
+
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		if err := s.reviews.SetModeration(ctx, reviewID, m); err != nil {
+			return err
+		}
+		if !visibilityChanged {
+			return nil
+		}
+		return s.refreshRatings(ctx, review.OrderID.Hex(), review.CleanerID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	review.Hidden, review.Flagged, review.Moderation = m.Hidden, m.Flagged, m
+	review.UpdatedAt = m.ModeratedAt
+	return review, nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+)
+
+const routeDateFormat = "2006-01-02"
+
+// routeLocation — пояс, в котором режутся сутки маршрута: tz из запроса, иначе пояс сервиса.
+func (s *orderService) routeLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return s.location(), nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", models.ErrValidation, tz)
+	}
+	return loc, nil
+}
+
+// dayBounds разбирает дату YYYY-MM-DD в сутки [00:00, 24:00) в поясе loc;
+// пустая дата — сегодня в этом поясе.
+func dayBounds(date string, loc *time.Location) (string, time.Time, time.Time, error) {
+	if date == "" {
+		date = time.Now().In(loc).Format(routeDateFormat)
+	}
+	day, err := time.ParseInLocation(routeDateFormat, date, loc)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, fmt.Errorf("%w: invalid date, expected YYYY-MM-DD", models.ErrValidation)
+	}
+	return date, day, day.AddDate(0, 0, 1), nil
+}
+
+func (s *orderService) routeStop(order *models.Order) models.RouteStop {
+	start, end := s.orderInterval(order)
+	stop := models.RouteStop{
+		OrderID: order.ID.Hex(),
+		Status:  order.Status,
+		Address: order.Address,
+		Start:   start,
+		End:     end,
+	}
+	if order.Location != nil && order.Location.Valid() {
+		loc := *order.Location
+		stop.Location = &loc
+	}
+	return stop
+}
+
+// GetCleanerRoute строит маршрут клинера на дату по его назначенным заказам.
+func (s *orderService) GetCleanerRoute(ctx context.Context, cleanerID, date, tz string) (*models.CleanerRoute, error) {
+	loc, err := s.routeLocation(tz)
+	if err != nil {
+		return nil, err
+	}
+	date, from, to, err := dayBounds(date, loc)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.repo.FindCleanersOrdersInRange(ctx, []string{cleanerID}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	stops := make([]models.RouteStop, 0, len(orders))
+	for i := range orders {
+		stops = append(stops, s.routeStop(&orders[i]))
+	}
+	route := models.PlanRoute(cleanerID, date, stops, float64(s.cfg.RouteSpeedKmh))
+	return &route, nil
+}
+
+// GetRoutes строит маршруты на дату для всех клинеров, у которых есть заказы.
+// Маршруты с непреодолимыми переездами идут первыми.
+func (s *orderService) GetRoutes(ctx context.Context, date, tz string) ([]models.CleanerRoute, error) {
+	loc, err := s.routeLocation(tz)
+	if err != nil {
+		return nil, err
+	}
+	date, from, to, err := dayBounds(date, loc)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.repo.FindAssignedInRange(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	byCleaner := make(map[string][]models.RouteStop)
+	for i := range orders {
+		stop := s.routeStop(&orders[i])
+		for _, cleanerID := range orders[i].CleanerID {
+			byCleaner[cleanerID] = append(byCleaner[cleanerID], stop)
+		}
+	}
+
+	routes := make([]models.CleanerRoute, 0, len(byCleaner))
+	for cleanerID, stops := range byCleaner {
+		routes = append(routes, models.PlanRoute(cleanerID, date, stops, float64(s.cfg.RouteSpeedKmh)))
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Feasible != routes[j].Feasible {
+			return !routes[i].Feasible
+		}
+		return routes[i].CleanerID < routes[j].CleanerID
+	})
+	return routes, nil
+}
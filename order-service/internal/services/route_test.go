@@ -0,0 +1,42 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+)
+
+func TestDayBounds(t *testing.T) {
+	almaty := time.FixedZone("Asia/Almaty", 5*3600)
+	date, from, to, err := dayBounds("2025-06-02", almaty)
+	if err != nil {
+		t.Fatalf("dayBounds: %v", err)
+	}
+	// Сутки по Алматы: с 19:00 UTC предыдущего дня.
+	wantFrom := time.Date(2025, 6, 1, 19, 0, 0, 0, time.UTC)
+	if date != "2025-06-02" || !from.Equal(wantFrom) || !to.Equal(wantFrom.Add(24*time.Hour)) {
+		t.Errorf("dayBounds = %s %v - %v, want %v - +24h", date, from.UTC(), to.UTC(), wantFrom)
+	}
+
+	// Без даты — сегодня в поясе запроса.
+	date, _, _, err = dayBounds("", almaty)
+	if err != nil || date != time.Now().In(almaty).Format(routeDateFormat) {
+		t.Errorf("dayBounds(\"\") = %s, %v", date, err)
+	}
+
+	if _, _, _, err := dayBounds("02.06.2025", almaty); !errors.Is(err, models.ErrValidation) {
+		t.Errorf("invalid date: err = %v, want ErrValidation", err)
+	}
+}
+
+func TestRouteLocation(t *testing.T) {
+	svc := newTestService(newStubOrderRepo())
+	if loc, err := svc.routeLocation(""); err != nil || loc != time.UTC {
+		t.Errorf("routeLocation(\"\") = %v, %v; want service default UTC", loc, err)
+	}
+	if _, err := svc.routeLocation("Mars/Olympus"); !errors.Is(err, models.ErrValidation) {
+		t.Errorf("unknown tz: err = %v, want ErrValidation", err)
+	}
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+
+	"cleaning-app/order-service/internal/models"
+	"cleaning-app/order-service/internal/utils"
+)
+
+// SearchOrders возвращает страницу заказов по фильтрам с keyset-пагинацией:
+// запрашиваем limit+1 документ, лишний означает, что есть следующая страница.
+func (s *orderService) SearchOrders(ctx context.Context, q *models.OrderSearch) (*models.OrderPage, error) {
+	if err := q.Normalize(); err != nil {
+		return nil, err
+	}
+	filter, err := q.Filter()
+	if err != nil {
+		return nil, err
+	}
+
+	// Кэшируются только запрошенные страницы; запись в любой заказ меняет поколение фильтров.
+	b, _ := json.Marshal(q)
+	hash := sha1.Sum(b)
+	cacheKey := s.cache.key(ctx, cacheFamilyFilter, cacheFamilyFilter, hex.EncodeToString(hash[:]))
+	var cached models.OrderPage
+	if s.cache.get(ctx, cacheFamilyFilter, cacheKey, &cached) {
+		return &cached, nil
+	}
+
+	orders, err := s.repo.Search(ctx, filter, q.SortSpec(), int64(q.Limit+1))
+	if err != nil {
+		return nil, err
+	}
+	page := &models.OrderPage{Items: orders}
+	if len(orders) > q.Limit {
+		page.Items = orders[:q.Limit]
+		page.HasMore = true
+		page.NextCursor = q.NextCursor(&page.Items[q.Limit-1])
+	}
+	if page.Items == nil {
+		page.Items = []models.Order{}
+	}
+	s.enrichOrders(ctx, page.Items)
+
+	s.cache.set(ctx, cacheKey, page)
+	return page, nil
+}
+
+// enrichOrders подтягивает описание услуг для старых заказов без service_details
+// одним запросом к cleaning-details на всю страницу.
+func (s *orderService) enrichOrders(ctx context.Context, orders []models.Order) {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, o := range orders {
+		if len(o.ServiceDetails) > 0 {
+			continue
+		}
+		for _, id := range o.ServiceIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	services, err := utils.FetchServiceDetails(ctx, s.cfg.CleaningDetailsURL, ids)
+	if err != nil {
+		log.Printf("[SEARCH] Failed to fetch details for %d services: %v", len(ids), err)
+		return
+	}
+	byID := make(map[string]models.Service, len(services))
+	for _, svc := range services {
+		byID[svc.ID] = svc
+	}
+	for i := range orders {
+		if len(orders[i].ServiceDetails) > 0 {
+			continue
+		}
+		for _, id := range orders[i].ServiceIDs {
+			if svc, ok := byID[id]; ok {
+				orders[i].ServiceDetails = append(orders[i].ServiceDetails, svc)
+			}
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"cleaning-app/order-service/internal/config"
+	"cleaning-app/order-service/internal/models"
+	"cleaning-app/order-service/internal/utils"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Заглушки хранилищ в памяти для тестов сервиса. Методы, которые тест не ожидает,
+// паникуют через встроенный nil-интерфейс.
+
+type stubOrderRepo struct {
+	OrderRepository
+	mu      sync.Mutex
+	orders  map[primitive.ObjectID]*models.Order
+	updates int
+	// inRange отдаёт FindCleanersOrdersInRange (заказы нужных клинеров, без учёта времени).
+	inRange []models.Order
+	// beforeUpdate вызывается один раз перед первой записью — имитирует параллельного писателя.
+	beforeUpdate func(stored *models.Order)
+}
+
+func newStubOrderRepo(orders ...*models.Order) *stubOrderRepo {
+	r := &stubOrderRepo{orders: map[primitive.ObjectID]*models.Order{}}
+	for _, o := range orders {
+		r.orders[o.ID] = o
+	}
+	return r
+}
+
+func (r *stubOrderRepo) GetByID(_ context.Context, id primitive.ObjectID) (*models.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o, ok := r.orders[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	cp := *o
+	cp.CleanerID = append([]string(nil), o.CleanerID...)
+	return &cp, nil
+}
+
+// Update проверяет версию, как настоящий репозиторий.
+func (r *stubOrderRepo) Update(_ context.Context, o *models.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if hook := r.beforeUpdate; hook != nil {
+		r.beforeUpdate = nil
+		hook(r.orders[o.ID])
+	}
+	if cur, ok := r.orders[o.ID]; ok && cur.Version != o.Version {
+		return models.ErrVersionConflict
+	}
+	o.Version++
+	cp := *o
+	cp.CleanerID = append([]string(nil), o.CleanerID...)
+	r.orders[o.ID] = &cp
+	r.updates++
+	return nil
+}
+
+func (r *stubOrderRepo) FindCleanerConflict(context.Context, string, time.Time, time.Time, primitive.ObjectID) (*models.Order, error) {
+	return nil, nil
+}
+
+func (r *stubOrderRepo) FindCleanersOrdersInRange(_ context.Context, cleanerIDs []string, _, _ time.Time) ([]models.Order, error) {
+	var out []models.Order
+	for _, o := range r.inRange {
+		for _, cid := range o.CleanerID {
+			if containsString(cleanerIDs, cid) {
+				out = append(out, o)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// stubScheduleRepo — графики по id клинера; без графика клинер доступен всегда.
+type stubScheduleRepo struct {
+	ScheduleRepository
+	byCleaner map[string]*models.CleanerSchedule
+}
+
+func (r stubScheduleRepo) GetByCleanerID(_ context.Context, cleanerID string) (*models.CleanerSchedule, error) {
+	if sch, ok := r.byCleaner[cleanerID]; ok {
+		return sch, nil
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (r stubScheduleRepo) Find(_ context.Context, cleanerIDs []string) ([]models.CleanerSchedule, error) {
+	var out []models.CleanerSchedule
+	for _, id := range cleanerIDs {
+		if sch, ok := r.byCleaner[id]; ok {
+			out = append(out, *sch)
+		}
+	}
+	return out, nil
+}
+
+type stubOutbox struct {
+	OutboxRepository
+	mu     sync.Mutex
+	events []*models.OutboxEvent
+}
+
+func (o *stubOutbox) Insert(_ context.Context, events ...*models.OutboxEvent) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, events...)
+	return nil
+}
+
+type stubHistory struct {
+	StatusHistoryRepository
+	mu      sync.Mutex
+	entries []*models.StatusHistoryEntry
+}
+
+func (h *stubHistory) Create(_ context.Context, entry *models.StatusHistoryEntry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, entry)
+	return nil
+}
+
+func (h *stubHistory) LastTransitionTo(context.Context, primitive.ObjectID, models.OrderStatus) (*models.StatusHistoryEntry, error) {
+	return nil, mongo.ErrNoDocuments
+}
+
+type noTx struct{}
+
+func (noTx) WithTx(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) }
+
+// newTestService собирает сервис на заглушках; Redis недоступен, поэтому кэш
+// заказов просто промахивается.
+func newTestService(repo OrderRepository) *orderService {
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 10 * time.Millisecond})
+	cfg := &config.Config{DefaultOrderDurationMinutes: 120, CancelFreeHours: 24, CancelFeePercent: 50}
+	return &orderService{
+		repo:      repo,
+		history:   &stubHistory{},
+		schedules: stubScheduleRepo{},
+		outbox:    &stubOutbox{},
+		tx:        noTx{},
+		cache:     newOrderCache(rdb, time.Minute),
+		redis:     rdb,
+		cfg:       cfg,
+	}
+}
+
+// stubZoneRepo — активные зоны; точка попадает в зону, если contains для неё возвращает true.
+type stubZoneRepo struct {
+	ZoneRepository
+	zones    []models.Zone
+	contains func(zone models.Zone, p models.GeoPoint) bool
+}
+
+func (r stubZoneRepo) GetByID(_ context.Context, id primitive.ObjectID) (*models.Zone, error) {
+	for i := range r.zones {
+		if r.zones[i].ID == id {
+			z := r.zones[i]
+			return &z, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (r stubZoneRepo) CountActive(context.Context) (int64, error) {
+	return int64(len(r.zones)), nil
+}
+
+func (r stubZoneRepo) FindActiveByPoint(_ context.Context, p models.GeoPoint) ([]models.Zone, error) {
+	var out []models.Zone
+	for _, z := range r.zones {
+		if r.contains(z, p) {
+			out = append(out, z)
+		}
+	}
+	return out, nil
+}
+
+type stubAddressRepo struct {
+	AddressRepository
+	byID map[primitive.ObjectID]*models.Address
+}
+
+func (r stubAddressRepo) GetByID(_ context.Context, id primitive.ObjectID) (*models.Address, error) {
+	if a, ok := r.byID[id]; ok {
+		cp := *a
+		return &cp, nil
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+// fakePayments — payment-service для тестов возвратов: отклоняет первые failures запросов,
+// остальные принимает и запоминает; повтор с тем же ключом, как и настоящий, не возвращает
+// деньги второй раз.
+type fakePayments struct {
+	mu       sync.Mutex
+	failures int
+	refunds  []utils.RefundRequest
+}
+
+func newFakePayments(t *testing.T, failures int) (*fakePayments, string) {
+	p := &fakePayments{failures: failures}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req utils.RefundRequest
+		if r.URL.Path != "/payments/refund" || json.NewDecoder(r.Body).Decode(&req) != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.failures > 0 {
+			p.failures--
+			http.Error(w, "provider unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		resp := utils.RefundResponse{Status: "refunded", RefundID: "refund_" + req.IdempotencyKey, Amount: req.Amount}
+		for _, prev := range p.refunds {
+			if prev.IdempotencyKey == req.IdempotencyKey {
+				_ = json.NewEncoder(w).Encode(resp)
+				return
+			}
+		}
+		p.refunds = append(p.refunds, req)
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return p, srv.URL
+}
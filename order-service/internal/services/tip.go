@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+	"cleaning-app/order-service/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TipRepository хранит чаевые клиентов (коллекция tips).
+type TipRepository interface {
+	Create(ctx context.Context, tip *models.Tip) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.Tip, error)
+	ListByOrder(ctx context.Context, orderID string) ([]models.Tip, error)
+	MarkPaid(ctx context.Context, id primitive.ObjectID, paidAt time.Time) (bool, error)
+	MarkFailed(ctx context.Context, id primitive.ObjectID, reason string) error
+}
+
+// TipOrder создаёт чаевые клинерам завершённого заказа и оплачивает их через
+// payment-service (entity_type "tip"). Успешный платёж сразу зачисляется клинерам;
+// уведомление payment-service о том же платеже повторно ничего не начислит.
+func (s *orderService) TipOrder(ctx context.Context, orderID primitive.ObjectID, clientID string, amount float64, message, authHeader string) (*models.Tip, error) {
+	order, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := models.NewTip(order, clientID, amount, message, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tips.Create(ctx, tip); err != nil {
+		return nil, err
+	}
+
+	_, err = utils.RequestPayment(ctx, s.cfg.PaymentServiceURL, utils.PaymentRequest{
+		EntityType: "tip",
+		EntityID:   tip.ID.Hex(),
+		UserID:     clientID,
+		Amount:     tip.Amount,
+	}, authHeader)
+	if err != nil {
+		var declined *utils.PaymentDeclinedError
+		if errors.As(err, &declined) {
+			if err := s.tips.MarkFailed(ctx, tip.ID, declined.Reason); err != nil {
+				log.Printf("[TIP] Failed to mark tip %s as failed: %v", tip.ID.Hex(), err)
+			}
+			return nil, fmt.Errorf("%w: %s", models.ErrTipPaymentFailed, declined.Reason)
+		}
+		// Исход платежа неизвестен: чаевые остаются pending до уведомления payment-service.
+		return nil, fmt.Errorf("tip payment: %w", err)
+	}
+	return s.confirmTip(ctx, tip.ID)
+}
+
+// UpdateTipPaymentStatus обрабатывает уведомление payment-service по чаевым.
+func (s *orderService) UpdateTipPaymentStatus(ctx context.Context, tipID string, status string) error {
+	id, err := primitive.ObjectIDFromHex(tipID)
+	if err != nil {
+		return fmt.Errorf("invalid tip id: %w", err)
+	}
+	// Зачисляются только чаевые, ещё ожидающие оплаты: MarkPaid переводит pending → paid
+	// один раз, поэтому повторное уведомление ничего не начислит.
+	switch status {
+	case "success":
+		_, err = s.confirmTip(ctx, id)
+		return err
+	case "failed":
+		return s.tips.MarkFailed(ctx, id, "payment failed")
+	}
+	return fmt.Errorf("%w: unknown payment status %q", models.ErrValidation, status)
+}
+
+// confirmTip отмечает чаевые оплаченными, записывает доли в книгу заработка
+// и уведомляет клинеров — всё в одной транзакции.
+func (s *orderService) confirmTip(ctx context.Context, id primitive.ObjectID) (*models.Tip, error) {
+	tip, err := s.tips.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tip.Status != models.TipPending {
+		return tip, nil
+	}
+	orderID, err := primitive.ObjectIDFromHex(tip.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order id in tip %s: %w", tip.ID.Hex(), err)
+	}
+	order, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		ok, err := s.tips.MarkPaid(ctx, tip.ID, now)
+		if err != nil || !ok {
+			return err
+		}
+		err = s.earnings.Insert(ctx, tip.LedgerEntries(now)...)
+		if err != nil && !errors.Is(err, models.ErrEarningsPosted) {
+			return err
+		}
+		events := make([]*models.OutboxEvent, 0, len(tip.Shares))
+		for _, share := range tip.Shares {
+			events = append(events, cleanerNotices(order, []string{share.CleanerID}, "tip_received", map[string]string{
+				"tip_id":  tip.ID.Hex(),
+				"amount":  fmt.Sprintf("%.2f", share.Amount),
+				"message": tip.Message,
+			})...)
+		}
+		return s.emit(ctx, events...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.tips.GetByID(ctx, id)
+}
+
+// GetTip отдаёт чаевые клиенту, получившим их клинерам и персоналу.
+func (s *orderService) GetTip(ctx context.Context, id primitive.ObjectID, userID, role string) (*models.Tip, error) {
+	tip, err := s.tips.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !tip.VisibleTo(userID, role) {
+		return nil, models.ErrTipNotFound
+	}
+	return tip, nil
+}
+
+// GetOrderTips — чаевые по заказу; доступ как к самому заказу.
+func (s *orderService) GetOrderTips(ctx context.Context, orderID primitive.ObjectID, userID, role string) ([]models.Tip, error) {
+	order, err := s.repo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOrderAccess(order, userID, role); err != nil {
+		return nil, err
+	}
+	return s.tips.ListByOrder(ctx, orderID.Hex())
+}
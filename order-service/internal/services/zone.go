@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ZoneRepository хранит зоны обслуживания (zones) с 2dsphere-индексом по полигону.
+type ZoneRepository interface {
+	Create(ctx context.Context, zone *models.Zone) error
+	Update(ctx context.Context, zone *models.Zone) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.Zone, error)
+	List(ctx context.Context) ([]models.Zone, error)
+	CountActive(ctx context.Context) (int64, error)
+	FindActiveByPoint(ctx context.Context, p models.GeoPoint) ([]models.Zone, error)
+}
+
+func (s *orderService) CreateZone(ctx context.Context, zone *models.Zone) error {
+	if err := zone.Validate(); err != nil {
+		return err
+	}
+	return s.zones.Create(ctx, zone)
+}
+
+func (s *orderService) UpdateZone(ctx context.Context, id primitive.ObjectID, zone *models.Zone) error {
+	existing, err := s.zones.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := zone.Validate(); err != nil {
+		return err
+	}
+	zone.ID = id
+	zone.CreatedAt = existing.CreatedAt
+	return s.zones.Update(ctx, zone)
+}
+
+func (s *orderService) DeleteZone(ctx context.Context, id primitive.ObjectID) error {
+	return s.zones.Delete(ctx, id)
+}
+
+func (s *orderService) GetZone(ctx context.Context, id primitive.ObjectID) (*models.Zone, error) {
+	return s.zones.GetByID(ctx, id)
+}
+
+func (s *orderService) ListZones(ctx context.Context) ([]models.Zone, error) {
+	return s.zones.List(ctx)
+}
+
+// LookupZone отвечает, обслуживается ли точка: возвращает её зону или ErrOutsideServiceArea.
+// Если активных зон нет, ограничений нет и возвращается nil.
+func (s *orderService) LookupZone(ctx context.Context, loc models.GeoPoint) (*models.Zone, error) {
+	return s.zoneFor(ctx, &loc)
+}
+
+// zoneFor находит активную зону, в которую попадает точка. Пока ни одна зона не включена,
+// приём заказов не ограничивается (nil, nil); иначе без координат заказ не принять.
+func (s *orderService) zoneFor(ctx context.Context, loc *models.GeoPoint) (*models.Zone, error) {
+	active, err := s.zones.CountActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if active == 0 {
+		return nil, nil
+	}
+	if loc == nil || !loc.Valid() {
+		return nil, fmt.Errorf("%w: address coordinates are required to check the service zone", models.ErrValidation)
+	}
+	zones, err := s.zones.FindActiveByPoint(ctx, *loc)
+	if err != nil {
+		return nil, err
+	}
+	if len(zones) == 0 {
+		return nil, models.ErrOutsideServiceArea
+	}
+	return &zones[0], nil
+}
+
+// resolveZone проверяет, что адрес заказа в зоне обслуживания и срок заказа
+// не меньше минимального для зоны, и запоминает зону в заказе.
+func (s *orderService) resolveZone(ctx context.Context, order *models.Order) (*models.Zone, error) {
+	order.ZoneID = ""
+	zone, err := s.zoneFor(ctx, order.Location)
+	if err != nil || zone == nil {
+		return nil, err
+	}
+	if err := zone.CheckNotice(order.Date, time.Now()); err != nil {
+		return nil, err
+	}
+	order.ZoneID = zone.ID.Hex()
+	return zone, nil
+}
+
+// rezoneOrder заново определяет зону после смены адреса: адрес вне зон обслуживания
+// отклоняется. Если у новой зоны другой множитель цены, неоплаченный заказ
+// пересчитывается, а оплаченный перенести в такую зону нельзя.
+func (s *orderService) rezoneOrder(ctx context.Context, order *models.Order) error {
+	before := s.orderZone(ctx, order)
+	zone, err := s.resolveZone(ctx, order)
+	if err != nil {
+		return err
+	}
+	if zoneMultiplier(before) == zoneMultiplier(zone) {
+		return nil
+	}
+	if order.Status != models.StatusPending {
+		return fmt.Errorf("%w: the new address is in a zone with different prices; only unpaid orders can be repriced", models.ErrRequoteRequired)
+	}
+	return s.repriceOrder(ctx, order)
+}
+
+func zoneMultiplier(zone *models.Zone) float64 {
+	if zone == nil || zone.PriceMultiplier <= 0 {
+		return 1
+	}
+	return zone.PriceMultiplier
+}
+
+// orderZone — зона, сохранённая в заказе. Удалённая зона не мешает работе с заказом.
+func (s *orderService) orderZone(ctx context.Context, order *models.Order) *models.Zone {
+	if order.ZoneID == "" {
+		return nil
+	}
+	id, err := primitive.ObjectIDFromHex(order.ZoneID)
+	if err != nil {
+		return nil
+	}
+	zone, err := s.zones.GetByID(ctx, id)
+	if err != nil {
+		log.Printf("[ZONE] Zone %s of order %s unavailable: %v", order.ZoneID, order.ID.Hex(), err)
+		return nil
+	}
+	return zone
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Смена адреса заново определяет зону: вне зон заказ не переносится, а оплаченный
+// заказ нельзя перенести в зону с другой ценой.
+func TestUpdateOrderAddressRezones(t *testing.T) {
+	// Зоны различаются по широте: центр севернее 55.7, пригород южнее, 0 — вне зон.
+	center := models.Zone{ID: primitive.NewObjectID(), Name: "center", Active: true, PriceMultiplier: 1.2}
+	centerEast := models.Zone{ID: primitive.NewObjectID(), Name: "center-east", Active: true, PriceMultiplier: 1.2}
+	suburb := models.Zone{ID: primitive.NewObjectID(), Name: "suburb", Active: true, PriceMultiplier: 1}
+	zones := stubZoneRepo{
+		zones: []models.Zone{center, centerEast, suburb},
+		contains: func(z models.Zone, p models.GeoPoint) bool {
+			switch z.Name {
+			case "center":
+				return p.Lat > 55.7 && p.Lng < 37.7
+			case "center-east":
+				return p.Lat > 55.7 && p.Lng >= 37.7
+			default:
+				return p.Lat > 55 && p.Lat <= 55.7
+			}
+		},
+	}
+	addrAt := func(lat, lng float64) *models.Address {
+		return &models.Address{
+			ID:              primitive.NewObjectID(),
+			ClientID:        "client",
+			AddressSnapshot: models.AddressSnapshot{City: "Москва", Street: "Тверская", Building: "1", Location: &models.GeoPoint{Lat: lat, Lng: lng}},
+		}
+	}
+	east, south, nowhere := addrAt(55.75, 37.8), addrAt(55.6, 37.6), addrAt(10, 10)
+	addresses := stubAddressRepo{byID: map[primitive.ObjectID]*models.Address{east.ID: east, south.ID: south, nowhere.ID: nowhere}}
+
+	cases := []struct {
+		name     string
+		address  *models.Address
+		wantErr  error
+		wantZone string
+	}{
+		{"outside every zone", nowhere, models.ErrOutsideServiceArea, center.ID.Hex()},
+		{"zone with other prices", south, models.ErrRequoteRequired, center.ID.Hex()},
+		{"zone with same prices", east, nil, centerEast.ID.Hex()},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			order := &models.Order{
+				ID:       primitive.NewObjectID(),
+				ClientID: "client",
+				Status:   models.StatusPaid,
+				Date:     time.Now().Add(72 * time.Hour),
+				Location: &models.GeoPoint{Lat: 55.75, Lng: 37.6},
+				ZoneID:   center.ID.Hex(),
+				Version:  1,
+			}
+			repo := newStubOrderRepo(order)
+			svc := newTestService(repo)
+			svc.zones, svc.addresses = zones, addresses
+
+			err := svc.UpdateOrder(context.Background(), order.ID, &models.Order{AddressID: tc.address.ID.Hex()})
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tc.wantErr)
+			}
+			stored, _ := repo.GetByID(context.Background(), order.ID)
+			if stored.ZoneID != tc.wantZone {
+				t.Errorf("zone = %s, want %s", stored.ZoneID, tc.wantZone)
+			}
+		})
+	}
+}
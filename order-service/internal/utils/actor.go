@@ -0,0 +1,24 @@
+package utils
+
+import "context"
+
+type actorKey struct{}
+
+type actor struct {
+	id   string
+	role string
+}
+
+// WithActor кладёт в контекст пользователя, выполняющего запрос.
+func WithActor(ctx context.Context, userID, role string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor{id: userID, role: role})
+}
+
+// ActorFromContext возвращает userID и роль инициатора запроса.
+// Для фоновых задач и внутренних вызовов без авторизации — "system".
+func ActorFromContext(ctx context.Context) (string, string) {
+	if a, ok := ctx.Value(actorKey{}).(actor); ok {
+		return a.id, a.role
+	}
+	return "system", "system"
+}
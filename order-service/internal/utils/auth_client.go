@@ -2,39 +2,51 @@ package utils
 
 import (
 	"bytes"
+	"cleaning-app/order-service/internal/config"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 )
 
 type authClient struct {
 	baseURL    string
+	secret     string
 	httpClient *http.Client
 }
 
-func NewAuthClient(baseURL string) *authClient {
+// NewAuthClient — клиент внутреннего API auth-service; вызовы подписываются secret.
+func NewAuthClient(baseURL, secret string) *authClient {
 	return &authClient{
 		baseURL:    baseURL,
+		secret:     secret,
 		httpClient: &http.Client{},
 	}
 }
 
-func (c *authClient) AddBulkRatings(ctx context.Context, cleanerIDs []string, rating int, comment string, authHeader string) error {
-	payload := map[string]interface{}{
-		"cleaner_ids": cleanerIDs,
-		"rating":      rating,
-		"comment":     comment,
-	}
-	body, _ := json.Marshal(payload)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/auth/add-ratings", c.baseURL), bytes.NewBuffer(body))
+// PushCleanerRating передаёт в auth-service рейтинг клинера, пересчитанный по отзывам.
+// Повторная или запоздавшая доставка безопасна: auth-service сравнивает asOf с сохранённым.
+func PushCleanerRating(ctx context.Context, cfg *config.Config, cleanerID string, average float64, count int, asOf time.Time) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"average_rating": average,
+		"rating_count":   count,
+		"as_of":          asOf,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+	url := fmt.Sprintf("%s/auth/internal/cleaners/%s/rating", cfg.AuthServiceURL, cleanerID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer(body))
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", authHeader)
-	resp, err := c.httpClient.Do(req)
+	SignInternalRequest(req, cfg.InternalAPISecret, body)
+
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
@@ -45,3 +57,34 @@ func (c *authClient) AddBulkRatings(ctx context.Context, cleanerIDs []string, ra
 	}
 	return nil
 }
+
+// CleanerProfile — клинер с рейтингом из auth-service (GET /auth/internal/cleaners).
+type CleanerProfile struct {
+	ID            string  `json:"id"`
+	FirstName     string  `json:"first_name"`
+	LastName      string  `json:"last_name"`
+	AverageRating float64 `json:"average_rating"`
+	RatingCount   int     `json:"rating_count"`
+}
+
+func (c *authClient) GetCleaners(ctx context.Context) ([]CleanerProfile, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/auth/internal/cleaners", c.baseURL), nil)
+	if err != nil {
+		return nil, err
+	}
+	SignInternalRequest(req, c.secret, nil)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("auth service %d: %s", resp.StatusCode, string(respBody))
+	}
+	var cleaners []CleanerProfile
+	if err := json.NewDecoder(resp.Body).Decode(&cleaners); err != nil {
+		return nil, fmt.Errorf("decode error: %w", err)
+	}
+	return cleaners, nil
+}
@@ -10,38 +10,76 @@ import (
 	"time"
 )
 
-func SendGamificationXP(cfg *config.Config, userID string, xp int) {
-	go func() {
-		// Создаём собственный контекст с таймаутом, вместо ctx (который отменится при выходе из HTTP-хендлера)
-		innerCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-		defer cancel()
-
-		url := fmt.Sprintf("%s/users/gamification/add-xp", cfg.UserManagementURL)
-
-		payload := map[string]interface{}{
-			"user_id": userID,
-			"xp":      xp,
-		}
-		data, _ := json.Marshal(payload)
-
-		req, err := http.NewRequestWithContext(innerCtx, http.MethodPost, url, bytes.NewBuffer(data))
-		if err != nil {
-			fmt.Printf("[GamificationClient] Ошибка при создании запроса: %v\n", err)
-			return
-		}
-		req.Header.Set("Content-Type", "application/json")
-
-		client := &http.Client{Timeout: 3 * time.Second}
-		resp, err := client.Do(req)
-		if err != nil {
-			fmt.Printf("[GamificationClient] Не удалось отправить XP (userID=%s): %v\n", userID, err)
-			return
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode >= 300 {
-			fmt.Printf("[GamificationClient] Получен статус %d при отправке XP пользователю %s\n", resp.StatusCode, userID)
-			return
-		}
-	}()
+// AddGamificationXP начисляет опыт пользователю. key — ключ идемпотентности:
+// user-management не начислит XP повторно при повторной доставке.
+func AddGamificationXP(ctx context.Context, cfg *config.Config, userID string, xp int, key string) error {
+	innerCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/users/gamification/add-xp", cfg.UserManagementURL)
+	data, err := json.Marshal(map[string]interface{}{
+		"user_id":         userID,
+		"xp":              xp,
+		"idempotency_key": key,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(innerCtx, http.MethodPost, url, bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("new request error: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("http error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("user management returned %d for user %s", resp.StatusCode, userID)
+	}
+	return nil
+}
+
+// FetchGamificationLevels запрашивает текущие уровни пользователей одним вызовом
+// (POST /users/gamification/statuses). Возвращает userID → уровень.
+func FetchGamificationLevels(ctx context.Context, cfg *config.Config, userIDs []string) (map[string]int, error) {
+	data, err := json.Marshal(map[string]interface{}{"user_ids": userIDs})
+	if err != nil {
+		return nil, fmt.Errorf("marshal error: %w", err)
+	}
+	url := fmt.Sprintf("%s/users/gamification/statuses", cfg.UserManagementURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, fmt.Errorf("new request error: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	SignInternalRequest(req, cfg.InternalAPISecret, data)
+
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http error: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("non-200 from user management: %d", resp.StatusCode)
+	}
+
+	var statuses []struct {
+		UserID       string `json:"user_id"`
+		CurrentLevel int    `json:"current_level"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&statuses); err != nil {
+		return nil, fmt.Errorf("decode error: %w", err)
+	}
+	levels := make(map[string]int, len(statuses))
+	for _, st := range statuses {
+		levels[st.UserID] = st.CurrentLevel
+	}
+	return levels, nil
 }
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"cleaning-app/order-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const IdempotencyHeader = "Idempotency-Key"
+
+// IdempotencyStore — хранилище ответов по ключу идемпотентности (см. repository.NewIdempotencyRepository).
+type IdempotencyStore interface {
+	Begin(ctx context.Context, rec *models.IdempotencyRecord) (*models.IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte, expiresAt time.Time) error
+	Release(ctx context.Context, key string) error
+}
+
+// capturingWriter дублирует тело ответа в буфер, чтобы сохранить его для повторов.
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware обрабатывает заголовок Idempotency-Key на изменяющих запросах.
+// Первый ответ сохраняется на ttl и отдаётся повторно на запросы с тем же ключом и телом
+// (с заголовком Idempotent-Replayed: true); тот же ключ с другим телом — 422,
+// пока первый запрос ещё выполняется — 409. Ответы 5xx не сохраняются.
+// Должен стоять после AuthMiddleware: ключи разделяются по пользователю.
+func IdempotencyMiddleware(store IdempotencyStore, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		if len(key) > models.MaxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key is too long"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		now := time.Now()
+		rec := &models.IdempotencyRecord{
+			Key:         models.IdempotencyScope(c.GetString("userId"), c.Request.Method, c.FullPath(), key),
+			RequestHash: models.RequestFingerprint(c.Request.Method, c.Request.URL.Path, body),
+			Status:      models.IdempotencyInProgress,
+			CreatedAt:   now,
+			LockedUntil: now.Add(models.IdempotencyLease),
+			ExpiresAt:   now.Add(ttl),
+		}
+
+		stored, fresh, err := store.Begin(ctx, rec)
+		if err != nil {
+			log.Printf("[IDEMPOTENCY] Failed to claim key %q: %v", key, err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Idempotency store unavailable"})
+			return
+		}
+		if !fresh {
+			switch err := stored.Check(rec.RequestHash); {
+			case errors.Is(err, models.ErrIdempotencyMismatch):
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			case errors.Is(err, models.ErrIdempotencyInProgress):
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(stored.StatusCode, stored.ContentType, stored.Body)
+				c.Abort()
+			}
+			return
+		}
+
+		// Если обработчик упал с паникой или вернул 5xx — освобождаем ключ, клиент повторит запрос.
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := store.Release(context.WithoutCancel(ctx), rec.Key); err != nil {
+				log.Printf("[IDEMPOTENCY] Failed to release key %q: %v", key, err)
+			}
+		}()
+
+		w := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		status := w.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		err = store.Complete(context.WithoutCancel(ctx), rec.Key, status, w.Header().Get("Content-Type"), w.body.Bytes(), time.Now().Add(ttl))
+		if err != nil {
+			log.Printf("[IDEMPOTENCY] Failed to store response for key %q: %v", key, err)
+			return
+		}
+		completed = true
+	}
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// FetchDisputeEvidenceURLs возвращает адреса фото, загруженных к спору по заказу
+// через media-service (GET /media/disputes/:orderId).
+func FetchDisputeEvidenceURLs(ctx context.Context, baseURL, orderID, authHeader string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/media/disputes/"+orderID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request error: %w", err)
+	}
+	req.Header.Set("Authorization", authHeader)
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("media service %d: %s", resp.StatusCode, string(body))
+	}
+
+	var medias []struct{ URL string }
+	if err := json.NewDecoder(resp.Body).Decode(&medias); err != nil {
+		return nil, fmt.Errorf("decode error: %w", err)
+	}
+	urls := make([]string, 0, len(medias))
+	for _, m := range medias {
+		urls = append(urls, m.URL)
+	}
+	return urls, nil
+}
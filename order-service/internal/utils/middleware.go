@@ -72,6 +72,7 @@ func AuthMiddleware(authURL string) gin.HandlerFunc {
 		log.Printf("[AUTH] Authenticated user: %s with role: %s", data.UserID, data.Role)
 		c.Set("userId", data.UserID)
 		c.Set("role", data.Role)
+		c.Request = c.Request.WithContext(WithActor(c.Request.Context(), data.UserID, data.Role))
 		c.Next()
 	}
 }
@@ -17,6 +17,8 @@ type NotificationEvent struct {
 	Message     string            `json:"message,omitempty"`      // Можно оставить пустым — будет дефолтный
 	ExtraData   map[string]string `json:"extra_data,omitempty"`   // Любые доп. поля (например, email)
 	DeviceToken string            `json:"device_token,omitempty"` // Для пуша
+	// IdempotencyKey — ключ для отсечения повторной доставки на стороне notification-service
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 }
 
 const NotificationEventsChannel = "notification_events"
@@ -44,3 +46,30 @@ func SendNotificationEvent(ctx context.Context, event NotificationEvent) error {
 	}
 	return redisClient.Publish(ctx, NotificationEventsChannel, payload).Err()
 }
+
+// NotificationEventsStream — Redis Stream, из которого notification-service читает события
+// через группу потребителей и подтверждает (XACK) каждое только после сохранения.
+const NotificationEventsStream = "notification_events_stream"
+
+// notificationStreamMaxLen ограничивает длину стрима (приблизительно, MAXLEN ~).
+const notificationStreamMaxLen = 100000
+
+// DeliverNotificationEvent добавляет событие в стрим notification_events_stream.
+// Успешный XADD означает, что событие сохранено в Redis: группа потребителей
+// notification-service получит его, даже если сервис сейчас не запущен, и будет
+// получать повторно, пока не подтвердит обработку.
+func DeliverNotificationEvent(ctx context.Context, event NotificationEvent) error {
+	if redisClient == nil {
+		InitRedisClient()
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: NotificationEventsStream,
+		MaxLen: notificationStreamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"payload": payload},
+	}).Err()
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// RefundRequest — запрос возврата. По IdempotencyKey payment-service узнаёт повтор
+// и не возвращает деньги второй раз.
+type RefundRequest struct {
+	EntityType     string  `json:"entity_type"`
+	EntityID       string  `json:"entity_id"`
+	UserID         string  `json:"user_id"`
+	Amount         float64 `json:"amount"`
+	Reason         string  `json:"reason,omitempty"`
+	IdempotencyKey string  `json:"idempotency_key,omitempty"`
+}
+
+type RefundResponse struct {
+	Status   string  `json:"status"`
+	RefundID string  `json:"refund_id"`
+	Amount   float64 `json:"amount"`
+}
+
+// RequestRefund вызывает POST /payments/refund в payment-service.
+func RequestRefund(ctx context.Context, baseURL string, refund RefundRequest, authHeader string) (*RefundResponse, error) {
+	payload, err := json.Marshal(refund)
+	if err != nil {
+		return nil, fmt.Errorf("marshal error: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/payments/refund", bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, fmt.Errorf("new request error: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", authHeader)
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("payment service %d: %s", resp.StatusCode, string(body))
+	}
+
+	var out RefundResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode error: %w", err)
+	}
+	return &out, nil
+}
+
+type PaymentRequest struct {
+	EntityType string  `json:"entity_type"`
+	EntityID   string  `json:"entity_id"`
+	UserID     string  `json:"user_id"`
+	Amount     float64 `json:"amount"`
+}
+
+type PaymentResponse struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// PaymentDeclinedError — payment-service отказал в платеже (например, не сошлась сумма).
+type PaymentDeclinedError struct {
+	Reason string
+}
+
+func (e *PaymentDeclinedError) Error() string {
+	return "payment declined: " + e.Reason
+}
+
+// RequestPayment вызывает POST /payments в payment-service от имени клиента.
+// Отказ провайдера возвращается как *PaymentDeclinedError.
+func RequestPayment(ctx context.Context, baseURL string, payment PaymentRequest, authHeader string) (*PaymentResponse, error) {
+	payload, err := json.Marshal(payment)
+	if err != nil {
+		return nil, fmt.Errorf("marshal error: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/payments", bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, fmt.Errorf("new request error: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", authHeader)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	var out PaymentResponse
+	if resp.StatusCode != http.StatusOK {
+		if json.Unmarshal(body, &out) == nil && out.Status == "failed" {
+			return nil, &PaymentDeclinedError{Reason: out.Reason}
+		}
+		return nil, fmt.Errorf("payment service %d: %s", resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("decode error: %w", err)
+	}
+	if out.Status != "success" {
+		return nil, &PaymentDeclinedError{Reason: out.Reason}
+	}
+	return &out, nil
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+type SupportTicketRequest struct {
+	Subject  string `json:"subject"`
+	Text     string `json:"text"`
+	OrderID  string `json:"order_id"`
+	Category string `json:"category,omitempty"`
+}
+
+// CreateSupportTicket открывает тикет в support-service от имени пользователя из authHeader
+// и возвращает его ID.
+func CreateSupportTicket(ctx context.Context, baseURL string, ticket SupportTicketRequest, authHeader string) (string, error) {
+	payload, err := json.Marshal(ticket)
+	if err != nil {
+		return "", fmt.Errorf("marshal error: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/support/tickets", bytes.NewBuffer(payload))
+	if err != nil {
+		return "", fmt.Errorf("new request error: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", authHeader)
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("http error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("support service %d: %s", resp.StatusCode, string(body))
+	}
+
+	var out struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("decode error: %w", err)
+	}
+	return out.ID, nil
+}
+
+// CloseSupportTicket переводит тикет в closed (PUT /support/tickets/:id/status).
+func CloseSupportTicket(ctx context.Context, baseURL, ticketID, authHeader string) error {
+	payload, _ := json.Marshal(map[string]string{"status": "closed"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, baseURL+"/support/tickets/"+ticketID+"/status", bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("new request error: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", authHeader)
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("http error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("support service %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
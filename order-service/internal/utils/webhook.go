@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookSignatureHeader — HMAC-SHA256 тела уведомления payment-service в hex.
+const WebhookSignatureHeader = "X-Payment-Signature"
+
+// RequireWebhookSignature пропускает только уведомления, подписанные общим секретом
+// PAYMENT_WEBHOOK_SECRET. Без настроенного секрета уведомления не принимаются вовсе.
+func RequireWebhookSignature(secret string) gin.HandlerFunc {
+	if secret == "" {
+		log.Printf("[WEBHOOK] PAYMENT_WEBHOOK_SECRET is not set: payment notifications will be rejected")
+	}
+	return func(c *gin.Context) {
+		if secret == "" {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "payment notifications are not configured"})
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+			return
+		}
+		got, err := hex.DecodeString(c.GetHeader(WebhookSignatureHeader))
+		if err != nil || !hmac.Equal(got, signWebhook(secret, body)) {
+			log.Printf("[WEBHOOK] Rejected payment notification with invalid signature from %s", c.ClientIP())
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+func signWebhook(secret string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
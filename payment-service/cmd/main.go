@@ -13,16 +13,18 @@ func main() {
 	// Считываем URL-ы из окружения
 	orderURL := os.Getenv("ORDER_SERVICE_URL")
 	subURL := os.Getenv("SUBSCRIPTION_SERVICE_URL")
+	webhookSecret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
 	port := os.Getenv("PAYMENT_SERVICE_PORT")
 	if port == "" {
 		port = "8005"
 	}
 
 	// Создаём хендлер, передаём адреса других сервисов
-	paymentHandler := handler.NewPaymentHandler(orderURL, subURL)
+	paymentHandler := handler.NewPaymentHandler(orderURL, subURL, webhookSecret)
 
-	// Регистрируем единственный endpoint
+	// Регистрируем endpoints
 	http.HandleFunc("/payments", paymentHandler.Pay)
+	http.HandleFunc("/payments/refund", paymentHandler.Refund)
 
 	// Запускаем HTTP-сервер
 	addr := ":" + port
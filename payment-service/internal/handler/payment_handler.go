@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sync"
 	"time"
 
 	"cleaning-app/payment-service/internal/utils"
@@ -14,14 +15,22 @@ import (
 type PaymentHandler struct {
 	OrderServiceURL        string
 	SubscriptionServiceURL string
-	httpClient             *http.Client
+	// webhookSecret подписывает уведомления order-service (PAYMENT_WEBHOOK_SECRET).
+	webhookSecret string
+	httpClient    *http.Client
+
+	// refunds — уже выполненные возвраты по ключу идемпотентности (см. refundKey).
+	refundsMu sync.Mutex
+	refunds   map[string]RefundResponse
 }
 
-func NewPaymentHandler(orderURL, subscriptionURL string) *PaymentHandler {
+func NewPaymentHandler(orderURL, subscriptionURL, webhookSecret string) *PaymentHandler {
 	return &PaymentHandler{
 		OrderServiceURL:        orderURL,
 		SubscriptionServiceURL: subscriptionURL,
+		webhookSecret:          webhookSecret,
 		httpClient:             &http.Client{Timeout: 5 * time.Second},
+		refunds:                map[string]RefundResponse{},
 	}
 }
 
@@ -80,7 +89,7 @@ func (h *PaymentHandler) Pay(w http.ResponseWriter, r *http.Request) {
 
 		go func(entityID, jwt string) {
 			notifyURL := fmt.Sprintf("%s/api/internal/payments/notify", h.OrderServiceURL)
-			utils.NotifyJSONWithAuth(h.httpClient, notifyURL, entityID, "success", jwt)
+			utils.NotifyJSONWithAuth(h.httpClient, notifyURL, "order", entityID, "success", jwt, h.webhookSecret)
 		}(reqBody.EntityID, token)
 
 		resp := PaymentResponse{Status: "success", ClientSecret: "mock_client_secret_123"}
@@ -102,7 +111,29 @@ func (h *PaymentHandler) Pay(w http.ResponseWriter, r *http.Request) {
 
 		go func(entityID, jwt string) {
 			notifyURL := fmt.Sprintf("%s/api/payments/notify", h.SubscriptionServiceURL)
-			utils.NotifyJSONWithAuth(h.httpClient, notifyURL, entityID, "success", jwt)
+			utils.NotifyJSONWithAuth(h.httpClient, notifyURL, "subscription", entityID, "success", jwt, "")
+		}(reqBody.EntityID, token)
+
+		resp := PaymentResponse{Status: "success", ClientSecret: "mock_client_secret_123"}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(resp)
+
+	case "tip":
+		// Чаевые клинерам: entity_id — id чаевых в order-service.
+		ok, reason := h.validateTip(reqBody.EntityID, reqBody.Amount, token)
+		if !ok {
+			log.Printf("[WARN][PaymentService] validateTip failed: %s", reason)
+			resp := PaymentResponse{Status: "failed", Reason: reason}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(resp)
+			return
+		}
+
+		go func(entityID, jwt string) {
+			notifyURL := fmt.Sprintf("%s/api/internal/payments/notify", h.OrderServiceURL)
+			utils.NotifyJSONWithAuth(h.httpClient, notifyURL, "tip", entityID, "success", jwt, h.webhookSecret)
 		}(reqBody.EntityID, token)
 
 		resp := PaymentResponse{Status: "success", ClientSecret: "mock_client_secret_123"}
@@ -112,7 +143,7 @@ func (h *PaymentHandler) Pay(w http.ResponseWriter, r *http.Request) {
 
 	default:
 		log.Printf("[WARN][PaymentService] unknown entity_type: %s", reqBody.EntityType)
-		http.Error(w, "entity_type должен быть 'order' или 'subscription'", http.StatusBadRequest)
+		http.Error(w, "entity_type должен быть 'order', 'subscription' или 'tip'", http.StatusBadRequest)
 	}
 }
 
@@ -191,3 +222,134 @@ func (h *PaymentHandler) validateSubscription(subID string, amount float64, toke
 	}
 	return true, ""
 }
+
+func (h *PaymentHandler) validateTip(tipID string, amount float64, token string) (bool, string) {
+	url := fmt.Sprintf("%s/tips/%s", h.OrderServiceURL, tipID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	utils.AttachBearerHeader(req, token)
+	res, err := h.httpClient.Do(req)
+	if err != nil {
+		return false, "Order Service unreachable"
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusNotFound:
+		return false, "Tip not found"
+	case http.StatusUnauthorized:
+		return false, "Order Service returned status 401"
+	case http.StatusOK:
+	default:
+		return false, fmt.Sprintf("Order Service returned status %d", res.StatusCode)
+	}
+
+	var respBody struct {
+		ID     string  `json:"id"`
+		Amount float64 `json:"amount"`
+		Status string  `json:"status"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&respBody); err != nil {
+		return false, "failed to parse Order Service response"
+	}
+
+	if respBody.Status != "pending" {
+		return false, "tip is already " + respBody.Status
+	}
+	if respBody.Amount != amount {
+		return false, "amount mismatch"
+	}
+	return true, ""
+}
+
+type RefundRequest struct {
+	EntityType     string  `json:"entity_type"`
+	EntityID       string  `json:"entity_id"`
+	UserID         string  `json:"user_id"`
+	Amount         float64 `json:"amount"`
+	Reason         string  `json:"reason,omitempty"`
+	IdempotencyKey string  `json:"idempotency_key,omitempty"`
+}
+
+// refundKey — ключ, по которому повторный запрос возврата узнаётся как уже выполненный:
+// idempotency_key, а без него — сущность и причина возврата.
+func (r *RefundRequest) refundKey() string {
+	if r.IdempotencyKey != "" {
+		return "key:" + r.IdempotencyKey
+	}
+	return r.EntityType + ":" + r.EntityID + ":" + r.Reason
+}
+
+type RefundResponse struct {
+	Status   string  `json:"status"`
+	RefundID string  `json:"refund_id,omitempty"`
+	Amount   float64 `json:"amount"`
+	Reason   string  `json:"reason,omitempty"`
+}
+
+// Refund возвращает клиенту сумму (полностью или частично) по ранее оплаченной сущности.
+// Сумму считает вызывающий сервис по своей политике; здесь — только проверка и мок-провайдер.
+func (h *PaymentHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	log.Printf("[TRACE][PaymentService] Refund called: Method=%s, Path=%s", r.Method, r.URL.Path)
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := utils.GetBearerToken(r)
+	if token == "" {
+		http.Error(w, "Authorization header missing or invalid", http.StatusUnauthorized)
+		return
+	}
+
+	var reqBody RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		log.Printf("[ERROR][PaymentService] invalid refund body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if reqBody.EntityType == "" || reqBody.EntityID == "" || reqBody.UserID == "" || reqBody.Amount <= 0 {
+		http.Error(w, "entity_type, entity_id, user_id и amount обязательны и amount > 0", http.StatusBadRequest)
+		return
+	}
+	if reqBody.EntityType != "order" {
+		http.Error(w, "refund поддерживается только для entity_type 'order'", http.StatusBadRequest)
+		return
+	}
+
+	// Повтор уже выполненного возврата не возвращает деньги второй раз, а отдаёт прежний
+	// результат; тот же ключ с другой суммой — ошибка вызывающего сервиса.
+	key := reqBody.refundKey()
+	h.refundsMu.Lock()
+	defer h.refundsMu.Unlock()
+	if prev, ok := h.refunds[key]; ok {
+		if prev.Amount != reqBody.Amount {
+			http.Error(w, "refund with this key was already made for a different amount", http.StatusConflict)
+			return
+		}
+		log.Printf("[TRACE][PaymentService] Refund %s already made as %s", key, prev.RefundID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(prev)
+		return
+	}
+
+	log.Printf("[TRACE][PaymentService] Refund %.2f for %s %s (user %s): %s",
+		reqBody.Amount, reqBody.EntityType, reqBody.EntityID, reqBody.UserID, reqBody.Reason)
+
+	resp := RefundResponse{
+		Status:   "refunded",
+		RefundID: fmt.Sprintf("mock_refund_%s_%d", reqBody.EntityID, time.Now().UnixNano()),
+		Amount:   reqBody.Amount,
+	}
+	h.refunds[key] = resp
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
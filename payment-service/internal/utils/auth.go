@@ -2,6 +2,9 @@ package utils
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +12,16 @@ import (
 	"strings"
 )
 
+// SignatureHeader — заголовок с HMAC-SHA256 тела уведомления (общий секрет с получателем).
+const SignatureHeader = "X-Payment-Signature"
+
+// SignPayload возвращает HMAC-SHA256 тела в hex.
+func SignPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 // GetBearerToken извлекает токен из заголовка Authorization: Bearer <token>.
 // Если заголовка нет или он невалиден, возвращает пустую строку.
 func GetBearerToken(r *http.Request) string {
@@ -45,11 +58,12 @@ func AttachBearerHeader(req *http.Request, token string) {
 	}
 }
 
-// NotifyJSONWithAuth отправляет POST с JSON-полем {"entity_id":..,"status":..}
-// на указанный URL, добавляя Authorization: Bearer <token>.
+// NotifyJSONWithAuth отправляет POST с JSON {"entity_type":..,"entity_id":..,"status":..}
+// на указанный URL, добавляя Authorization: Bearer <token> и, если задан secret,
+// подпись тела в заголовке X-Payment-Signature.
 // client должен быть уже инициализирован.
-func NotifyJSONWithAuth(client *http.Client, url, entityID, status, token string) {
-	payload := map[string]string{"entity_id": entityID, "status": status}
+func NotifyJSONWithAuth(client *http.Client, url, entityType, entityID, status, token, secret string) {
+	payload := map[string]string{"entity_type": entityType, "entity_id": entityID, "status": status}
 	b, _ := json.Marshal(payload)
 
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(b))
@@ -59,6 +73,9 @@ func NotifyJSONWithAuth(client *http.Client, url, entityID, status, token string
 	}
 	req.Header.Set("Content-Type", "application/json")
 	AttachBearerHeader(req, token)
+	if secret != "" {
+		req.Header.Set(SignatureHeader, SignPayload(secret, b))
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
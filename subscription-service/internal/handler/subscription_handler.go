@@ -69,6 +69,7 @@ func (h *SubscriptionHandler) Create(c *gin.Context) {
 		Frequency   models.Frequency   `json:"frequency"    binding:"required"`
 		DaysOfWeek  []string           `json:"days_of_week" binding:"required"`
 		WeekNumbers []int              `json:"week_numbers"`
+		AddressID   string             `json:"address_id"` // опц.; по умолчанию — адрес исходного заказа
 	}
 
 	// 1) считать JSON
@@ -122,6 +123,15 @@ func (h *SubscriptionHandler) Create(c *gin.Context) {
 	}
 	calculatedPrice := orderResp.TotalPrice
 
+	addressID := orderResp.AddressID
+	if in.AddressID != "" {
+		if err := h.orderClient.CheckAddress(c.Request.Context(), in.AddressID, authHeader); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to fetch address: " + err.Error()})
+			return
+		}
+		addressID = in.AddressID
+	}
+
 	now := time.Now().UTC()
 	// 5) собираем модель новой подписки
 	sub := &models.Subscription{
@@ -134,6 +144,7 @@ func (h *SubscriptionHandler) Create(c *gin.Context) {
 			DaysOfWeek:  in.DaysOfWeek,
 			WeekNumbers: in.WeekNumbers,
 		},
+		AddressID:       addressID,
 		Price:           calculatedPrice,
 		Status:          models.StatusActive,
 		CreatedAt:       now,
@@ -35,6 +35,7 @@ type Subscription struct {
 	StartDate       time.Time          `bson:"start_date"              json:"start_date"`
 	EndDate         time.Time          `bson:"end_date"                json:"end_date"`
 	Schedule        ScheduleSpec       `bson:"schedule"      json:"schedule"`
+	AddressID       string             `bson:"address_id,omitempty"    json:"address_id,omitempty"`
 	Price           float64            `bson:"price"                   json:"price"`
 	Status          SubscriptionStatus `bson:"status"                  json:"status"`
 	CreatedAt       time.Time          `bson:"created_at"              json:"created_at"`
@@ -20,6 +20,7 @@ type OrderResponse struct {
 	ID             string          `json:"id"`
 	ClientID       string          `json:"client_id"`
 	Address        string          `json:"address"`
+	AddressID      string          `json:"address_id,omitempty"`
 	ServiceType    string          `json:"service_type"`
 	ServiceIDs     []string        `json:"service_ids"`
 	ServiceDetails []ServiceDetail `json:"service_details"`
@@ -74,6 +75,10 @@ func (c *OrderServiceClient) CreateOrderFromSubscription(ctx context.Context, su
 		"status":  "prepaid",                          // фиксируем prepaid
 		"comment": "Автоматический заказ по подписке", // любой текст
 	}
+	// Сохранённый адрес подписки: order-service сам сделает копию в новом заказе.
+	if sub.AddressID != "" {
+		newBody["address_id"] = sub.AddressID
+	}
 	jsonData, err := json.Marshal(newBody)
 	if err != nil {
 		return fmt.Errorf("marshal new order body: %w", err)
@@ -86,6 +91,8 @@ func (c *OrderServiceClient) CreateOrderFromSubscription(ctx context.Context, su
 	}
 	postReq.Header.Set("Content-Type", "application/json")
 	postReq.Header.Set("Authorization", authHeader)
+	// Повторный прогон планировщика по той же дате получит уже созданный заказ, а не дубль.
+	postReq.Header.Set("Idempotency-Key", "subscription:"+sub.ID.Hex()+":"+sub.NextPlannedDate.UTC().Format("2006-01-02"))
 
 	postResp, err := http.DefaultClient.Do(postReq)
 	if err != nil {
@@ -124,3 +131,23 @@ func (c *OrderServiceClient) GetOrderByID(ctx context.Context, orderID, authHead
 	}
 	return &out, nil
 }
+
+// CheckAddress проверяет, что сохранённый адрес существует и доступен клиенту (GET /addresses/:id).
+func (c *OrderServiceClient) CheckAddress(ctx context.Context, addressID, authHeader string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL+"/addresses/"+addressID, nil)
+	if err != nil {
+		return fmt.Errorf("build get-address request: %w", err)
+	}
+	req.Header.Set("Authorization", authHeader)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("call order service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("order service returned status %d", resp.StatusCode)
+	}
+	return nil
+}
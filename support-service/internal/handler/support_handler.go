@@ -53,8 +53,10 @@ func NewSupportHandler(srv SupportService, userServiceURL string) *SupportHandle
 // POST /support/tickets
 func (h *SupportHandler) CreateTicket(c *gin.Context) {
 	var req struct {
-		Subject string `json:"subject"`
-		Text    string `json:"text"`
+		Subject  string `json:"subject"`
+		Text     string `json:"text"`
+		OrderID  string `json:"order_id"`
+		Category string `json:"category"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
@@ -65,6 +67,8 @@ func (h *SupportHandler) CreateTicket(c *gin.Context) {
 	t := &models.Ticket{
 		ClientID:  userID,
 		Subject:   req.Subject,
+		OrderID:   req.OrderID,
+		Category:  req.Category,
 		Status:    models.StatusOpen,
 		CreatedAt: now,
 		UpdatedAt: now,
@@ -73,6 +77,20 @@ func (h *SupportHandler) CreateTicket(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	// Текст обращения становится первым сообщением тикета.
+	if req.Text != "" {
+		msg := &models.Message{
+			TicketID:   t.ID,
+			SenderID:   userID,
+			SenderRole: c.GetString("role"),
+			Text:       req.Text,
+			Timestamp:  now,
+		}
+		if err := h.service.AddMessage(c.Request.Context(), msg); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
 	c.JSON(http.StatusCreated, t)
 }
 
@@ -18,6 +18,8 @@ type Ticket struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	ClientID  string             `bson:"client_id"      json:"client_id"`
 	Subject   string             `bson:"subject"        json:"subject"`
+	OrderID   string             `bson:"order_id,omitempty" json:"order_id,omitempty"` // тикет по заказу (например, спор)
+	Category  string             `bson:"category,omitempty" json:"category,omitempty"`
 	Status    TicketStatus       `bson:"status"         json:"status"`
 	CreatedAt time.Time          `bson:"created_at"     json:"created_at"`
 	UpdatedAt time.Time          `bson:"updated_at"     json:"updated_at"`
@@ -46,6 +46,7 @@ func main() {
 	router.RedirectTrailingSlash = false
 
 	router.POST("/users/gamification/add-xp", h.AddXP)
+	router.POST("/users/gamification/statuses", utils.RequireInternalSignature(cfg.InternalAPISecret), h.GetStatuses)
 	
 	authMW := utils.AuthMiddleware(cfg.AuthServiceURL)
 	users := router.Group("/users")
@@ -11,6 +11,9 @@ type Config struct {
 	ServerPort       string
 	AuthServiceURL   string
 	NotifiServiceURL string
+
+	// Общий с другими сервисами секрет подписи межсервисных вызовов.
+	InternalAPISecret string
 }
 
 func LoadConfig() (*Config, error) {
@@ -23,5 +26,7 @@ func LoadConfig() (*Config, error) {
 		ServerPort:       os.Getenv("SERVER_PORT"),
 		AuthServiceURL:   os.Getenv("AUTH_SERVICE_URL"),
 		NotifiServiceURL: os.Getenv("NOTIFI_SERVICE_URL"),
+
+		InternalAPISecret: os.Getenv("INTERNAL_API_SECRET"),
 	}, nil
 }
@@ -23,8 +23,9 @@ type UserService interface {
 	ChangeUserRole(ctx context.Context, id primitive.ObjectID, newRole models.Role) error
 	BlockUser(ctx context.Context, id primitive.ObjectID) error
 	UnblockUser(ctx context.Context, id primitive.ObjectID) error
-	AddXPToUser(ctx context.Context, id primitive.ObjectID, xp int) (*models.GamificationStatus, error)
+	AddXPToUser(ctx context.Context, id primitive.ObjectID, xp int, key string) (*models.GamificationStatus, bool, error)
 	GetGamificationStatus(ctx context.Context, id primitive.ObjectID) (*models.GamificationStatus, error)
+	GetGamificationStatuses(ctx context.Context, ids []primitive.ObjectID) ([]models.GamificationStatus, error)
 }
 
 func NewUserHandler(s UserService) *UserHandler {
@@ -32,10 +33,12 @@ func NewUserHandler(s UserService) *UserHandler {
 }
 
 // POST /api/users/gamification/add-xp
+// idempotency_key (опц.) — повторная доставка с тем же ключом не начисляет XP второй раз.
 func (h *UserHandler) AddXP(c *gin.Context) {
 	var payload struct {
-		UserID string `json:"user_id" binding:"required"`
-		XP     int    `json:"xp"      binding:"required"`
+		UserID         string `json:"user_id" binding:"required"`
+		XP             int    `json:"xp"      binding:"required"`
+		IdempotencyKey string `json:"idempotency_key"`
 	}
 	if err := c.ShouldBindJSON(&payload); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
@@ -48,11 +51,15 @@ func (h *UserHandler) AddXP(c *gin.Context) {
 		return
 	}
 
-	status, err := h.service.AddXPToUser(c.Request.Context(), userID, payload.XP)
+	status, applied, err := h.service.AddXPToUser(c.Request.Context(), userID, payload.XP, payload.IdempotencyKey)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if !applied {
+		c.JSON(http.StatusOK, status)
+		return
+	}
 
 	// ── Уведомление об обновлении XP ──
 	go func() {
@@ -67,6 +74,35 @@ func (h *UserHandler) AddXP(c *gin.Context) {
 	c.JSON(http.StatusOK, status)
 }
 
+// POST /api/users/gamification/statuses — внутренний пакетный запрос уровней (order-service),
+// подписанный INTERNAL_API_SECRET
+func (h *UserHandler) GetStatuses(c *gin.Context) {
+	var payload struct {
+		UserIDs []string `json:"user_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&payload); err != nil || len(payload.UserIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(payload.UserIDs))
+	for _, idHex := range payload.UserIDs {
+		id, err := primitive.ObjectIDFromHex(idHex)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid user_id: %s", idHex)})
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	statuses, err := h.service.GetGamificationStatuses(c.Request.Context(), ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, statuses)
+}
+
 // GET /api/users/gamification/status
 func (h *UserHandler) GetStatus(c *gin.Context) {
 	idStr, exists := c.Get("userId")
@@ -28,18 +28,33 @@ func (r *UserRepository) Create(ctx context.Context, u *models.User) error {
 	return err
 }
 
-func (r *UserRepository) AddXP(ctx context.Context, id primitive.ObjectID, xp int) error {
+// xpGrantsKept — сколько последних ключей начисления XP хранится у пользователя для дедупликации.
+const xpGrantsKept = 100
+
+// AddXP начисляет XP. Непустой key делает начисление идемпотентным: повторный вызов
+// с тем же ключом ничего не меняет и возвращает applied=false.
+func (r *UserRepository) AddXP(ctx context.Context, id primitive.ObjectID, xp int, key string) (bool, error) {
+	filter := bson.M{"_id": id}
 	update := bson.M{"$inc": bson.M{"xp_total": xp}}
-	res, err := r.col.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if key != "" {
+		filter["xp_grants"] = bson.M{"$ne": key}
+		update["$push"] = bson.M{"xp_grants": bson.M{"$each": []string{key}, "$slice": -xpGrantsKept}}
+	}
+	res, err := r.col.UpdateOne(ctx, filter, update)
 	if err != nil {
-
-		return err
+		return false, err
 	}
 	if res.MatchedCount == 0 {
-		return fmt.Errorf("user not found")
+		if key != "" {
+			// Пользователь есть, но ключ уже применён — это повтор.
+			if n, err := r.col.CountDocuments(ctx, bson.M{"_id": id}); err == nil && n > 0 {
+				return false, nil
+			}
+		}
+		return false, fmt.Errorf("user not found")
 	}
 
-	return nil
+	return true, nil
 }
 
 func (r *UserRepository) UpdateLevel(ctx context.Context, id primitive.ObjectID, newLevel int) error {
@@ -55,6 +70,21 @@ func (r *UserRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*m
 	return &user, err
 }
 
+// GetByIDs возвращает пользователей по списку ID (отсутствующие просто пропускаются).
+func (r *UserRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.User, error) {
+	cursor, err := r.col.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
 func (r *UserRepository) GetAll(ctx context.Context, role models.Role) ([]models.User, error) {
 	filter := bson.M{}
 	if role != models.RoleAll {
@@ -16,10 +16,11 @@ type UserService struct {
 type UserRepository interface {
 	Create(ctx context.Context, u *models.User) error
 	GetByID(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.User, error)
 	GetAll(ctx context.Context, role models.Role) ([]models.User, error)
 	SetBanStatus(ctx context.Context, id primitive.ObjectID, banned bool) error
 	UpdateRole(ctx context.Context, id primitive.ObjectID, role models.Role) error
-	AddXP(ctx context.Context, id primitive.ObjectID, xp int) error
+	AddXP(ctx context.Context, id primitive.ObjectID, xp int, key string) (bool, error)
 	UpdateLevel(ctx context.Context, id primitive.ObjectID, newLevel int) error
 }
 
@@ -52,11 +53,13 @@ func (s *UserService) UnblockUser(ctx context.Context, id primitive.ObjectID) er
 }
 
 // ─── AddXPToUser ───
-func (s *UserService) AddXPToUser(ctx context.Context, id primitive.ObjectID, xp int) (*models.GamificationStatus, error) {
+// key — ключ идемпотентности отправителя; повтор с тем же ключом вернёт текущий статус
+// и applied=false.
+func (s *UserService) AddXPToUser(ctx context.Context, id primitive.ObjectID, xp int, key string) (*models.GamificationStatus, bool, error) {
 	// 1. Вычитываем текущее состояние пользователя
 	user, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("could not fetch user: %w", err)
+		return nil, false, fmt.Errorf("could not fetch user: %w", err)
 	}
 
 	// 2. Новое суммарное XP
@@ -67,13 +70,18 @@ func (s *UserService) AddXPToUser(ctx context.Context, id primitive.ObjectID, xp
 
 	// 4. Обновляем в БД: xp_total и, при необходимости, current_level
 	// Сначала обновим xp_total
-	if err := s.repo.AddXP(ctx, id, xp); err != nil {
-		return nil, fmt.Errorf("could not add xp: %w", err)
+	applied, err := s.repo.AddXP(ctx, id, xp, key)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not add xp: %w", err)
+	}
+	if !applied {
+		status, err := s.GetGamificationStatus(ctx, id)
+		return status, false, err
 	}
 	// Затем, если уровень изменился, обновляем current_level
 	if newLevel != user.CurrentLevel {
 		if err := s.repo.UpdateLevel(ctx, id, newLevel); err != nil {
-			return nil, fmt.Errorf("could not update level: %w", err)
+			return nil, false, fmt.Errorf("could not update level: %w", err)
 		}
 	}
 
@@ -83,7 +91,7 @@ func (s *UserService) AddXPToUser(ctx context.Context, id primitive.ObjectID, xp
 		XPTotal:       newXPTotal,
 		CurrentLevel:  newLevel,
 		XPToNextLevel: xpToNext,
-	}, nil
+	}, true, nil
 }
 
 // ─── GetGamificationStatus ───
@@ -100,3 +108,23 @@ func (s *UserService) GetGamificationStatus(ctx context.Context, id primitive.Ob
 		XPToNextLevel: xpToNext,
 	}, nil
 }
+
+// ─── GetGamificationStatuses ───
+// Пакетный вариант GetGamificationStatus для внутренних вызовов (order-service).
+func (s *UserService) GetGamificationStatuses(ctx context.Context, ids []primitive.ObjectID) ([]models.GamificationStatus, error) {
+	users, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch users: %w", err)
+	}
+	statuses := make([]models.GamificationStatus, 0, len(users))
+	for _, user := range users {
+		currentLevel, xpToNext := models.CalculateLevel(user.XPTotal)
+		statuses = append(statuses, models.GamificationStatus{
+			UserID:        user.ID,
+			XPTotal:       user.XPTotal,
+			CurrentLevel:  currentLevel,
+			XPToNextLevel: xpToNext,
+		})
+	}
+	return statuses, nil
+}